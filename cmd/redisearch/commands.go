@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uretgec/go-redisearch/redisearch"
+)
+
+func cmdCreate(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "create {index.json} ...")
+	files, err := parseArgs(fs, args, 1, -1)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		ftc, err := redisearch.LoadIndexDefinition(path)
+		if err != nil {
+			return err
+		}
+		name := ftc.IndexName()
+
+		if _, err := c.client.Create(name, ftc.Serialize()...); err != nil {
+			return fmt.Errorf("create %s: %v", name, err)
+		}
+		fmt.Fprintf(c.out, "created %s\n", name)
+	}
+
+	return nil
+}
+
+func cmdInfo(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "info {index}")
+	pos, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	info, err := c.client.Info(ctx, pos[0])
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printJSON(c.out, info)
+	}
+
+	err = printTable(c.out, []string{"INDEX", "ON", "PREFIXES", "DOCS", "INDEXING", "FAILURES"}, [][]string{{
+		info.Name,
+		info.DataType,
+		strings.Join(info.Prefixes, ","),
+		strconv.FormatInt(info.NumDocs, 10),
+		strconv.FormatFloat(info.PercentIndexed*100, 'f', -1, 64) + "%",
+		strconv.FormatInt(info.HashIndexingFailures, 10),
+	}})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out)
+
+	rows := make([][]string, 0, len(info.Attributes))
+	for _, a := range info.Attributes {
+		var opts []string
+		if a.Sortable {
+			opts = append(opts, "SORTABLE")
+		}
+		if a.UNF {
+			opts = append(opts, "UNF")
+		}
+		if a.NoStem {
+			opts = append(opts, "NOSTEM")
+		}
+		if a.NoIndex {
+			opts = append(opts, "NOINDEX")
+		}
+		if a.CaseSensitive {
+			opts = append(opts, "CASESENSITIVE")
+		}
+		if a.Type == redisearch.FieldTypeText && a.Weight != 1 {
+			opts = append(opts, "WEIGHT "+strconv.FormatFloat(a.Weight, 'f', -1, 64))
+		}
+		if a.Separator != "" {
+			opts = append(opts, "SEPARATOR "+a.Separator)
+		}
+		if a.Phonetic != "" {
+			opts = append(opts, "PHONETIC "+a.Phonetic)
+		}
+		rows = append(rows, []string{a.Identifier, a.Attribute, a.Type, strings.Join(opts, " ")})
+	}
+
+	return printTable(c.out, []string{"IDENTIFIER", "ATTRIBUTE", "TYPE", "OPTIONS"}, rows)
+}
+
+func cmdList(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "list")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	reply, err := c.client.List()
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	items, _ := reply.([]interface{})
+	for _, item := range items {
+		names = append(names, fmt.Sprint(item))
+	}
+	sort.Strings(names)
+
+	return c.printList(names)
+}
+
+func cmdDrop(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "drop [-dd] {index}")
+	dd := fs.Bool("dd", false, "delete the documents of the index")
+	pos, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.client.DropIndex(pos[0], *dd); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "dropped %s\n", pos[0])
+
+	return nil
+}
+
+func cmdAlias(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "alias add|update {alias} {index} | alias del {alias}")
+	pos, err := parseArgs(fs, args, 2, 3)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case pos[0] == "add" && len(pos) == 3:
+		_, err = c.client.AliasAdd(pos[1], pos[2])
+	case pos[0] == "update" && len(pos) == 3:
+		_, err = c.client.AliasUpdate(pos[1], pos[2])
+	case pos[0] == "del" && len(pos) == 2:
+		_, err = c.client.AliasDel(pos[1])
+	default:
+		return fmt.Errorf("usage: %s", fs.Name())
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, "OK")
+
+	return nil
+}
+
+func cmdSug(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "sug add {key} {string} ... | sug get [-max n] {key} {prefix}")
+	max := fs.Int("max", 5, "maximum number of suggestions")
+	pos, err := parseArgs(fs, args, 3, -1)
+	if err != nil {
+		return err
+	}
+
+	switch pos[0] {
+	case "add":
+		for _, val := range pos[2:] {
+			if _, err := c.client.SugAdd(pos[1], val); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(c.out, "added %d suggestions\n", len(pos)-2)
+
+		return nil
+	case "get":
+		if len(pos) != 3 {
+			return fmt.Errorf("usage: %s", fs.Name())
+		}
+
+		reply, err := c.client.SugGet(pos[1], pos[2], *max)
+		if err != nil {
+			return err
+		}
+
+		var sugs []string
+		items, _ := reply.([]interface{})
+		for _, item := range items {
+			sugs = append(sugs, fmt.Sprint(item))
+		}
+
+		return c.printList(sugs)
+	}
+
+	return fmt.Errorf("usage: %s", fs.Name())
+}
+
+func cmdDict(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "dict add|del {dict} {term} ... | dict dump {dict}")
+	pos, err := parseArgs(fs, args, 2, -1)
+	if err != nil {
+		return err
+	}
+
+	var n int64
+	switch {
+	case pos[0] == "add" && len(pos) > 2:
+		n, err = c.client.DictAdd(ctx, pos[1], pos[2:]...)
+	case pos[0] == "del" && len(pos) > 2:
+		n, err = c.client.DictDel(ctx, pos[1], pos[2:]...)
+	case pos[0] == "dump" && len(pos) == 2:
+		terms, err := c.client.DictDump(ctx, pos[1])
+		if err != nil {
+			return err
+		}
+		sort.Strings(terms)
+
+		return c.printList(terms)
+	default:
+		return fmt.Errorf("usage: %s", fs.Name())
+	}
+	if err != nil {
+		return err
+	}
+
+	verb := "added"
+	if pos[0] == "del" {
+		verb = "deleted"
+	}
+	fmt.Fprintf(c.out, "%s %d terms\n", verb, n)
+
+	return nil
+}
+
+func cmdSyn(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "syn update [-skip] {index} {group} {term} ... | syn dump {index}")
+	skip := fs.Bool("skip", false, "SKIPINITIALSCAN: only apply the group to documents indexed from now on")
+	pos, err := parseArgs(fs, args, 2, -1)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case pos[0] == "update" && len(pos) > 3:
+		if err := c.client.SynUpdate(ctx, pos[1], pos[2], *skip, pos[3:]...); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.out, "OK")
+
+		return nil
+	case pos[0] == "dump" && len(pos) == 2:
+		groups, err := c.client.SynDump(ctx, pos[1])
+		if err != nil {
+			return err
+		}
+
+		if c.json {
+			return printJSON(c.out, groups)
+		}
+
+		terms := make([]string, 0, len(groups))
+		for t := range groups {
+			terms = append(terms, t)
+		}
+		sort.Strings(terms)
+
+		rows := make([][]string, 0, len(terms))
+		for _, t := range terms {
+			rows = append(rows, []string{t, strings.Join(groups[t], ",")})
+		}
+
+		return printTable(c.out, []string{"TERM", "GROUPS"}, rows)
+	}
+
+	return fmt.Errorf("usage: %s", fs.Name())
+}
+
+func (c *cli) printList(items []string) error {
+	if c.json {
+		if items == nil {
+			items = []string{}
+		}
+		return printJSON(c.out, items)
+	}
+
+	for _, item := range items {
+		fmt.Fprintln(c.out, item)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+// Command redisearch administers RediSearch indexes and runs ad-hoc searches.
+//
+//	redisearch [-addr host:port,...] [-json] <command> [flags] [args]
+//
+// Commands:
+//
+//	create {index.json} ...                       create indexes from index documents
+//	info {index}                                  show the index definition and stats
+//	list                                          list indexes
+//	drop [-dd] {index}                            drop an index, -dd deletes its documents
+//	alias add|update {alias} {index}              point an alias at an index
+//	alias del {alias}                             remove an alias
+//	search [flags] {index} [query]                run FT.SEARCH with builder flags
+//	aggregate {index} {query} [pipeline ...]      run FT.AGGREGATE, e.g. GROUPBY 1 @cats REDUCE COUNT 0 AS n
+//	explain [-dialect n] {index} {query}          print the execution plan
+//	profile [-limited] [flags] {index} [query]    run FT.PROFILE SEARCH with search flags
+//	sug add {key} {string} ...                    add suggestions
+//	sug get [-max n] {key} {prefix}               get fuzzy suggestions
+//	dict add|del {dict} {term} ...                add or delete dictionary terms
+//	dict dump {dict}                              list dictionary terms
+//	syn update [-skip] {index} {group} {term} ... add terms to a synonym group
+//	syn dump {index}                              list synonym groups
+//
+// The address defaults to $REDISEARCH_ADDR or 127.0.0.1:6379. Flags may follow the arguments,
+// put -- before a query that starts with a dash.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/uretgec/go-redisearch/redisearch"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "redisearch:", err)
+		os.Exit(1)
+	}
+}
+
+// cli is the state shared by every command
+type cli struct {
+	client *redisearch.RedisearchClient
+	out    io.Writer
+	json   bool
+}
+
+type command func(ctx context.Context, c *cli, args []string) error
+
+var commands = map[string]command{
+	"create":    cmdCreate,
+	"info":      cmdInfo,
+	"list":      cmdList,
+	"drop":      cmdDrop,
+	"alias":     cmdAlias,
+	"search":    cmdSearch,
+	"aggregate": cmdAggregate,
+	"explain":   cmdExplain,
+	"profile":   cmdProfile,
+	"sug":       cmdSug,
+	"dict":      cmdDict,
+	"syn":       cmdSyn,
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	addr := os.Getenv("REDISEARCH_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+
+	fs := flag.NewFlagSet("redisearch", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.StringVar(&addr, "addr", addr, "comma separated Redis addresses, several addresses connect to a cluster")
+	asJSON := fs.Bool("json", false, "print JSON instead of tables")
+	fs.Usage = func() {
+		fmt.Fprintln(out, "usage: redisearch [-addr host:port,...] [-json] <command> [flags] [args]")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+
+	client := redisearch.NewRedisearchClient("redisearch-cli", strings.Split(addr, ","), []int{4}, []int{0}, []int{1})
+	defer client.CloseUniversalClient()
+
+	return cmd(ctx, &cli{client: client, out: out, json: *asJSON}, fs.Args()[1:])
+}
+
+// parseArgs accepts flags before, between and after the positional arguments.
+// Everything after -- is positional.
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	var positional, rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, rest = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	positional = append(positional, rest...)
+
+	if len(positional) < min || (max >= 0 && len(positional) > max) {
+		return nil, fmt.Errorf("usage: %s", fs.Name())
+	}
+
+	return positional, nil
+}
+
+func newFlags(c *cli, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(usage, flag.ContinueOnError)
+	fs.SetOutput(c.out)
+
+	return fs
+}
+
+// splitList splits a comma separated flag value, an empty value is nil
+func splitList(val string) []string {
+	if val == "" {
+		return nil
+	}
+
+	return strings.Split(val, ",")
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/uretgec/go-redisearch/redisearch"
+	"github.com/uretgec/go-redisearch/redisearch/redisearchtest"
+)
+
+const testSchema = `{
+  "name": "idx:drd",
+  "prefixes": ["drd:"],
+  "fields": [
+    {"name": "name", "type": "TEXT", "sortable": true},
+    {"name": "cats", "type": "TAG", "separator": ","},
+    {"name": "updated", "type": "NUMERIC", "sortable": true}
+  ]
+}`
+
+func TestRun(t *testing.T) {
+	srv, err := redisearchtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	schema := filepath.Join(t.TempDir(), "drd.json")
+	if err := os.WriteFile(schema, []byte(testSchema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+
+		var out bytes.Buffer
+		if err := run(context.Background(), append([]string{"-addr", srv.Addr()}, args...), &out); err != nil {
+			t.Fatalf("run(%q) error = %v", args, err)
+		}
+
+		return out.String()
+	}
+
+	if got := run("create", schema); got != "created idx:drd\n" {
+		t.Fatalf("create = %q", got)
+	}
+
+	// the CLI client selects its own DB, seed the documents through the same settings
+	rsc := redisearch.NewRedisearchClient("test", []string{srv.Addr()}, []int{4}, []int{0}, []int{1})
+	defer rsc.CloseUniversalClient()
+	docs := map[string][]interface{}{
+		"drd:1": {"name", "Dream Test 1", "cats", "dream,test", "updated", 100},
+		"drd:2": {"name", "Dream Test 2", "cats", "dream", "updated", 200},
+		"drd:3": {"name", "Hello World", "cats", "world", "updated", 300},
+	}
+	for key, fields := range docs {
+		if _, err := rsc.HSet(key, fields...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "List",
+			args: []string{"list"},
+			want: []string{"idx:drd"},
+		},
+		{
+			name: "Info",
+			args: []string{"info", "idx:drd"},
+			want: []string{"idx:drd", "HASH", "drd:", "cats", "TAG", "SEPARATOR ,", "SORTABLE"},
+		},
+		{
+			name: "Search",
+			args: []string{"search", "idx:drd", "dream", "-sortby", "updated", "-desc", "-return", "name,updated"},
+			want: []string{"ID", "name", "updated", "drd:2", "Dream Test 2", "2 of 2 results"},
+		},
+		{
+			name: "Search Filter",
+			args: []string{"search", "-filter", "updated:(100:+inf", "-nocontent", "idx:drd"},
+			want: []string{"drd:2", "drd:3", "2 of 2 results"},
+		},
+		{
+			name: "Search JSON",
+			args: []string{"-json", "search", "idx:drd", "@cats:{world}"},
+			want: []string{`"Total": 1`, `"ID": "drd:3"`},
+		},
+		{
+			name: "Alias",
+			args: []string{"alias", "add", "drd", "idx:drd"},
+			want: []string{"OK"},
+		},
+		{
+			name: "Suggestions",
+			args: []string{"sug", "add", "sug:drd", "dream", "dreamer"},
+			want: []string{"added 2 suggestions"},
+		},
+		{
+			name: "Dictionary",
+			args: []string{"dict", "add", "dict:drd", "dream", "world"},
+			want: []string{"added 2 terms"},
+		},
+		{
+			name: "Dictionary Dump",
+			args: []string{"-json", "dict", "dump", "dict:drd"},
+			want: []string{`"dream"`, `"world"`},
+		},
+		{
+			name: "Synonyms",
+			args: []string{"syn", "update", "idx:drd", "grp1", "dream", "vision", "-skip"},
+			want: []string{"OK"},
+		},
+		{
+			name: "Synonyms Dump",
+			args: []string{"syn", "dump", "idx:drd"},
+			want: []string{"dream", "vision", "grp1"},
+		},
+		{
+			name: "Drop",
+			args: []string{"drop", "idx:drd", "-dd"},
+			want: []string{"dropped idx:drd"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := run(tt.args...)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("run(%q) = %q, want it to contain %q", tt.args, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_parsePipeline(t *testing.T) {
+	tokens := strings.Fields("LOAD 1 @name GROUPBY 1 @cats REDUCE count 0 AS n REDUCE SUM 1 @updated AS total " +
+		"SORTBY 3 @n DESC @cats MAX 5 APPLY upper(@cats) AS c FILTER @n>1 LIMIT 0 3")
+
+	fta, err := parsePipeline("idx:drd", "*", tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Trim(fmt.Sprint(fta.Serialize()), "[]")
+	want := "FT.AGGREGATE idx:drd * LOAD 1 @name GROUPBY 1 @cats REDUCE COUNT 0 AS n REDUCE SUM 1 @updated AS total " +
+		"SORTBY 4 @n DESC @cats ASC MAX 5 APPLY upper(@cats) AS c FILTER @n>1 LIMIT 0 3"
+	if got != want {
+		t.Errorf("parsePipeline() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"GROUPBY 2 @cats", "APPLY @a", "LIMIT x 1", "HAVING 1"} {
+		if _, err := parsePipeline("idx:drd", "*", strings.Fields(bad)); err == nil {
+			t.Errorf("parsePipeline(%q) error = nil", bad)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+func printJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// printTable aligns rows under the header, tabs and newlines in cells are replaced by spaces
+func printTable(out io.Writer, header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	write := func(cells []string) {
+		for i, cell := range cells {
+			cells[i] = clean.Replace(cell)
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	write(append([]string(nil), header...))
+	for _, row := range rows {
+		write(row)
+	}
+
+	return w.Flush()
+}
+
+// columns returns the sorted union of the keys of every row, first keys keep their position
+func columns(rows []map[string]string, first ...string) []string {
+	seen := make(map[string]bool)
+	for _, k := range first {
+		seen[k] = true
+	}
+
+	var rest []string
+	for _, row := range rows {
+		for k := range row {
+			if !seen[k] {
+				seen[k] = true
+				rest = append(rest, k)
+			}
+		}
+	}
+	sort.Strings(rest)
+
+	return append(append([]string(nil), first...), rest...)
+}
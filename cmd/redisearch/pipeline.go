@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/uretgec/go-redisearch/redisearch"
+)
+
+// pipeline walks the raw FT.AGGREGATE tokens given on the command line
+type pipeline struct {
+	tokens []string
+	pos    int
+}
+
+func (p *pipeline) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *pipeline) next(what string) (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("missing %s", what)
+	}
+	p.pos++
+
+	return p.tokens[p.pos-1], nil
+}
+
+// peek reports whether the next token is keyword, ignoring case
+func (p *pipeline) peek(keyword string) bool {
+	return !p.done() && strings.EqualFold(p.tokens[p.pos], keyword)
+}
+
+func (p *pipeline) int(what string) (int64, error) {
+	tok, err := p.next(what)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseInt(tok, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", what, tok)
+	}
+
+	return n, nil
+}
+
+func (p *pipeline) list(what string) ([]string, error) {
+	n, err := p.int(what + " count")
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]string, 0, n)
+	for i := int64(0); i < n; i++ {
+		tok, err := p.next(what)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, tok)
+	}
+
+	return list, nil
+}
+
+// parsePipeline turns LOAD, GROUPBY ... REDUCE, SORTBY, APPLY, FILTER and LIMIT tokens into the builder
+func parsePipeline(indexName, query string, tokens []string) (*redisearch.FtAggregate, error) {
+	fta := redisearch.NewFtAggregate(indexName).AddQuery(query)
+	p := &pipeline{tokens: tokens}
+
+	for !p.done() {
+		step, _ := p.next("step")
+
+		switch strings.ToUpper(step) {
+		case "LOAD":
+			if p.peek("*") {
+				p.pos++
+				fta.AddLoad("*")
+				continue
+			}
+
+			fields, err := p.list("LOAD")
+			if err != nil {
+				return nil, err
+			}
+			fta.AddLoad(fields...)
+
+		case "GROUPBY":
+			props, err := p.list("GROUPBY")
+			if err != nil {
+				return nil, err
+			}
+
+			var reducers []redisearch.FtReducer
+			for p.peek("REDUCE") {
+				p.pos++
+
+				fn, err := p.next("REDUCE function")
+				if err != nil {
+					return nil, err
+				}
+				args, err := p.list("REDUCE")
+				if err != nil {
+					return nil, err
+				}
+
+				var alias string
+				if p.peek("AS") {
+					p.pos++
+					if alias, err = p.next("REDUCE alias"); err != nil {
+						return nil, err
+					}
+				}
+
+				reducers = append(reducers, redisearch.NewReducer(strings.ToUpper(fn), alias, args...))
+			}
+			fta.AddGroupBy(props, reducers...)
+
+		case "SORTBY":
+			args, err := p.list("SORTBY")
+			if err != nil {
+				return nil, err
+			}
+
+			var keys []redisearch.FtSortKey
+			for i := 0; i < len(args); i++ {
+				property, asc := args[i], true
+				if i+1 < len(args) {
+					switch strings.ToUpper(args[i+1]) {
+					case "ASC":
+						i++
+					case "DESC":
+						asc = false
+						i++
+					}
+				}
+				keys = append(keys, redisearch.NewSortKey(property, asc))
+			}
+
+			var max int64
+			if p.peek("MAX") {
+				p.pos++
+				if max, err = p.int("SORTBY MAX"); err != nil {
+					return nil, err
+				}
+			}
+			fta.AddSortBy(int(max), keys...)
+
+		case "APPLY":
+			expr, err := p.next("APPLY expression")
+			if err != nil {
+				return nil, err
+			}
+			if !p.peek("AS") {
+				return nil, fmt.Errorf("APPLY %s: missing AS", expr)
+			}
+			p.pos++
+			alias, err := p.next("APPLY alias")
+			if err != nil {
+				return nil, err
+			}
+			fta.AddApply(expr, alias)
+
+		case "FILTER":
+			expr, err := p.next("FILTER expression")
+			if err != nil {
+				return nil, err
+			}
+			fta.AddFilter(expr)
+
+		case "LIMIT":
+			offset, err := p.int("LIMIT offset")
+			if err != nil {
+				return nil, err
+			}
+			num, err := p.int("LIMIT num")
+			if err != nil {
+				return nil, err
+			}
+			fta.AddLimit(offset, num)
+
+		default:
+			return nil, fmt.Errorf("unknown aggregate step %q", step)
+		}
+	}
+
+	return fta, nil
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/uretgec/go-redisearch/redisearch"
+)
+
+// repeated collects every value of a flag given several times
+type repeated []string
+
+func (r *repeated) String() string { return strings.Join(*r, " ") }
+
+func (r *repeated) Set(val string) error {
+	*r = append(*r, val)
+	return nil
+}
+
+// searchFlags maps command line flags to FtSearch builder calls
+type searchFlags struct {
+	offset     int64
+	num        int64
+	sortby     string
+	desc       bool
+	fields     string
+	infields   string
+	highlight  string
+	language   string
+	nocontent  bool
+	verbatim   bool
+	withscores bool
+	filters    repeated
+	geofilter  string
+}
+
+func newSearchFlags(fs *flag.FlagSet) *searchFlags {
+	sf := &searchFlags{}
+	fs.Int64Var(&sf.offset, "offset", 0, "LIMIT offset")
+	fs.Int64Var(&sf.num, "num", 10, "LIMIT num")
+	fs.StringVar(&sf.sortby, "sortby", "", "SORTBY attribute")
+	fs.BoolVar(&sf.desc, "desc", false, "sort descending")
+	fs.StringVar(&sf.fields, "return", "", "comma separated RETURN fields")
+	fs.StringVar(&sf.infields, "infields", "", "comma separated INFIELDS")
+	fs.StringVar(&sf.highlight, "highlight", "", "comma separated HIGHLIGHT fields")
+	fs.StringVar(&sf.language, "language", "", "LANGUAGE of the query")
+	fs.BoolVar(&sf.nocontent, "nocontent", false, "only return document ids")
+	fs.BoolVar(&sf.verbatim, "verbatim", false, "do not stem the query terms")
+	fs.BoolVar(&sf.withscores, "withscores", false, "return the document scores")
+	fs.Var(&sf.filters, "filter", "numeric FILTER attribute:min:max, ( marks an exclusive bound, -inf and +inf are open; repeatable")
+	fs.StringVar(&sf.geofilter, "geofilter", "", "GEOFILTER attribute:lon:lat:radius:unit")
+
+	return sf
+}
+
+func (sf *searchFlags) build(indexName, query string) (*redisearch.FtSearch, error) {
+	if query == "" {
+		query = "*"
+	}
+
+	fts := redisearch.NewFtSearch(indexName).
+		AddQuery(query).
+		AddNoContent(sf.nocontent).
+		AddVerbatim(sf.verbatim).
+		AddWithScores(sf.withscores).
+		AddLanguage(sf.language).
+		AddLimit(sf.offset, sf.num)
+
+	if sf.sortby != "" {
+		fts.AddSortBy(sf.sortby, !sf.desc)
+	}
+	if fields := splitList(sf.fields); fields != nil {
+		fts.AddReturnFields(fields...)
+	}
+	if fields := splitList(sf.infields); fields != nil {
+		fts.AddInFields(fields...)
+	}
+	if fields := splitList(sf.highlight); fields != nil {
+		fts.AddHighlight(fields, "", "")
+	}
+
+	for _, f := range sf.filters {
+		parts := strings.Split(f, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid filter %q, want attribute:min:max", f)
+		}
+
+		min, excludeMin, err := parseBound(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		max, excludeMax, err := parseBound(parts[2])
+		if err != nil {
+			return nil, err
+		}
+
+		fts.AddNumericFilter(parts[0], redisearch.NewNumericRange(min, max).Exclusive(excludeMin, excludeMax))
+	}
+
+	if sf.geofilter != "" {
+		parts := strings.Split(sf.geofilter, ":")
+		if len(parts) != 5 {
+			return nil, fmt.Errorf("invalid geofilter %q, want attribute:lon:lat:radius:unit", sf.geofilter)
+		}
+
+		var coords [3]float64
+		for i := range coords {
+			val, err := strconv.ParseFloat(parts[i+1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid geofilter %q: %v", sf.geofilter, err)
+			}
+			coords[i] = val
+		}
+
+		fts.AddGeoFilter(parts[0], coords[0], coords[1], coords[2], redisearch.Unit(parts[4]))
+	}
+
+	return fts, nil
+}
+
+// parseBound reads 10, (10, -inf, inf or +inf
+func parseBound(val string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(val, "(")
+	val = strings.TrimPrefix(val, "(")
+
+	switch val {
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	case "inf", "+inf":
+		return math.Inf(1), exclusive, nil
+	}
+
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid bound %q", val)
+	}
+
+	return f, exclusive, nil
+}
+
+func cmdSearch(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "search [flags] {index} [query]")
+	sf := newSearchFlags(fs)
+	pos, err := parseArgs(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	fts, err := sf.build(pos[0], strings.Join(pos[1:], " "))
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.DoSearch(ctx, fts)
+	if err != nil {
+		return err
+	}
+
+	return c.printSearch(res, sf)
+}
+
+func (c *cli) printSearch(res *redisearch.SearchResult, sf *searchFlags) error {
+	if c.json {
+		if res.Docs == nil {
+			res.Docs = []redisearch.Document{}
+		}
+		return printJSON(c.out, res)
+	}
+
+	fields := make([]map[string]string, 0, len(res.Docs))
+	for _, doc := range res.Docs {
+		fields = append(fields, doc.Fields)
+	}
+
+	header := []string{"ID"}
+	if sf.withscores {
+		header = append(header, "SCORE")
+	}
+	names := columns(fields, splitList(sf.fields)...)
+	header = append(header, names...)
+
+	rows := make([][]string, 0, len(res.Docs))
+	for _, doc := range res.Docs {
+		row := []string{doc.ID}
+		if sf.withscores {
+			row = append(row, strconv.FormatFloat(doc.Score, 'f', -1, 64))
+		}
+		for _, name := range names {
+			row = append(row, doc.Fields[name])
+		}
+		rows = append(rows, row)
+	}
+
+	if err := printTable(c.out, header, rows); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(c.out, "%d of %d results\n", len(res.Docs), res.Total)
+
+	return err
+}
+
+func cmdAggregate(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "aggregate {index} {query} [LOAD|GROUPBY|SORTBY|APPLY|FILTER|LIMIT ...]")
+	verbatim := fs.Bool("verbatim", false, "do not stem the query terms")
+
+	// pipeline tokens like SORTBY 2 @count DESC are positional, flags only come first
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("usage: %s", fs.Name())
+	}
+
+	fta, err := parsePipeline(fs.Arg(0), fs.Arg(1), fs.Args()[2:])
+	if err != nil {
+		return err
+	}
+	fta.AddVerbatim(*verbatim)
+
+	res, err := c.client.Aggregate(ctx, fta)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		if res.Rows == nil {
+			res.Rows = []map[string]string{}
+		}
+		return printJSON(c.out, res)
+	}
+
+	header := columns(res.Rows)
+	rows := make([][]string, 0, len(res.Rows))
+	for _, r := range res.Rows {
+		row := make([]string, 0, len(header))
+		for _, name := range header {
+			row = append(row, r[name])
+		}
+		rows = append(rows, row)
+	}
+
+	if err := printTable(c.out, header, rows); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.out, "%d rows\n", len(res.Rows))
+
+	return err
+}
+
+func cmdExplain(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "explain [-dialect n] {index} {query}")
+	dialect := fs.Int("dialect", 0, "query DIALECT, 0 uses the server default")
+	pos, err := parseArgs(fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.client.Explain(ctx, pos[0], pos[1], *dialect)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printJSON(c.out, res.Root)
+	}
+
+	_, err = fmt.Fprint(c.out, res.Root.String())
+
+	return err
+}
+
+func cmdProfile(ctx context.Context, c *cli, args []string) error {
+	fs := newFlags(c, "profile [-limited] [flags] {index} [query]")
+	limited := fs.Bool("limited", false, "summarize reader iterators")
+	sf := newSearchFlags(fs)
+	pos, err := parseArgs(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	fts, err := sf.build(pos[0], strings.Join(pos[1:], " "))
+	if err != nil {
+		return err
+	}
+
+	res, profile, err := c.client.ProfileSearch(ctx, fts, *limited)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return printJSON(c.out, struct {
+			Total   int64
+			Profile *redisearch.Profile
+		}{res.Total, profile})
+	}
+
+	fmt.Fprintf(c.out, "%d results\ntotal %v, parsing %v, pipeline creation %v\n\n",
+		res.Total, profile.TotalTime, profile.ParsingTime, profile.PipelineCreationTime)
+
+	var rows [][]string
+	var walk func(it *redisearch.ProfileIterator, depth int)
+	walk = func(it *redisearch.ProfileIterator, depth int) {
+		if it == nil {
+			return
+		}
+		name := strings.Repeat("  ", depth) + it.Type
+		if it.QueryType != "" {
+			name += " " + it.QueryType
+		}
+		if it.Term != "" {
+			name += " " + it.Term
+		}
+		rows = append(rows, []string{name, it.Time.String(), strconv.FormatInt(it.Counter, 10), strconv.FormatInt(it.Size, 10)})
+		for _, child := range it.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(profile.Iterators, 0)
+
+	if err := printTable(c.out, []string{"ITERATOR", "TIME", "COUNTER", "SIZE"}, rows); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out)
+
+	rows = rows[:0]
+	for _, p := range profile.ResultProcessors {
+		rows = append(rows, []string{p.Type, p.Time.String(), strconv.FormatInt(p.Counter, 10)})
+	}
+
+	return printTable(c.out, []string{"PROCESSOR", "TIME", "COUNTER"}, rows)
+}
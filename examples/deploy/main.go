@@ -1,18 +1,25 @@
 package main
 
 import (
-	"errors"
+	"flag"
 	"fmt"
+	"os"
 
 	"github.com/uretgec/go-redisearch/redisearch"
 )
 
-const (
-	INDEX_DREAMS = "index_dreams"
-	INDEX_TERMS  = "index_terms"
-)
+// Index documents, one JSON file per index. The default works from the repository root,
+// set -indexes or INDEX_DIR when running from elsewhere.
+const defaultIndexDir = "examples/deploy/indexes"
 
 func main() {
+	indexDir := os.Getenv("INDEX_DIR")
+	if indexDir == "" {
+		indexDir = defaultIndexDir
+	}
+	flag.StringVar(&indexDir, "indexes", indexDir, "directory of the index documents")
+	flag.Parse()
+
 	// Connect Redisearch
 	redisAddrs := []string{"127.0.0.1:6481"}
 	redisPoolSizes := []int{1000}
@@ -28,97 +35,34 @@ func main() {
 		panic(err)
 	}
 
-	// Create Dream Index
-	args, err := generateIndexQuery(INDEX_DREAMS)
-	if err != nil {
-		fmt.Printf("error: %#v\n", err)
-		panic(err)
-	}
-
-	err = client.UClient.Do(client.Ctx, args...).Err()
-	if err != nil {
-		fmt.Printf("error: %#v\n", err)
-		panic(err)
-	}
-
-	fmt.Println("Dream index created")
-
-	// Create Term Index
-	args, err = generateIndexQuery(INDEX_TERMS)
+	// Load index definitions
+	indexes, err := redisearch.LoadIndexDefinitions(indexDir)
 	if err != nil {
 		fmt.Printf("error: %#v\n", err)
 		panic(err)
 	}
 
-	err = client.UClient.Do(client.Ctx, args...).Err()
-	if err != nil {
-		fmt.Printf("error: %#v\n", err)
-		panic(err)
+	// Create missing indexes, add new attributes to existing ones.
+	// The index lock lets one instance do it when several start together, the others skip it.
+	for _, indexCreator := range indexes {
+		lock := client.NewIndexLock(indexCreator.IndexName()).AddWait(0)
+		res, err := client.EnsureIndexLocked(client.Ctx, indexCreator, redisearch.EnsureAlter, lock)
+		if err != nil {
+			fmt.Printf("error: %#v\n", err)
+			panic(err)
+		}
+
+		switch {
+		case res.Skipped:
+			fmt.Printf("%s index is ensured by another instance\n", res.Index)
+		case res.Created:
+			fmt.Printf("%s index created\n", res.Index)
+		case len(res.Altered) > 0:
+			fmt.Printf("%s index altered: %v\n", res.Index, res.Altered)
+		default:
+			fmt.Printf("%s index is up to date\n", res.Index)
+		}
 	}
 
-	fmt.Println("Term index created")
-
 	fmt.Println("bye bye")
 }
-
-func generateIndexQuery(indexName string) ([]interface{}, error) {
-	switch indexName {
-	case INDEX_DREAMS:
-		indexCreator := redisearch.NewFtCreate(indexName)
-		//indexCreator.AddTemporarySeconds(true, 3600)
-		indexCreator.AddDataType(redisearch.HASH)
-		indexCreator.AddPrefix("drd:")
-		//indexCreator.AddScoreField("score")
-
-		// uid
-		schemaTextOpt := indexCreator.AddSchemaTextOption(0, false, false, "")
-		indexCreator.AddSchema(redisearch.FieldTypeText, "uid", "", false, schemaTextOpt)
-
-		// name
-		indexCreator.AddSchema(redisearch.FieldTypeText, "name", "", false, schemaTextOpt)
-
-		// slug
-		//indexCreator.AddSchema(redisearch.FieldTypeText, "slug", "", false, schemaTextOpt)
-
-		// description
-		indexCreator.AddSchema(redisearch.FieldTypeText, "description", "", false, schemaTextOpt)
-
-		// cats
-		indexCreator.AddSchema(redisearch.FieldTypeText, "cats", "", false, schemaTextOpt)
-
-		// tags
-		indexCreator.AddSchema(redisearch.FieldTypeText, "tags", "", false, schemaTextOpt)
-
-		// updated
-		schemaNumericOpt := indexCreator.AddSchemaNumericOption(false)
-		indexCreator.AddSchema(redisearch.FieldTypeNumeric, "updated", "", true, schemaNumericOpt)
-
-		return indexCreator.Serialize(), nil
-	case INDEX_TERMS:
-		indexCreator := redisearch.NewFtCreate(indexName)
-		//indexCreator.AddTemporarySeconds(true, 3600)
-		indexCreator.AddDataType(redisearch.HASH)
-		indexCreator.AddPrefix("drd:")
-		//indexCreator.AddScoreField("score")
-
-		// uid
-		schemaTextOpt := indexCreator.AddSchemaTextOption(0, false, false, "")
-		indexCreator.AddSchema(redisearch.FieldTypeText, "uid", "", false, schemaTextOpt)
-
-		// cats
-		schemaTagOpt := indexCreator.AddSchemaTagOption(false, ",")
-		indexCreator.AddSchema(redisearch.FieldTypeTag, "cats", "", false, schemaTagOpt)
-
-		// tags
-		indexCreator.AddSchema(redisearch.FieldTypeTag, "tags", "", false, schemaTagOpt)
-
-		// updated
-		schemaNumericOpt := indexCreator.AddSchemaNumericOption(false)
-		indexCreator.AddSchema(redisearch.FieldTypeNumeric, "updated", "", true, schemaNumericOpt)
-
-		return indexCreator.Serialize(), nil
-
-	}
-
-	return nil, errors.New("index name not found")
-}
@@ -1,5 +1,10 @@
+// Via: https://oss.redis.com/redisearch/Commands/#ftaggregate
 package redisearch
 
+import (
+	"strings"
+)
+
 /*
 FT.AGGREGATE {index_name}
   {query_string}
@@ -13,8 +18,305 @@ FT.AGGREGATE {index_name}
   [APPLY {expr} AS {alias}] ...
   [LIMIT {offset} {num}] ...
   [FILTER {expr}] ...
+  [WITHCURSOR [COUNT {read size}] [MAXIDLE {idle timeout}]]
+  [DIALECT {dialect}]
+
+GROUPBY, SORTBY, APPLY, LIMIT and FILTER are pipeline steps: they run in the order they are added.
 */
 
+// Reducer functions
+const (
+	ReducerCount            string = "COUNT"
+	ReducerCountDistinct    string = "COUNT_DISTINCT"
+	ReducerCountDistinctish string = "COUNT_DISTINCTISH"
+	ReducerSum              string = "SUM"
+	ReducerMin              string = "MIN"
+	ReducerMax              string = "MAX"
+	ReducerAvg              string = "AVG"
+	ReducerStdDev           string = "STDDEV"
+	ReducerQuantile         string = "QUANTILE"
+	ReducerToList           string = "TOLIST"
+	ReducerFirstValue       string = "FIRST_VALUE"
+	ReducerRandomSample     string = "RANDOM_SAMPLE"
+)
+
+// Pipeline steps
+const (
+	aggregateStepGroupBy = "GROUPBY"
+	aggregateStepSortBy  = "SORTBY"
+	aggregateStepApply   = "APPLY"
+	aggregateStepLimit   = "LIMIT"
+	aggregateStepFilter  = "FILTER"
+)
+
+type FtReducer struct {
+	function string
+	args     []string
+	alias    string // AS
+}
+
+type FtSortKey struct {
+	property string
+	asc      bool
+}
+
+type FtAggregateStep struct {
+	step     string // GROUPBY, SORTBY, APPLY, LIMIT, FILTER
+	groupby  []string
+	reducers []FtReducer
+	sortby   []FtSortKey
+	sortmax  int
+	expr     string // APPLY and FILTER expression
+	alias    string // APPLY AS
+	offset   int64
+	num      int64
+}
+
 // Search Aggregate Builder
 type FtAggregate struct {
+	indexname  string
+	query      string
+	verbatim   bool
+	load       []string
+	steps      []FtAggregateStep
+	withcursor bool
+	cursor     struct {
+		count   int
+		maxidle int
+	}
+	dialect int
+}
+
+func NewFtAggregate(indexName string) *FtAggregate {
+	return &FtAggregate{
+		indexname: indexName,
+	}
+}
+
+func (fta *FtAggregate) AddIndexName(name string) *FtAggregate {
+	fta.indexname = name
+
+	return fta
+}
+
+func (fta *FtAggregate) AddQuery(query string) *FtAggregate {
+	fta.query = query
+
+	return fta
+}
+
+func (fta *FtAggregate) AddVerbatim(active bool) *FtAggregate {
+	fta.verbatim = active
+
+	return fta
+}
+
+// LOAD {nargs} {identifier} ...
+// Use "*" to load all document attributes.
+func (fta *FtAggregate) AddLoad(fields ...string) *FtAggregate {
+	fta.load = append(fta.load, fields...)
+
+	return fta
+}
+
+// REDUCE {func} {nargs} {arg} ... [AS {name}]
+// Arguments are sent as is, so properties must be written with @ (e.g. "@price").
+func NewReducer(function string, alias string, args ...string) FtReducer {
+	return FtReducer{
+		function: function,
+		args:     args,
+		alias:    alias,
+	}
+}
+
+// GROUPBY {nargs} {property} ... REDUCE ...
+func (fta *FtAggregate) AddGroupBy(properties []string, reducers ...FtReducer) *FtAggregate {
+	fta.steps = append(fta.steps, FtAggregateStep{
+		step:     aggregateStepGroupBy,
+		groupby:  properties,
+		reducers: reducers,
+	})
+
+	return fta
+}
+
+// {property} [ASC|DESC] of SORTBY
+func NewSortKey(property string, asc bool) FtSortKey {
+	return FtSortKey{
+		property: property,
+		asc:      asc,
+	}
+}
+
+// SORTBY {nargs} {property} [ASC|DESC] ... [MAX {num}]
+// max 0 sorts all results.
+func (fta *FtAggregate) AddSortBy(max int, keys ...FtSortKey) *FtAggregate {
+	fta.steps = append(fta.steps, FtAggregateStep{
+		step:    aggregateStepSortBy,
+		sortby:  keys,
+		sortmax: max,
+	})
+
+	return fta
+}
+
+// APPLY {expr} AS {alias}
+func (fta *FtAggregate) AddApply(expr string, alias string) *FtAggregate {
+	fta.steps = append(fta.steps, FtAggregateStep{
+		step:  aggregateStepApply,
+		expr:  expr,
+		alias: alias,
+	})
+
+	return fta
+}
+
+func (fta *FtAggregate) AddLimit(offset, num int64) *FtAggregate {
+	fta.steps = append(fta.steps, FtAggregateStep{
+		step:   aggregateStepLimit,
+		offset: offset,
+		num:    num,
+	})
+
+	return fta
+}
+
+// FILTER {expr}
+func (fta *FtAggregate) AddFilter(expr string) *FtAggregate {
+	fta.steps = append(fta.steps, FtAggregateStep{
+		step: aggregateStepFilter,
+		expr: expr,
+	})
+
+	return fta
+}
+
+// WITHCURSOR [COUNT {read size}] [MAXIDLE {idle timeout}]
+// Zero values use the server defaults.
+func (fta *FtAggregate) AddWithCursor(active bool, count, maxIdle int) *FtAggregate {
+	fta.withcursor = active
+	fta.cursor.count = count
+	fta.cursor.maxidle = maxIdle
+
+	return fta
+}
+
+func (fta *FtAggregate) AddDialect(dialect int) *FtAggregate {
+	fta.dialect = dialect
+
+	return fta
+}
+
+func (fta *FtAggregate) Serialize() []interface{} {
+
+	var queryCode []interface{}
+
+	queryCode = append(queryCode, "FT.AGGREGATE")
+
+	queryCode = append(queryCode, fta.indexname)
+
+	queryCode = append(queryCode, fta.queryString())
+
+	return append(queryCode, fta.serializeOptions()...)
+}
+
+func (fta *FtAggregate) queryString() string {
+	if fta.query == "" {
+		return "*"
+	}
+
+	return fta.query
+}
+
+// serializeOptions returns everything after the query string
+func (fta *FtAggregate) serializeOptions() []interface{} {
+
+	var queryCode []interface{}
+
+	if fta.verbatim {
+		queryCode = append(queryCode, "VERBATIM")
+	}
+
+	if len(fta.load) > 0 {
+		if len(fta.load) == 1 && fta.load[0] == "*" {
+			queryCode = append(queryCode, "LOAD", "*")
+		} else {
+			queryCode = append(queryCode, "LOAD", len(fta.load))
+			for _, l := range fta.load {
+				queryCode = append(queryCode, aggregateProperty(l))
+			}
+		}
+	}
+
+	for _, s := range fta.steps {
+		switch s.step {
+		case aggregateStepGroupBy:
+			queryCode = append(queryCode, "GROUPBY", len(s.groupby))
+			for _, p := range s.groupby {
+				queryCode = append(queryCode, aggregateProperty(p))
+			}
+
+			for _, r := range s.reducers {
+				queryCode = append(queryCode, "REDUCE", r.function, len(r.args))
+				for _, a := range r.args {
+					queryCode = append(queryCode, a)
+				}
+
+				if r.alias != "" {
+					queryCode = append(queryCode, "AS", r.alias)
+				}
+			}
+
+		case aggregateStepSortBy:
+			queryCode = append(queryCode, "SORTBY", len(s.sortby)*2)
+			for _, k := range s.sortby {
+				queryCode = append(queryCode, aggregateProperty(k.property))
+				if k.asc {
+					queryCode = append(queryCode, "ASC")
+				} else {
+					queryCode = append(queryCode, "DESC")
+				}
+			}
+
+			if s.sortmax > 0 {
+				queryCode = append(queryCode, "MAX", s.sortmax)
+			}
+
+		case aggregateStepApply:
+			queryCode = append(queryCode, "APPLY", s.expr, "AS", s.alias)
+
+		case aggregateStepLimit:
+			queryCode = append(queryCode, "LIMIT", s.offset, s.num)
+
+		case aggregateStepFilter:
+			queryCode = append(queryCode, "FILTER", s.expr)
+		}
+	}
+
+	if fta.withcursor {
+		queryCode = append(queryCode, "WITHCURSOR")
+
+		if fta.cursor.count > 0 {
+			queryCode = append(queryCode, "COUNT", fta.cursor.count)
+		}
+
+		if fta.cursor.maxidle > 0 {
+			queryCode = append(queryCode, "MAXIDLE", fta.cursor.maxidle)
+		}
+	}
+
+	if fta.dialect > 0 {
+		queryCode = append(queryCode, "DIALECT", fta.dialect)
+	}
+
+	return queryCode
+}
+
+// aggregateProperty adds the @ prefix the aggregate pipeline expects for properties
+func aggregateProperty(name string) string {
+	if strings.HasPrefix(name, "@") || strings.HasPrefix(name, "$") {
+		return name
+	}
+
+	return "@" + name
 }
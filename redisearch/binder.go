@@ -0,0 +1,399 @@
+package redisearch
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Defaults of SearchBinderConfig
+const (
+	DefaultBindPageSize    = 10
+	DefaultBindMaxPageSize = 100
+	DefaultBindMaxTerms    = 32
+	DefaultBindMaxOffset   = 10000 // MAXSEARCHRESULTS of RediSearch
+)
+
+// Query parameters every SearchBinder reads, facet fields add their own
+const (
+	BindParamQuery  = "q"      // free text, searched in the search fields
+	BindParamFields = "fields" // comma separated subset of the search fields
+	BindParamSort   = "sort"   // sortable field, -field sorts descending
+	BindParamPage   = "page"   // 1 based
+	BindParamSize   = "size"
+)
+
+// Whitelist of what a SearchBinder lets HTTP clients search on one index
+type SearchBinderConfig struct {
+	Index           string
+	SearchFields    []string // TEXT attributes q searches and fields= picks from, all TEXT attributes when empty
+	FacetFields     []string // TAG attributes filtered by ?{field}=a,b (a or b), repeated parameters add values
+	SortFields      []string // attributes ?sort= accepts
+	DefaultSort     string   // used without ?sort=, e.g. "-updated"; empty keeps the relevance order
+	ReturnFields    []string // RETURN, every field when empty
+	DefaultPageSize int64    // DefaultBindPageSize when 0
+	MaxPageSize     int64    // DefaultBindMaxPageSize when 0
+	MaxTerms        int      // DefaultBindMaxTerms when 0
+	MaxOffset       int64    // last result a page may reach, DefaultBindMaxOffset when 0
+}
+
+// BindError reports a query parameter the binder refuses, a 400 Bad Request for HTTP clients
+type BindError struct {
+	Param  string
+	Reason string
+}
+
+func (e *BindError) Error() string {
+	return "redisearch: invalid parameter " + e.Param + ": " + e.Reason
+}
+
+func (e *BindError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// Search bound from query parameters
+type BoundSearch struct {
+	*FtSearch
+	Query  string // q as given
+	Page   int64
+	Size   int64
+	Sort   string              // applied sort, -field for descending
+	Facets map[string][]string // applied facet values by field
+
+	clauses []boundClause
+}
+
+// Part of the bound query, field is empty for the q text
+type boundClause struct {
+	field  string
+	clause string
+}
+
+// queryWithout returns the bound query without the filter of the facet field, so the counts
+// of a facet include the values next to the selected ones
+func (bs *BoundSearch) queryWithout(field string) string {
+	var parts []string
+	for _, c := range bs.clauses {
+		if field == "" || c.field != field {
+			parts = append(parts, c.clause)
+		}
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+
+	return strings.Join(parts, " ")
+}
+
+/*
+SearchBinder turns url.Values into an escaped FtSearch limited to a whitelist.
+It replaces the hand mapping of SearchBuilder fields into FtQuery and FtSearch.
+
+	binder := redisearch.NewSearchBinder(redisearch.SearchBinderConfig{
+		Index:        "idx:drd",
+		SearchFields: []string{"name", "description"},
+		FacetFields:  []string{"cats", "tags"},
+		SortFields:   []string{"updated"},
+		DefaultSort:  "-updated",
+	}).AddIndexDefinition(ftc)
+
+	// ?q=dream test&cats=dream,sleep&sort=-updated&page=2&size=20
+	bs, err := binder.Bind(r.URL.Query())
+	var berr *redisearch.BindError
+	if errors.As(err, &berr) {
+		http.Error(w, berr.Error(), berr.StatusCode())
+		return
+	}
+	res, err := client.DoSearch(ctx, bs.FtSearch)
+*/
+type SearchBinder struct {
+	config SearchBinderConfig
+	schema []IndexAttribute
+	err    error
+}
+
+// NewSearchBinder refuses facet fields named like the q, fields, sort, page and size parameters,
+// see Err. Bind returns the same error.
+func NewSearchBinder(config SearchBinderConfig) *SearchBinder {
+	if config.DefaultPageSize <= 0 {
+		config.DefaultPageSize = DefaultBindPageSize
+	}
+	if config.MaxPageSize <= 0 {
+		config.MaxPageSize = DefaultBindMaxPageSize
+	}
+	if config.DefaultPageSize > config.MaxPageSize {
+		config.DefaultPageSize = config.MaxPageSize
+	}
+	if config.MaxTerms <= 0 {
+		config.MaxTerms = DefaultBindMaxTerms
+	}
+	if config.MaxOffset <= 0 {
+		config.MaxOffset = DefaultBindMaxOffset
+	}
+
+	sb := &SearchBinder{config: config}
+	for _, field := range config.FacetFields {
+		if isBindParam(field) {
+			sb.err = fmt.Errorf("redisearch: facet field %s of %s is a reserved query parameter", field, config.Index)
+			break
+		}
+	}
+
+	return sb
+}
+
+// Err returns the config error NewSearchBinder found, nil when the config is usable
+func (sb *SearchBinder) Err() error {
+	return sb.err
+}
+
+// AddIndexDefinition validates bound searches against the schema of ftc
+func (sb *SearchBinder) AddIndexDefinition(ftc *FtCreate) *SearchBinder {
+	sb.schema = ftc.attributes()
+
+	return sb
+}
+
+// AddIndexInfo validates bound searches against the live schema from FT.INFO
+func (sb *SearchBinder) AddIndexInfo(info *IndexInfo) *SearchBinder {
+	sb.schema = info.Attributes
+
+	return sb
+}
+
+func (sb *SearchBinder) Config() SearchBinderConfig {
+	return sb.config
+}
+
+// Bind returns a *BindError for parameters the whitelist refuses. Other errors come from
+// validating the search against the index schema and point at the binder config.
+func (sb *SearchBinder) Bind(values url.Values) (*BoundSearch, error) {
+	if sb.err != nil {
+		return nil, sb.err
+	}
+	if err := sb.checkParams(values); err != nil {
+		return nil, err
+	}
+
+	bs := &BoundSearch{
+		FtSearch: NewFtSearch(sb.config.Index),
+		Query:    values.Get(BindParamQuery),
+		Facets:   make(map[string][]string),
+	}
+
+	text, err := sb.bindText(values)
+	if err != nil {
+		return nil, err
+	}
+	if text != "" {
+		bs.clauses = append(bs.clauses, boundClause{clause: text})
+	}
+
+	for _, field := range sb.config.FacetFields {
+		var tags []string
+		for _, val := range values[field] {
+			for _, tag := range strings.Split(val, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		if len(tags) > sb.config.MaxTerms {
+			return nil, &BindError{Param: field, Reason: "more than " + strconv.Itoa(sb.config.MaxTerms) + " values"}
+		}
+
+		escaped := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			escaped = append(escaped, EscapeQuery(tag))
+		}
+		bs.clauses = append(bs.clauses, boundClause{field: field, clause: "@" + field + ":{" + strings.Join(escaped, "|") + "}"})
+		bs.Facets[field] = tags
+	}
+
+	bs.AddQuery(bs.queryWithout(""))
+
+	if err := sb.bindSort(values, bs); err != nil {
+		return nil, err
+	}
+	if err := sb.bindPage(values, bs); err != nil {
+		return nil, err
+	}
+
+	if len(sb.config.ReturnFields) > 0 {
+		bs.AddReturnFields(sb.config.ReturnFields...)
+	}
+
+	if sb.schema != nil {
+		bs.schema = sb.schema
+		if err := bs.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return bs, nil
+}
+
+// checkParams refuses unknown and repeated parameters
+func (sb *SearchBinder) checkParams(values url.Values) error {
+	params := make([]string, 0, len(values))
+	for param := range values {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+
+	for _, param := range params {
+		switch {
+		case containsString(sb.config.FacetFields, param):
+		case isBindParam(param):
+			if len(values[param]) > 1 {
+				return &BindError{Param: param, Reason: "given more than once"}
+			}
+		default:
+			return &BindError{Param: param, Reason: "unknown parameter"}
+		}
+	}
+
+	return nil
+}
+
+func isBindParam(param string) bool {
+	return param == BindParamQuery || param == BindParamFields || param == BindParamSort ||
+		param == BindParamPage || param == BindParamSize
+}
+
+func (sb *SearchBinder) bindText(values url.Values) (string, error) {
+	terms := strings.Fields(values.Get(BindParamQuery))
+	if len(terms) > sb.config.MaxTerms {
+		return "", &BindError{Param: BindParamQuery, Reason: "more than " + strconv.Itoa(sb.config.MaxTerms) + " terms"}
+	}
+
+	fields := sb.config.SearchFields
+	if val := values.Get(BindParamFields); val != "" {
+		fields = nil
+		for _, f := range strings.Split(val, ",") {
+			f = strings.TrimSpace(f)
+			if !sb.searchable(f) {
+				return "", &BindError{Param: BindParamFields, Reason: "field " + strconv.Quote(f) + " is not searchable"}
+			}
+			fields = append(fields, f)
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", nil
+	}
+
+	for i, term := range terms {
+		terms[i] = EscapeQuery(term)
+	}
+	text := strings.Join(terms, " ")
+
+	if len(fields) == 0 {
+		return text, nil
+	}
+
+	return "@" + strings.Join(fields, "|") + ":(" + text + ")", nil
+}
+
+// searchable reports whether fields= may name field: one of the SearchFields, or any TEXT
+// attribute of the schema when SearchFields is empty
+func (sb *SearchBinder) searchable(field string) bool {
+	if len(sb.config.SearchFields) > 0 {
+		return containsString(sb.config.SearchFields, field)
+	}
+
+	attr := findAttribute(sb.schema, field)
+
+	return attr != nil && attr.Attribute == field && attr.Type == FieldTypeText
+}
+
+// facetAttribute returns the schema attribute of a facet field, so TagCloud splits and folds
+// its values like the index. Without a schema the field is a TAG with the default separator.
+func (sb *SearchBinder) facetAttribute(field string) IndexAttribute {
+	if attr := findAttribute(sb.schema, field); attr != nil {
+		return *attr
+	}
+
+	return IndexAttribute{Attribute: field, Type: FieldTypeTag}
+}
+
+func (sb *SearchBinder) bindSort(values url.Values, bs *BoundSearch) error {
+	val := values.Get(BindParamSort)
+	if val == "" {
+		val = sb.config.DefaultSort
+	}
+	if val == "" {
+		return nil
+	}
+
+	field := strings.TrimPrefix(val, "-")
+	if !containsString(sb.config.SortFields, field) {
+		if values.Get(BindParamSort) == "" {
+			return fmt.Errorf("redisearch: DefaultSort %s of %s is not one of the SortFields", val, sb.config.Index)
+		}
+		return &BindError{Param: BindParamSort, Reason: "field " + strconv.Quote(field) + " is not sortable"}
+	}
+
+	bs.AddSortBy(field, !strings.HasPrefix(val, "-"))
+	bs.Sort = val
+
+	return nil
+}
+
+func (sb *SearchBinder) bindPage(values url.Values, bs *BoundSearch) error {
+	bs.Page, bs.Size = 1, sb.config.DefaultPageSize
+
+	if val := values.Get(BindParamPage); val != "" {
+		page, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || page < 1 {
+			return &BindError{Param: BindParamPage, Reason: "want a number from 1"}
+		}
+		bs.Page = page
+	}
+
+	if val := values.Get(BindParamSize); val != "" {
+		size, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || size < 1 || size > sb.config.MaxPageSize {
+			return &BindError{Param: BindParamSize, Reason: "want a number from 1 to " + strconv.FormatInt(sb.config.MaxPageSize, 10)}
+		}
+		bs.Size = size
+	}
+
+	// (page-1)*size+size > MaxOffset, without overflowing the multiplication
+	if bs.Page > sb.config.MaxOffset/bs.Size {
+		return &BindError{Param: BindParamPage, Reason: "pages end at result " + strconv.FormatInt(sb.config.MaxOffset, 10)}
+	}
+	bs.AddLimit((bs.Page-1)*bs.Size, bs.Size)
+
+	return nil
+}
+
+// EscapeQuery escapes the punctuation and spaces of s so RediSearch reads it as a single term or tag
+func EscapeQuery(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,198 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testSearchBinder() *SearchBinder {
+	return NewSearchBinder(SearchBinderConfig{
+		Index:        "idx:drd",
+		SearchFields: []string{"name", "desc"},
+		FacetFields:  []string{"cats"},
+		SortFields:   []string{"updated"},
+		DefaultSort:  "-updated",
+		MaxPageSize:  50,
+	}).AddIndexDefinition(testIndexDefinition())
+}
+
+func TestSearchBinder_Bind(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Defaults",
+			query: "",
+			want:  "FT.SEARCH idx:drd * SORTBY updated DESC LIMIT 0 10",
+		},
+		{
+			name:  "Text Facets Sort Page",
+			query: "q=dream+test&cats=dream,sleep&cats=night&sort=updated&page=3&size=20",
+			want:  "FT.SEARCH idx:drd @name|desc:(dream test) @cats:{dream|sleep|night} SORTBY updated ASC LIMIT 40 20",
+		},
+		{
+			name:  "Restricted Fields",
+			query: "q=dream&fields=desc",
+			want:  "FT.SEARCH idx:drd @desc:(dream) SORTBY updated DESC LIMIT 0 10",
+		},
+		{
+			name:  "Escaped Input",
+			query: "q=" + url.QueryEscape(`user@example.com -(x|y)* "}`) + "&cats=" + url.QueryEscape("sci fi}"),
+			want:  `FT.SEARCH idx:drd @name|desc:(user\@example\.com \-\(x\|y\)\* \"\}) @cats:{sci\ fi\}} SORTBY updated DESC LIMIT 0 10`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bs, err := testSearchBinder().Bind(values)
+			if err != nil {
+				t.Fatalf("Bind() error = %v", err)
+			}
+
+			if got := strings.Trim(fmt.Sprint(bs.Serialize()), "[]"); got != tt.want {
+				t.Errorf("Bind() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchBinder_BindErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantParam string
+	}{
+		{"Unknown Parameter", "q=dream&tags=x", "tags"},
+		{"Repeated Parameter", "q=dream&q=test", "q"},
+		{"Not Searchable", "q=dream&fields=name,cats", "fields"},
+		{"Not Sortable", "sort=-name", "sort"},
+		{"Bad Page", "page=0", "page"},
+		{"Page Size Too Large", "size=51", "size"},
+		{"Bad Page Size", "size=ten", "size"},
+		{"Page Past Max Offset", "page=201&size=50", "page"},
+		{"Page Overflow", "page=9223372036854775807&size=50", "page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = testSearchBinder().Bind(values)
+			var berr *BindError
+			if !errors.As(err, &berr) || berr.Param != tt.wantParam || berr.StatusCode() != 400 {
+				t.Errorf("Bind() error = %v, want a BindError on %s", err, tt.wantParam)
+			}
+		})
+	}
+
+	// the last page ends exactly at MaxOffset
+	if _, err := testSearchBinder().Bind(url.Values{"page": {"200"}, "size": {"50"}}); err != nil {
+		t.Errorf("Bind() of the last page error = %v", err)
+	}
+
+	// a whitelist that does not fit the schema is a server side error
+	binder := NewSearchBinder(SearchBinderConfig{Index: "idx:drd", FacetFields: []string{"name"}}).AddIndexDefinition(testIndexDefinition())
+	_, err := binder.Bind(url.Values{"name": {"dream"}})
+	var verr *SearchValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("Bind() error = %v, want a *SearchValidationError", err)
+	}
+}
+
+func TestSearchBinder_SchemaFields(t *testing.T) {
+	// without SearchFields fields= accepts the TEXT attributes of the schema
+	binder := NewSearchBinder(SearchBinderConfig{Index: "idx:drd"}).AddIndexDefinition(testIndexDefinition())
+
+	bs, err := binder.Bind(url.Values{"q": {"dream"}, "fields": {"name,desc"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Trim(fmt.Sprint(bs.Serialize()), "[]"), "FT.SEARCH idx:drd @name|desc:(dream) LIMIT 0 10"; got != want {
+		t.Errorf("Bind() = %s, want %s", got, want)
+	}
+
+	tests := []struct {
+		name   string
+		binder *SearchBinder
+		fields string
+	}{
+		{"Tag Attribute", binder, "cats"},
+		{"Identifier Of An Alias", binder, "description"},
+		{"Unknown Attribute", binder, "slug"},
+		{"No Schema", NewSearchBinder(SearchBinderConfig{Index: "idx:drd"}), "name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.binder.Bind(url.Values{"q": {"dream"}, "fields": {tt.fields}})
+			var berr *BindError
+			if !errors.As(err, &berr) || berr.Param != BindParamFields {
+				t.Errorf("Bind(fields=%s) error = %v, want a BindError on fields", tt.fields, err)
+			}
+		})
+	}
+}
+
+func TestNewSearchBinder_ReservedFacets(t *testing.T) {
+	for _, param := range []string{BindParamQuery, BindParamFields, BindParamSort, BindParamPage, BindParamSize} {
+		t.Run(param, func(t *testing.T) {
+			binder := NewSearchBinder(SearchBinderConfig{Index: "idx:drd", FacetFields: []string{"cats", param}})
+			if binder.Err() == nil {
+				t.Fatalf("Err() = nil, want facet field %s refused", param)
+			}
+
+			_, err := binder.Bind(url.Values{})
+			var berr *BindError
+			if err == nil || errors.As(err, &berr) {
+				t.Errorf("Bind() error = %v, want the config error", err)
+			}
+		})
+	}
+
+	if err := testSearchBinder().Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestSearchBinder_Search(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	binder := NewSearchBinder(SearchBinderConfig{
+		Index:        "idx:drd",
+		SearchFields: []string{"name"},
+		FacetFields:  []string{"cats"},
+		SortFields:   []string{"updated"},
+		DefaultSort:  "-updated",
+	})
+
+	bs, err := binder.Bind(url.Values{"q": {"dream"}, "cats": {"dream,test"}, "size": {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := rsc.DoSearch(context.Background(), bs.FtSearch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, doc := range res.Docs {
+		ids = append(ids, doc.ID)
+	}
+	if res.Total != 2 || !reflect.DeepEqual(ids, []string{"drd:2"}) {
+		t.Errorf("DoSearch() = %d %v, want 2 [drd:2]", res.Total, ids)
+	}
+}
@@ -0,0 +1,422 @@
+package redisearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Bulk indexer defaults
+const (
+	DefaultBulkWorkers       = 4
+	DefaultBulkBatchSize     = 500
+	DefaultBulkFlushInterval = time.Second
+	DefaultBulkMaxRetries    = 3
+	DefaultBulkRetryBackoff  = 100 * time.Millisecond
+)
+
+// Document to write: HSET {key} {field} {value} ... or JSON.SET {key} $ {json} when JSON is set
+type BulkDocument struct {
+	Key    string
+	Fields map[string]interface{}
+	JSON   interface{}
+}
+
+// Document that could not be written
+type BulkError struct {
+	Key string
+	Err error
+}
+
+func (e BulkError) Error() string {
+	return e.Key + ": " + e.Err.Error()
+}
+
+// Totals reported by BulkIndexer.Close
+type BulkStats struct {
+	Added    int64 // documents accepted by Add
+	Indexed  int64 // documents written
+	Failed   int64 // documents given up on
+	Retried  int64 // document write retries
+	Batches  int64 // pipelines sent, including retries
+	Duration time.Duration
+	Errors   []BulkError
+}
+
+// DocsPerSecond returns the write throughput
+func (s *BulkStats) DocsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+
+	return float64(s.Indexed) / s.Duration.Seconds()
+}
+
+// BulkIndexer writes documents in pipelined batches with a pool of workers.
+// A batch is sent when it reaches the batch size or when the flush interval passes.
+// Add blocks when all workers are busy, so producers can not outrun Redis.
+//
+//	bi := redisearch.NewBulkIndexer(client).AddWorkers(8).AddBatchSize(1000).Start(ctx)
+//	for _, item := range items {
+//		bi.Add(redisearch.BulkDocument{Key: "drd:" + item.UID, Fields: item.Map()})
+//	}
+//	stats, err := bi.Close()
+type BulkIndexer struct {
+	rsc           *RedisearchClient
+	workers       int
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	retryBackoff  time.Duration
+	onError       func(BulkError)
+
+	ctx     context.Context
+	items   chan BulkDocument
+	batches chan []BulkDocument
+	wg      sync.WaitGroup
+	mu      sync.RWMutex
+	started time.Time
+	closed  bool
+
+	stats struct {
+		added   int64
+		indexed int64
+		failed  int64
+		retried int64
+		batches int64
+	}
+	errMu  sync.Mutex
+	errors []BulkError
+}
+
+func NewBulkIndexer(rsc *RedisearchClient) *BulkIndexer {
+	return &BulkIndexer{
+		rsc:           rsc,
+		workers:       DefaultBulkWorkers,
+		batchSize:     DefaultBulkBatchSize,
+		flushInterval: DefaultBulkFlushInterval,
+		maxRetries:    DefaultBulkMaxRetries,
+		retryBackoff:  DefaultBulkRetryBackoff,
+	}
+}
+
+func (bi *BulkIndexer) AddWorkers(workers int) *BulkIndexer {
+	if workers > 0 {
+		bi.workers = workers
+	}
+
+	return bi
+}
+
+func (bi *BulkIndexer) AddBatchSize(size int) *BulkIndexer {
+	if size > 0 {
+		bi.batchSize = size
+	}
+
+	return bi
+}
+
+func (bi *BulkIndexer) AddFlushInterval(interval time.Duration) *BulkIndexer {
+	if interval > 0 {
+		bi.flushInterval = interval
+	}
+
+	return bi
+}
+
+// AddMaxRetries sets how many times a document is retried after a transient error.
+// The backoff doubles after every attempt.
+func (bi *BulkIndexer) AddMaxRetries(retries int, backoff time.Duration) *BulkIndexer {
+	bi.maxRetries = retries
+	bi.retryBackoff = backoff
+
+	return bi
+}
+
+// AddOnError is called from the workers for every failed document
+func (bi *BulkIndexer) AddOnError(fn func(BulkError)) *BulkIndexer {
+	bi.onError = fn
+
+	return bi
+}
+
+// Start runs the batcher and the workers until Close
+func (bi *BulkIndexer) Start(ctx context.Context) *BulkIndexer {
+	bi.ctx = ctx
+	bi.started = time.Now()
+	bi.items = make(chan BulkDocument, bi.batchSize)
+	bi.batches = make(chan []BulkDocument, bi.workers)
+
+	for i := 0; i < bi.workers; i++ {
+		bi.wg.Add(1)
+		go bi.worker()
+	}
+
+	bi.wg.Add(1)
+	go bi.batcher()
+
+	return bi
+}
+
+// Add queues a document. It returns an error after Close.
+func (bi *BulkIndexer) Add(doc BulkDocument) error {
+	bi.mu.RLock()
+	defer bi.mu.RUnlock()
+
+	if bi.closed || bi.items == nil {
+		return errors.New("redisearch: bulk indexer is not running")
+	}
+
+	select {
+	case bi.items <- doc:
+		atomic.AddInt64(&bi.stats.added, 1)
+		return nil
+	case <-bi.ctx.Done():
+		return bi.ctx.Err()
+	}
+}
+
+// Feed adds documents from ch until it is closed
+func (bi *BulkIndexer) Feed(ch <-chan BulkDocument) error {
+	for doc := range ch {
+		if err := bi.Add(doc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close flushes the queued documents, waits for the workers and returns the totals.
+// The error is the first failed document, the stats hold all of them.
+func (bi *BulkIndexer) Close() (*BulkStats, error) {
+	bi.mu.Lock()
+	if bi.closed || bi.items == nil {
+		bi.mu.Unlock()
+		return nil, errors.New("redisearch: bulk indexer is not running")
+	}
+	bi.closed = true
+	close(bi.items)
+	bi.mu.Unlock()
+
+	bi.wg.Wait()
+
+	stats := &BulkStats{
+		Added:    atomic.LoadInt64(&bi.stats.added),
+		Indexed:  atomic.LoadInt64(&bi.stats.indexed),
+		Failed:   atomic.LoadInt64(&bi.stats.failed),
+		Retried:  atomic.LoadInt64(&bi.stats.retried),
+		Batches:  atomic.LoadInt64(&bi.stats.batches),
+		Duration: time.Since(bi.started),
+		Errors:   bi.errors,
+	}
+
+	if len(stats.Errors) > 0 {
+		return stats, stats.Errors[0]
+	}
+
+	return stats, nil
+}
+
+func (bi *BulkIndexer) batcher() {
+	defer bi.wg.Done()
+	defer close(bi.batches)
+
+	ticker := time.NewTicker(bi.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]BulkDocument, 0, bi.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		bi.batches <- batch
+		batch = make([]BulkDocument, 0, bi.batchSize)
+	}
+
+	for {
+		select {
+		case doc, ok := <-bi.items:
+			if !ok {
+				flush()
+				return
+			}
+
+			batch = append(batch, doc)
+			if len(batch) >= bi.batchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (bi *BulkIndexer) worker() {
+	defer bi.wg.Done()
+
+	for batch := range bi.batches {
+		bi.write(batch)
+	}
+}
+
+// write sends the batch and retries the documents that failed with a transient error
+func (bi *BulkIndexer) write(batch []BulkDocument) {
+	backoff := bi.retryBackoff
+
+	for attempt := 0; len(batch) > 0; attempt++ {
+		if attempt > 0 {
+			atomic.AddInt64(&bi.stats.retried, int64(len(batch)))
+
+			select {
+			case <-time.After(backoff):
+			case <-bi.ctx.Done():
+				for _, doc := range batch {
+					bi.fail(doc, bi.ctx.Err())
+				}
+				return
+			}
+			backoff *= 2
+		}
+
+		errs := bi.exec(batch)
+
+		var retry []BulkDocument
+		for i, doc := range batch {
+			switch {
+			case errs[i] == nil:
+				atomic.AddInt64(&bi.stats.indexed, 1)
+			case attempt < bi.maxRetries && isTransientError(errs[i]):
+				retry = append(retry, doc)
+			default:
+				bi.fail(doc, errs[i])
+			}
+		}
+
+		batch = retry
+	}
+}
+
+// exec sends one pipeline and returns the error of every document
+func (bi *BulkIndexer) exec(batch []BulkDocument) []error {
+	atomic.AddInt64(&bi.stats.batches, 1)
+
+	errs := make([]error, len(batch))
+
+	cmds := make([][]interface{}, 0, len(batch))
+	sent := make([]int, 0, len(batch))
+	for i, doc := range batch {
+		args, err := bulkArgs(doc)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		cmds = append(cmds, args)
+		sent = append(sent, i)
+	}
+
+	if len(sent) == 0 {
+		return errs
+	}
+
+	replies, err := bi.rsc.pipeline(bi.ctx, cmds)
+
+	written := make([]string, 0, len(sent))
+	for j, i := range sent {
+		if j < len(replies) {
+			errs[i] = replies[j].Err()
+		} else {
+			errs[i] = err
+		}
+
+		if errs[i] == nil {
+			written = append(written, batch[i].Key)
+		}
+	}
+
+	bi.rsc.invalidateKeys(bi.ctx, written...)
+
+	return errs
+}
+
+func (bi *BulkIndexer) fail(doc BulkDocument, err error) {
+	atomic.AddInt64(&bi.stats.failed, 1)
+
+	be := BulkError{Key: doc.Key, Err: err}
+
+	bi.errMu.Lock()
+	bi.errors = append(bi.errors, be)
+	bi.errMu.Unlock()
+
+	if bi.onError != nil {
+		bi.onError(be)
+	}
+}
+
+func bulkArgs(doc BulkDocument) ([]interface{}, error) {
+	if doc.Key == "" {
+		return nil, errors.New("redisearch: document key is empty")
+	}
+
+	if doc.JSON != nil {
+		data, err := json.Marshal(doc.JSON)
+		if err != nil {
+			return nil, err
+		}
+
+		return []interface{}{"JSON.SET", doc.Key, "$", string(data)}, nil
+	}
+
+	if len(doc.Fields) == 0 {
+		return nil, errors.New("redisearch: document has no fields")
+	}
+
+	// sorted for stable commands
+	fields := make([]string, 0, len(doc.Fields))
+	for f := range doc.Fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	args := make([]interface{}, 0, 2+len(fields)*2)
+	args = append(args, "HSET", doc.Key)
+	for _, f := range fields {
+		args = append(args, f, doc.Fields[f])
+	}
+
+	return args, nil
+}
+
+// isTransientError reports errors worth retrying: network failures and busy or failing over servers
+func isTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	msg := err.Error()
+	for _, prefix := range []string{"LOADING", "BUSY", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN", "READONLY"} {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,184 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_bulkArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     BulkDocument
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name: "Hash Document",
+			doc: BulkDocument{
+				Key:    "drd:1",
+				Fields: map[string]interface{}{"uid": "1", "name": "Dream Test 1", "updated": int64(1650000000)},
+			},
+			want: []interface{}{"HSET", "drd:1", "name", "Dream Test 1", "uid", "1", "updated", int64(1650000000)},
+		},
+		{
+			name: "JSON Document",
+			doc: BulkDocument{
+				Key:  "drj:1",
+				JSON: map[string]interface{}{"uid": "1"},
+			},
+			want: []interface{}{"JSON.SET", "drj:1", "$", `{"uid":"1"}`},
+		},
+		{
+			name:    "Empty Key",
+			doc:     BulkDocument{Fields: map[string]interface{}{"uid": "1"}},
+			wantErr: true,
+		},
+		{
+			name:    "No Fields",
+			doc:     BulkDocument{Key: "drd:1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := bulkArgs(tt.doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bulkArgs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bulkArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "No Error", err: nil, want: false},
+		{name: "Connection Closed", err: fmt.Errorf("read: %w", io.EOF), want: true},
+		{name: "Loading Dataset", err: errors.New("LOADING Redis is loading the dataset in memory"), want: true},
+		{name: "Cluster Down", err: errors.New("CLUSTERDOWN The cluster is down"), want: true},
+		{name: "Wrong Type", err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBulkIndexer_Retry(t *testing.T) {
+	loading := errors.New("LOADING Redis is loading the dataset in memory")
+
+	tests := []struct {
+		name        string
+		replies     []error // one per command in the order the pipelines send them, nil is a success
+		maxRetries  int
+		wantStats   BulkStats
+		wantErrKeys []string
+		wantErr     string // prefix of the Close error
+	}{
+		{
+			name:       "Retried Once",
+			replies:    []error{loading, nil, nil},
+			maxRetries: 2,
+			wantStats:  BulkStats{Added: 2, Indexed: 2, Retried: 1, Batches: 2},
+		},
+		{
+			name:        "Retries Exhausted",
+			replies:     []error{loading, nil, loading, loading},
+			maxRetries:  2,
+			wantStats:   BulkStats{Added: 2, Indexed: 1, Failed: 1, Retried: 2, Batches: 3},
+			wantErrKeys: []string{"drd:1"},
+			wantErr:     "drd:1: LOADING",
+		},
+		{
+			name:        "Not Transient",
+			replies:     []error{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), nil},
+			maxRetries:  2,
+			wantStats:   BulkStats{Added: 2, Indexed: 1, Failed: 1, Batches: 1},
+			wantErrKeys: []string{"drd:1"},
+			wantErr:     "drd:1: WRONGTYPE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := NewScriptedExecutor()
+			for _, err := range tt.replies {
+				if err != nil {
+					exec.AddError(err)
+				} else {
+					exec.AddReply(int64(1))
+				}
+			}
+
+			bi := NewBulkIndexer(NewRedisearchClientWithExecutor("test", exec)).
+				AddWorkers(1).
+				AddBatchSize(2).
+				AddMaxRetries(tt.maxRetries, time.Millisecond).
+				Start(context.Background())
+
+			for _, key := range []string{"drd:1", "drd:2"} {
+				if err := bi.Add(BulkDocument{Key: key, Fields: map[string]interface{}{"name": key}}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			stats, err := bi.Close()
+
+			var errKeys []string
+			for _, be := range stats.Errors {
+				errKeys = append(errKeys, be.Key)
+			}
+			if !reflect.DeepEqual(errKeys, tt.wantErrKeys) {
+				t.Errorf("failed keys = %v, want %v", errKeys, tt.wantErrKeys)
+			}
+			if (err == nil) != (tt.wantErr == "") || err != nil && !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Close() error = %v, want %q", err, tt.wantErr)
+			}
+
+			stats.Duration, stats.Errors = 0, nil
+			if !reflect.DeepEqual(*stats, tt.wantStats) {
+				t.Errorf("Close() stats = %+v, want %+v", *stats, tt.wantStats)
+			}
+			if exec.Pending() != 0 {
+				t.Errorf("%d scripted replies left", exec.Pending())
+			}
+		})
+	}
+}
+
+func TestBulkIndexer_Workers(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	bi := NewBulkIndexer(rsc).AddWorkers(4).AddBatchSize(3).Start(context.Background())
+	for i := 0; i < 50; i++ {
+		doc := BulkDocument{Key: fmt.Sprintf("drd:bulk:%d", i), Fields: map[string]interface{}{"name": "bulk", "updated": i}}
+		if err := bi.Add(doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := bi.Close()
+	if err != nil || stats.Indexed != 50 || stats.Batches < 17 {
+		t.Fatalf("Close() = %+v, %v, want 50 documents in at least 17 batches", stats, err)
+	}
+
+	res, err := rsc.DoSearch(context.Background(), NewFtSearch("idx:drd").AddQuery("@name:(bulk)").AddLimit(0, 0))
+	if err != nil || res.Total != 50 {
+		t.Errorf("DoSearch() = %+v, %v, want 50 documents", res, err)
+	}
+}
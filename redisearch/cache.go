@@ -0,0 +1,462 @@
+package redisearch
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// CacheBackend stores encoded FT.SEARCH replies grouped by index
+type CacheBackend interface {
+	Get(ctx context.Context, index, key string) ([]byte, bool, error)
+	Set(ctx context.Context, index, key string, val []byte, ttl time.Duration) error
+	// Invalidate drops every entry of the index
+	Invalidate(ctx context.Context, index string) error
+}
+
+// Cache counters
+type CacheStats struct {
+	Hits          int64
+	Misses        int64
+	Invalidations int64
+	Errors        int64 // backend errors, a failed lookup counts as a miss and a failed invalidation does not fail the write
+}
+
+type cachedIndex struct {
+	ttl      time.Duration
+	prefixes []string
+}
+
+// SearchCache caches FT.SEARCH replies of registered indexes, keyed on the serialized command.
+// Writes through HSet, HMSet, HDel, Del, the bulk indexer, Alter and DropIndex invalidate the
+// indexes whose prefixes cover the written key. Writes by other clients are only caught by the TTL.
+//
+//	cache := redisearch.NewSearchCache(redisearch.NewMemoryCache(10000)).
+//		AddIndex("index_terms", time.Minute, "term:")
+//	client.EnableSearchCache(cache)
+type SearchCache struct {
+	backend CacheBackend
+	indexes map[string]cachedIndex
+
+	mu          sync.RWMutex      // orders the store of a miss against invalidations
+	generations map[string]uint64 // bumped by every invalidation of an index
+
+	hits          int64
+	misses        int64
+	invalidations int64
+	errors        int64
+}
+
+func NewSearchCache(backend CacheBackend) *SearchCache {
+	return &SearchCache{
+		backend:     backend,
+		indexes:     make(map[string]cachedIndex),
+		generations: make(map[string]uint64),
+	}
+}
+
+// AddIndex enables caching for the index. No prefixes means every key belongs to the index, like FT.CREATE.
+func (sc *SearchCache) AddIndex(indexName string, ttl time.Duration, prefixes ...string) *SearchCache {
+	sc.indexes[indexName] = cachedIndex{ttl: ttl, prefixes: prefixes}
+
+	return sc
+}
+
+// AddIndexDefinition enables caching for the index with the prefixes of its definition
+func (sc *SearchCache) AddIndexDefinition(ftc *FtCreate, ttl time.Duration) *SearchCache {
+	return sc.AddIndex(ftc.indexname, ttl, ftc.prefix...)
+}
+
+// EnableSearchCache caches searches of the indexes registered on the cache.
+// Enable it before the client is shared between goroutines.
+func (rsc *RedisearchClient) EnableSearchCache(sc *SearchCache) *RedisearchClient {
+	rsc.cache = sc
+
+	return rsc
+}
+
+func (sc *SearchCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:          atomic.LoadInt64(&sc.hits),
+		Misses:        atomic.LoadInt64(&sc.misses),
+		Invalidations: atomic.LoadInt64(&sc.invalidations),
+		Errors:        atomic.LoadInt64(&sc.errors),
+	}
+}
+
+// Invalidate drops the cached searches of the index. Searches started before it do not store their replies.
+func (sc *SearchCache) Invalidate(ctx context.Context, indexName string) error {
+	atomic.AddInt64(&sc.invalidations, 1)
+
+	sc.mu.Lock()
+	sc.generations[indexName]++
+	sc.mu.Unlock()
+
+	if err := sc.backend.Invalidate(ctx, indexName); err != nil {
+		atomic.AddInt64(&sc.errors, 1)
+		return err
+	}
+
+	return nil
+}
+
+// InvalidateKeys drops the cached searches of every index covering one of the keys
+func (sc *SearchCache) InvalidateKeys(ctx context.Context, keys ...string) error {
+	var firstErr error
+	for name, ci := range sc.indexes {
+		if !coversAny(ci.prefixes, keys) {
+			continue
+		}
+
+		if err := sc.Invalidate(ctx, name); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func coversAny(prefixes []string, keys []string) bool {
+	if len(prefixes) == 0 {
+		return len(keys) > 0
+	}
+
+	for _, key := range keys {
+		for _, p := range prefixes {
+			if strings.HasPrefix(key, p) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cacheable returns the index of an FT.SEARCH on a registered index
+func (sc *SearchCache) cacheable(args []interface{}) (string, cachedIndex, bool) {
+	if len(args) < 3 || !strings.EqualFold(replyString(args[0]), "FT.SEARCH") {
+		return "", cachedIndex{}, false
+	}
+
+	name := replyString(args[1])
+	ci, ok := sc.indexes[name]
+
+	return name, ci, ok
+}
+
+// cachedDo answers searches of registered indexes from the cache and stores the replies of misses
+func (rsc *RedisearchClient) cachedDo(ctx context.Context, args []interface{}, do func() *redis.Cmd) *redis.Cmd {
+	sc := rsc.cache
+
+	index, ci, ok := sc.cacheable(args)
+	if !ok {
+		return do()
+	}
+
+	key := cacheKey(args)
+
+	data, found, err := sc.backend.Get(ctx, index, key)
+	if err != nil {
+		atomic.AddInt64(&sc.errors, 1)
+	}
+
+	if found {
+		if val, err := decodeReply(data); err == nil {
+			atomic.AddInt64(&sc.hits, 1)
+
+			cmd := redis.NewCmd(ctx, args...)
+			cmd.SetVal(val)
+			return cmd
+		}
+		atomic.AddInt64(&sc.errors, 1)
+	}
+
+	atomic.AddInt64(&sc.misses, 1)
+
+	gen := sc.generation(index)
+
+	cmd := do()
+	if cmd.Err() != nil {
+		return cmd
+	}
+
+	if data, err := json.Marshal(cmd.Val()); err == nil {
+		sc.store(ctx, index, key, data, ci.ttl, gen)
+	}
+
+	return cmd
+}
+
+func (sc *SearchCache) generation(index string) uint64 {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	return sc.generations[index]
+}
+
+// store keeps the reply of a miss unless the index was invalidated since the miss started.
+// Invalidations wait for a running store, so they drop what it wrote.
+func (sc *SearchCache) store(ctx context.Context, index, key string, data []byte, ttl time.Duration, gen uint64) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	if sc.generations[index] != gen {
+		return
+	}
+
+	if err := sc.backend.Set(ctx, index, key, data, ttl); err != nil {
+		atomic.AddInt64(&sc.errors, 1)
+	}
+}
+
+// invalidateKeys is called after every write of the client. The write already went through,
+// so a failed invalidation is only counted in CacheStats.Errors and the stale searches live until their TTL.
+func (rsc *RedisearchClient) invalidateKeys(ctx context.Context, keys ...string) {
+	if rsc.cache == nil {
+		return
+	}
+
+	rsc.cache.InvalidateKeys(ctx, keys...)
+}
+
+// invalidateIndex is called after schema changes, failures are counted like those of invalidateKeys
+func (rsc *RedisearchClient) invalidateIndex(ctx context.Context, indexName string) {
+	if rsc.cache == nil {
+		return
+	}
+
+	if _, ok := rsc.cache.indexes[indexName]; !ok {
+		return
+	}
+
+	rsc.cache.Invalidate(ctx, indexName)
+}
+
+// cacheKey hashes the serialized command
+func cacheKey(args []interface{}) string {
+	h := sha1.New()
+	for _, arg := range args {
+		fmt.Fprint(h, arg)
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// decodeReply decodes a JSON encoded RESP2 reply, numbers become int64 again
+func decodeReply(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var val interface{}
+	if err := dec.Decode(&val); err != nil {
+		return nil, err
+	}
+
+	return restoreInts(val)
+}
+
+func restoreInts(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case json.Number:
+		return v.Int64()
+	case []interface{}:
+		for i := range v {
+			item, err := restoreInts(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = item
+		}
+	}
+
+	return val, nil
+}
+
+type memoryEntry struct {
+	index   string
+	key     string
+	val     []byte
+	expires time.Time
+}
+
+// MemoryCache is an in-process LRU CacheBackend
+type MemoryCache struct {
+	capacity int
+	mu       sync.Mutex
+	lru      *list.List // front is the most recently used
+	entries  map[string]map[string]*list.Element
+}
+
+// NewMemoryCache keeps at most capacity entries
+func NewMemoryCache(capacity int) *MemoryCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+
+	return &MemoryCache{
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  make(map[string]map[string]*list.Element),
+	}
+}
+
+func (mc *MemoryCache) Get(ctx context.Context, index, key string) ([]byte, bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	el, ok := mc.entries[index][key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := el.Value.(*memoryEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		mc.remove(el)
+		return nil, false, nil
+	}
+
+	mc.lru.MoveToFront(el)
+
+	return entry.val, true, nil
+}
+
+func (mc *MemoryCache) Set(ctx context.Context, index, key string, val []byte, ttl time.Duration) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	entry := &memoryEntry{index: index, key: key, val: val}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	if el, ok := mc.entries[index][key]; ok {
+		el.Value = entry
+		mc.lru.MoveToFront(el)
+		return nil
+	}
+
+	if mc.entries[index] == nil {
+		mc.entries[index] = make(map[string]*list.Element)
+	}
+	mc.entries[index][key] = mc.lru.PushFront(entry)
+
+	for mc.lru.Len() > mc.capacity {
+		mc.remove(mc.lru.Back())
+	}
+
+	return nil
+}
+
+func (mc *MemoryCache) Invalidate(ctx context.Context, index string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for _, el := range mc.entries[index] {
+		mc.lru.Remove(el)
+	}
+	delete(mc.entries, index)
+
+	return nil
+}
+
+// Len returns the number of cached entries
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.lru.Len()
+}
+
+func (mc *MemoryCache) remove(el *list.Element) {
+	entry := el.Value.(*memoryEntry)
+
+	mc.lru.Remove(el)
+	delete(mc.entries[entry.index], entry.key)
+	if len(mc.entries[entry.index]) == 0 {
+		delete(mc.entries, entry.index)
+	}
+}
+
+// RedisCache is a CacheBackend shared by every client. Entries of an index live in one hash
+// ({prefix}{index}), so invalidation is a single DEL. The hash expires with the longest TTL
+// written to it and every field carries its own expiry.
+type RedisCache struct {
+	executor Executor
+	prefix   string
+}
+
+// NewRedisCache stores entries under keyPrefix, use rsc.Executor to share the client connections
+func NewRedisCache(executor Executor, keyPrefix string) *RedisCache {
+	return &RedisCache{executor: executor, prefix: keyPrefix}
+}
+
+func (rc *RedisCache) Get(ctx context.Context, index, key string) ([]byte, bool, error) {
+	data, err := rc.executor.Do(ctx, "HGET", rc.prefix+index, key).Text()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// {unix nano expiry}:{reply}
+	sep := strings.IndexByte(data, ':')
+	if sep < 0 {
+		return nil, false, nil
+	}
+
+	var expires int64
+	if _, err := fmt.Sscan(data[:sep], &expires); err != nil {
+		return nil, false, nil
+	}
+
+	if expires > 0 && time.Now().UnixNano() > expires {
+		rc.executor.Do(ctx, "HDEL", rc.prefix+index, key)
+		return nil, false, nil
+	}
+
+	return []byte(data[sep+1:]), true, nil
+}
+
+func (rc *RedisCache) Set(ctx context.Context, index, key string, val []byte, ttl time.Duration) error {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	cmds := [][]interface{}{
+		{"HSET", rc.prefix + index, key, fmt.Sprintf("%d:%s", expires, val)},
+	}
+
+	if ttl > 0 {
+		// only extend the hash expiry, never shorten it (EXPIRE GT needs Redis 7)
+		cmds = append(cmds, []interface{}{"EVAL", extendExpiryScript, 1, rc.prefix + index, ttl.Milliseconds()})
+	}
+
+	_, err := rc.executor.Pipeline(ctx, cmds)
+
+	return err
+}
+
+func (rc *RedisCache) Invalidate(ctx context.Context, index string) error {
+	return rc.executor.Do(ctx, "DEL", rc.prefix+index).Err()
+}
+
+const extendExpiryScript = `
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl >= 0 and ttl >= tonumber(ARGV[1]) then
+	return 0
+end
+return redis.call('PEXPIRE', KEYS[1], ARGV[1])
+`
@@ -0,0 +1,193 @@
+package redisearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestRedisearchClient_EnableSearchCache(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	cache := NewSearchCache(NewMemoryCache(100)).AddIndex("idx:drd", time.Minute, "drd:")
+	rsc.EnableSearchCache(cache)
+
+	ctx := context.Background()
+	search := func() int64 {
+		res, err := rsc.DoSearch(ctx, NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Total
+	}
+
+	if got := search(); got != 2 {
+		t.Fatalf("DoSearch() total = %d, want 2", got)
+	}
+	search()
+
+	if got := cache.Stats(); got.Hits != 1 || got.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 1 hit and 1 miss", got)
+	}
+
+	// a write outside the index prefixes keeps the cache
+	if _, err := rsc.HSet("other:1", "name", "Dream Other"); err != nil {
+		t.Fatal(err)
+	}
+	search()
+
+	if _, err := rsc.HSet("drd:4", "name", "Dream Test 4"); err != nil {
+		t.Fatal(err)
+	}
+	if got := search(); got != 3 {
+		t.Errorf("DoSearch() total after HSet = %d, want 3", got)
+	}
+
+	if _, err := rsc.Del("drd:1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := search(); got != 2 {
+		t.Errorf("DoSearch() total after Del = %d, want 2", got)
+	}
+
+	if got := cache.Stats(); got.Hits != 2 || got.Misses != 3 || got.Invalidations != 2 {
+		t.Errorf("Stats() = %+v, want 2 hits, 3 misses and 2 invalidations", got)
+	}
+}
+
+func TestSearchCache_InvalidatedMiss(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryCache(100)
+	cache := NewSearchCache(backend).AddIndex("idx:drd", time.Minute, "drd:")
+	rsc := NewRedisearchClientWithExecutor("test", NewScriptedExecutor()).EnableSearchCache(cache)
+
+	args := NewFtSearch("idx:drd").AddQuery("dream").Serialize()
+	reply := func(total int64) func() *redis.Cmd {
+		return func() *redis.Cmd {
+			cmd := redis.NewCmd(ctx, args...)
+			cmd.SetVal([]interface{}{total})
+			return cmd
+		}
+	}
+
+	// a write lands while the search is running: its old reply is not stored
+	rsc.cachedDo(ctx, args, func() *redis.Cmd {
+		rsc.invalidateKeys(ctx, "drd:1")
+		return reply(1)()
+	})
+	if backend.Len() != 0 {
+		t.Fatalf("cache holds %d entries, want the reply of the invalidated search dropped", backend.Len())
+	}
+
+	// the next miss is stored again
+	rsc.cachedDo(ctx, args, reply(2))
+	if got, _ := rsc.cachedDo(ctx, args, reply(3)).Slice(); !reflect.DeepEqual(got, []interface{}{int64(2)}) {
+		t.Errorf("cached reply = %v, want [2]", got)
+	}
+}
+
+func TestSearchCache_FailedInvalidation(t *testing.T) {
+	rsc := newTestClient(t)
+	backend := NewRedisCache(NewScriptedExecutor().AddError(errors.New("ERR cache down")), "cache:")
+	cache := NewSearchCache(backend).AddIndex("idx:drd", time.Minute, "drd:")
+	rsc.EnableSearchCache(cache)
+
+	// the write went through, so it is not reported as failed
+	if n, err := rsc.HSet("drd:1", "name", "Dream"); err != nil || n != 1 {
+		t.Errorf("HSet() = %d, %v, want the write result", n, err)
+	}
+	if got := cache.Stats(); got.Invalidations != 1 || got.Errors != 1 {
+		t.Errorf("Stats() = %+v, want 1 failed invalidation", got)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	mc := NewMemoryCache(3)
+
+	mc.Set(ctx, "idx:a", "1", []byte("one"), 0)
+	mc.Set(ctx, "idx:a", "2", []byte("two"), 0)
+	mc.Get(ctx, "idx:a", "1")
+	mc.Set(ctx, "idx:b", "3", []byte("three"), 0)
+	mc.Set(ctx, "idx:b", "4", []byte("four"), time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	tests := []struct {
+		name  string
+		index string
+		key   string
+		found bool
+	}{
+		{name: "Least Recently Used Evicted", index: "idx:a", key: "2", found: false},
+		{name: "Recently Used Kept", index: "idx:a", key: "1", found: true},
+		{name: "Newest Kept", index: "idx:b", key: "3", found: true},
+		{name: "Expired", index: "idx:b", key: "4", found: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, found, _ := mc.Get(ctx, tt.index, tt.key); found != tt.found {
+				t.Errorf("Get() found = %v, want %v", found, tt.found)
+			}
+		})
+	}
+
+	mc.Invalidate(ctx, "idx:b")
+	if mc.Len() != 1 {
+		t.Errorf("Len() after Invalidate = %d, want 1", mc.Len())
+	}
+}
+
+func Test_decodeReply(t *testing.T) {
+	reply := []interface{}{int64(2), "drd:1", "1.5", []interface{}{"name", "Dream"}, "drd:2", nil}
+
+	data := mustMarshalReply(t, reply)
+	got, err := decodeReply(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(got, reply) {
+		t.Errorf("decodeReply() = %#v, want %#v", got, reply)
+	}
+}
+
+func TestRedisCache_Get(t *testing.T) {
+	ctx := context.Background()
+	future := time.Now().Add(time.Minute).UnixNano()
+
+	scripted := NewScriptedExecutor().
+		AddReply(fmtInt(future) + `:[1,"drd:1"]`).
+		AddReply(`1:[1,"drd:1"]`).
+		AddReply(int64(1))
+	rc := NewRedisCache(scripted, "rscache:")
+
+	if data, found, err := rc.Get(ctx, "idx:drd", "k"); err != nil || !found || string(data) != `[1,"drd:1"]` {
+		t.Errorf("Get() = %s, %v, %v", data, found, err)
+	}
+
+	if _, found, _ := rc.Get(ctx, "idx:drd", "k"); found {
+		t.Error("Get() of an expired entry should miss")
+	}
+}
+
+func mustMarshalReply(t *testing.T, reply interface{}) []byte {
+	t.Helper()
+
+	data, err := json.Marshal(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func fmtInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
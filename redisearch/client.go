@@ -5,6 +5,10 @@ package redisearch
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/go-redis/redis/v8"
 )
@@ -16,9 +20,13 @@ import (
 // 2. if the number of Addrs is two or more, a ClusterClient is returned.
 // 3. Otherwise, a single-node Client is returned.
 type RedisearchClient struct {
-	Name    string
-	UClient redis.UniversalClient
-	Ctx     context.Context
+	Name     string
+	UClient  redis.UniversalClient // nil when the client is built with NewRedisearchClientWithExecutor
+	Executor Executor
+	Ctx      context.Context
+	hooks    []Hook
+	cache    *SearchCache
+	flight   *coalescer
 }
 
 func NewRedisearchClient(serviceName string, redisAddrs []string, redisPoolSizes, redisMinIdleConns, redisMaxRetries []int) *RedisearchClient {
@@ -32,18 +40,75 @@ func NewRedisearchClient(serviceName string, redisAddrs []string, redisPoolSizes
 	})
 
 	return &RedisearchClient{
-		Name:    serviceName,
-		UClient: uClient,
-		Ctx:     context.Background(),
+		Name:     serviceName,
+		UClient:  uClient,
+		Executor: NewGoRedisExecutor(uClient),
+		Ctx:      context.Background(),
 	}
 }
 
+// NewRedisearchClientWithExecutor returns a client that sends every command through executor
+func NewRedisearchClientWithExecutor(serviceName string, executor Executor) *RedisearchClient {
+	return &RedisearchClient{
+		Name:     serviceName,
+		Executor: executor,
+		Ctx:      context.Background(),
+	}
+}
+
+// do is the single path of every command to the executor, cached searches stop here
+func (rsc *RedisearchClient) do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	if rsc.cache == nil && rsc.flight == nil {
+		return rsc.exec(ctx, args)
+	}
+
+	next := func() *redis.Cmd {
+		return rsc.exec(ctx, args)
+	}
+
+	if rsc.flight != nil {
+		next = func() *redis.Cmd {
+			return rsc.flight.do(ctx, args, func(ctx context.Context) *redis.Cmd {
+				return rsc.exec(ctx, args)
+			})
+		}
+	}
+
+	if rsc.cache != nil {
+		return rsc.cachedDo(ctx, args, next)
+	}
+
+	return next()
+}
+
+// exec runs the hooks around FT.* commands
+func (rsc *RedisearchClient) exec(ctx context.Context, args []interface{}) *redis.Cmd {
+	if len(rsc.hooks) > 0 && isSearchCommand(args) {
+		return rsc.doHooked(ctx, args)
+	}
+
+	return rsc.Executor.Do(ctx, args...)
+}
+
+// pipeline sends the commands in one round trip through the executor
+func (rsc *RedisearchClient) pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	if len(rsc.hooks) > 0 {
+		return rsc.pipelineHooked(ctx, cmds)
+	}
+
+	return rsc.Executor.Pipeline(ctx, cmds)
+}
+
 func (rsc *RedisearchClient) CloseUniversalClient() error {
+	if rsc.UClient == nil {
+		return nil
+	}
+
 	return rsc.UClient.Close()
 }
 
 func (rsc *RedisearchClient) HealthCheckedUniversalClient() error {
-	if _, err := rsc.UClient.Ping(rsc.Ctx).Result(); err != nil {
+	if err := rsc.do(rsc.Ctx, "PING").Err(); err != nil {
 		return errors.New("redisearch cluster server not response")
 	}
 
@@ -67,7 +132,7 @@ FT.CREATE {index}
         [SORTABLE [UNF]] [NOINDEX]] ...
 */
 func (rsc *RedisearchClient) Create(indexName string, args ...interface{}) (string, error) {
-	return rsc.UClient.Do(rsc.Ctx, args...).Text()
+	return rsc.do(rsc.Ctx, args...).Text()
 }
 
 /*
@@ -77,25 +142,58 @@ EVICTED, CHANGE, LOADED, JSON.SET, JSON.DEL, JSON.NUMINCRBY, JSON.ARRAPPEND, JSO
 Beginning with RediSearch v2.0, you use native Redis commands to add, update or delete hashes. These include HSET , HINCRBY , HDEL .
 */
 func (rsc *RedisearchClient) HSet(key string, values ...interface{}) (int64, error) {
-	return rsc.UClient.HSet(rsc.Ctx, key, values...).Result()
+	n, err := rsc.do(rsc.Ctx, appendArgs([]interface{}{"HSET", key}, values)...).Int64()
+	if err != nil {
+		return n, err
+	}
+
+	rsc.invalidateKeys(rsc.Ctx, key)
+
+	return n, nil
 }
 func (rsc *RedisearchClient) HMSet(key string, values ...interface{}) (bool, error) {
-	return rsc.UClient.HMSet(rsc.Ctx, key, values...).Result()
+	res, err := rsc.do(rsc.Ctx, appendArgs([]interface{}{"HMSET", key}, values)...).Text()
+	if err != nil {
+		return false, err
+	}
+
+	rsc.invalidateKeys(rsc.Ctx, key)
+
+	return res == "OK", nil
 }
 func (rsc *RedisearchClient) HDel(key string, fields ...string) (int64, error) {
-	return rsc.UClient.HDel(rsc.Ctx, key, fields...).Result()
+	n, err := rsc.do(rsc.Ctx, appendArgs([]interface{}{"HDEL", key}, []interface{}{fields})...).Int64()
+	if err != nil {
+		return n, err
+	}
+
+	rsc.invalidateKeys(rsc.Ctx, key)
+
+	return n, nil
 }
 func (rsc *RedisearchClient) HGet(key string, field string) (string, error) {
-	return rsc.UClient.HGet(rsc.Ctx, key, field).Result()
+	return rsc.do(rsc.Ctx, "HGET", key, field).Text()
 }
 func (rsc *RedisearchClient) HMGet(key string, fields ...string) ([]interface{}, error) {
-	return rsc.UClient.HMGet(rsc.Ctx, key, fields...).Result()
+	return rsc.do(rsc.Ctx, appendArgs([]interface{}{"HMGET", key}, []interface{}{fields})...).Slice()
 }
 func (rsc *RedisearchClient) HGetAll(key string) (map[string]string, error) {
-	return rsc.UClient.HGetAll(rsc.Ctx, key).Result()
+	reply, err := rsc.do(rsc.Ctx, "HGETALL", key).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return replyStringMap(reply), nil
 }
 func (rsc *RedisearchClient) Del(keys ...string) (int64, error) {
-	return rsc.UClient.Del(rsc.Ctx, keys...).Result()
+	n, err := rsc.do(rsc.Ctx, appendArgs([]interface{}{"DEL"}, []interface{}{keys})...).Int64()
+	if err != nil {
+		return n, err
+	}
+
+	rsc.invalidateKeys(rsc.Ctx, keys...)
+
+	return n, nil
 }
 
 /*
@@ -116,7 +214,22 @@ FT.SEARCH {index} {query} [NOCONTENT] [VERBATIM] [NOSTOPWORDS] [WITHSCORES] [WIT
   [LIMIT offset num]
 */
 func (rsc *RedisearchClient) Search(indexName string, query ...interface{}) (interface{}, error) {
-	return rsc.UClient.Do(rsc.Ctx, query...).Result()
+	return rsc.do(rsc.Ctx, query...).Result()
+}
+
+// DoSearch runs the search builder and returns typed results.
+// A search with an attached schema is validated first, see FtSearch.Validate.
+func (rsc *RedisearchClient) DoSearch(ctx context.Context, fts *FtSearch) (*SearchResult, error) {
+	if err := fts.Validate(); err != nil {
+		return nil, err
+	}
+
+	reply, err := rsc.do(ctx, fts.Serialize()...).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSearchResult(reply, fts)
 }
 
 /*
@@ -133,22 +246,39 @@ FT.AGGREGATE {index_name}
   [LIMIT {offset} {num}] ...
   [FILTER {expr}] ...
 */
-func (rsc *RedisearchClient) Aggregate(indexName string) error {
-	return errors.New("not ready to use")
+func (rsc *RedisearchClient) Aggregate(ctx context.Context, fta *FtAggregate) (*AggregateResult, error) {
+	reply, err := rsc.do(ctx, fta.Serialize()...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAggregateResult(reply, fta.withcursor)
 }
 
 /*
-FT.EXPLAIN {index} {query}
+FT.CURSOR READ {index} {cursor_id} [COUNT {read size}]
+Reads the next results of a WITHCURSOR aggregate. CursorID of the result is 0 when the cursor is exhausted.
 */
-func (rsc *RedisearchClient) Explain(indexName string, query string) error {
-	return errors.New("not ready to use")
+func (rsc *RedisearchClient) CursorRead(ctx context.Context, indexName string, cursorID int64, count int) (*AggregateResult, error) {
+	args := []interface{}{"FT.CURSOR", "READ", indexName, cursorID}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+
+	reply, err := rsc.do(ctx, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseAggregateResult(reply, true)
 }
 
 /*
-FT.PROFILE {index} {[SEARCH, AGGREGATE]} [LIMITED] QUERY {query}
+FT.CURSOR DEL {index} {cursor_id}
+Deletes a cursor before it is exhausted or idle.
 */
-func (rsc *RedisearchClient) Profile(indexName string) error {
-	return errors.New("not ready to use")
+func (rsc *RedisearchClient) CursorDel(ctx context.Context, indexName string, cursorID int64) error {
+	return rsc.do(ctx, "FT.CURSOR", "DEL", indexName, cursorID).Err()
 }
 
 /*
@@ -157,7 +287,15 @@ Adds a new attribute to the index.
 */
 func (rsc *RedisearchClient) Alter(indexName string, values ...interface{}) (string, error) {
 	values = append([]interface{}{"FT.ALTER", indexName, "SCHEMA", "ADD"}, values...)
-	return rsc.UClient.Do(rsc.Ctx, values...).Text()
+
+	res, err := rsc.do(rsc.Ctx, values...).Text()
+	if err != nil {
+		return res, err
+	}
+
+	rsc.invalidateIndex(rsc.Ctx, indexName)
+
+	return res, nil
 }
 
 /*
@@ -166,11 +304,19 @@ Deletes the index.
 By default, FT.DROPINDEX does not delete the document hashes associated with the index. Adding the DD option deletes the hashes as well.
 */
 func (rsc *RedisearchClient) DropIndex(indexName string, deleteHash bool) (string, error) {
+	args := []interface{}{"FT.DROPINDEX", indexName}
 	if deleteHash {
-		return rsc.UClient.Do(rsc.Ctx, "FT.DROPINDEX", indexName, "DD").Text()
+		args = append(args, "DD")
+	}
+
+	res, err := rsc.do(rsc.Ctx, args...).Text()
+	if err != nil {
+		return res, err
 	}
 
-	return rsc.UClient.Do(rsc.Ctx, "FT.DROPINDEX", indexName).Text()
+	rsc.invalidateIndex(rsc.Ctx, indexName)
+
+	return res, nil
 }
 
 /*
@@ -181,20 +327,97 @@ FT.ALIASDEL {name}
 Indexes can have more than one alias, though an alias cannot refer to another alias.
 */
 func (rsc *RedisearchClient) AliasAdd(name string, indexName string) (string, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.ALIASADD", name, indexName).Text()
+	return rsc.do(rsc.Ctx, "FT.ALIASADD", name, indexName).Text()
 }
 func (rsc *RedisearchClient) AliasUpdate(name string, indexName string) (string, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.ALIASUPDATE", name, indexName).Text()
+	return rsc.do(rsc.Ctx, "FT.ALIASUPDATE", name, indexName).Text()
 }
 func (rsc *RedisearchClient) AliasDel(name string) (string, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.ALIASDEL", name).Text()
+	return rsc.do(rsc.Ctx, "FT.ALIASDEL", name).Text()
 }
 
 /*
 FT.TAGVALS {index} {attribute_name}
+Returns the distinct tags indexed in a TAG attribute. Values are lowercased unless the attribute is CASESENSITIVE.
 */
-func (rsc *RedisearchClient) TagVals(indexName string, attr string) error {
-	return errors.New("not ready to use")
+func (rsc *RedisearchClient) TagVals(ctx context.Context, indexName string, attr string) ([]string, error) {
+	return rsc.do(ctx, "FT.TAGVALS", indexName, attr).StringSlice()
+}
+
+// Tag value with the number of matching documents
+type TagCount struct {
+	Value string
+	Count int64
+}
+
+// TagCloud counts the documents matching query per value of the TAG attribute attr with one
+// FT.AGGREGATE {index} {query} LOAD 1 @attr APPLY split(@attr, "{separator}") AS v GROUPBY 1 @v REDUCE COUNT 0 AS n.
+// Values are lowercased and merged unless attr is CASESENSITIVE, like the index does.
+// query "" matches all documents. max 0 returns every tag.
+// Results are sorted by count, then by value.
+func (rsc *RedisearchClient) TagCloud(ctx context.Context, indexName string, attr IndexAttribute, query string, max int) ([]TagCount, error) {
+	res, err := rsc.Aggregate(ctx, tagCloudAggregate(indexName, attr, query))
+	if err != nil {
+		return nil, err
+	}
+
+	return tagCounts(res.Rows, attr.CaseSensitive, max), nil
+}
+
+// tagCloudAggregate splits the raw attribute value, so it needs the separator of the attribute
+func tagCloudAggregate(indexName string, attr IndexAttribute, query string) *FtAggregate {
+	if query == "" {
+		query = "*"
+	}
+
+	separator := attr.Separator
+	if separator == "" {
+		separator = ","
+	}
+
+	fta := NewFtAggregate(indexName).AddQuery(query).AddLoad(attr.Attribute)
+	fta.AddApply(fmt.Sprintf("split(@%s, %q)", attr.Attribute, separator), "v").
+		AddGroupBy([]string{"@v"}, NewReducer(ReducerCount, "n"))
+
+	return fta
+}
+
+// tagCounts merges the groups of tagCloudAggregate that only differ in case, unless caseSensitive,
+// drops empty values and sorts by count desc, value asc
+func tagCounts(rows []map[string]string, caseSensitive bool, max int) []TagCount {
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		val := strings.TrimSpace(row["v"])
+		if !caseSensitive {
+			val = strings.ToLower(val)
+		}
+
+		n, err := strconv.ParseInt(row["n"], 10, 64)
+		if val == "" || err != nil {
+			continue
+		}
+		counts[val] += n
+	}
+
+	tags := make([]TagCount, 0, len(counts))
+	for val, n := range counts {
+		if n > 0 {
+			tags = append(tags, TagCount{Value: val, Count: n})
+		}
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Value < tags[j].Value
+	})
+
+	if max > 0 && len(tags) > max {
+		tags = tags[:max]
+	}
+
+	return tags
 }
 
 /*
@@ -202,7 +425,7 @@ FT.SUGADD {key} {string} {score} [INCR] [PAYLOAD {payload}]
 Adds a suggestion string to an auto-complete suggestion dictionary. This is disconnected from the index definitions, and leaves creating and updating suggestions dictionaries to the user.
 */
 func (rsc *RedisearchClient) SugAdd(key string, val string) (int64, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.SUGADD", key, val, 1, "INCR").Int64()
+	return rsc.do(rsc.Ctx, "FT.SUGADD", key, val, 1, "INCR").Int64()
 }
 
 /*
@@ -210,7 +433,7 @@ FT.SUGGET {key} {prefix} [FUZZY] [WITHSCORES] [WITHPAYLOADS] [MAX num]
 Gets completion suggestions for a prefix.
 */
 func (rsc *RedisearchClient) SugGet(key string, prefix string, max int) (interface{}, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.SUGGET", key, prefix, "FUZZY", "MAX", max).Result()
+	return rsc.do(rsc.Ctx, "FT.SUGGET", key, prefix, "FUZZY", "MAX", max).Result()
 }
 
 /*
@@ -218,7 +441,7 @@ FT.SUGDEL {key} {string}
 Deletes a string from a suggestion index.
 */
 func (rsc *RedisearchClient) SugDel(key string, val string) (int64, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.SUGLEN", key).Int64()
+	return rsc.do(rsc.Ctx, "FT.SUGLEN", key).Int64()
 }
 
 /*
@@ -226,21 +449,51 @@ FT.SUGLEN {key}
 Gets the size of an auto-complete suggestion dictionary
 */
 func (rsc *RedisearchClient) SugLen(key string) (int64, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.SUGLEN", key).Int64()
+	return rsc.do(rsc.Ctx, "FT.SUGLEN", key).Int64()
 }
 
 /*
 FT.SYNUPDATE <index name> <synonym group id> [SKIPINITIALSCAN] <term1> <term2> ...
+Updates a synonym group, terms are added to the group. SKIPINITIALSCAN only applies the group to documents indexed afterwards.
 */
-func (rsc *RedisearchClient) SynUpdate(indexName string) error {
-	return errors.New("not ready to use")
+func (rsc *RedisearchClient) SynUpdate(ctx context.Context, indexName string, groupID string, skipInitialScan bool, terms ...string) error {
+	if len(terms) == 0 {
+		return errors.New("redisearch: synonym group needs at least one term")
+	}
+
+	args := []interface{}{"FT.SYNUPDATE", indexName, groupID}
+	if skipInitialScan {
+		args = append(args, "SKIPINITIALSCAN")
+	}
+	for _, t := range terms {
+		args = append(args, t)
+	}
+
+	if err := rsc.do(ctx, args...).Err(); err != nil {
+		return err
+	}
+
+	rsc.invalidateIndex(ctx, indexName)
+
+	return nil
 }
 
 /*
 FT.SYNDUMP <index name>
+Returns the synonym groups of every term: term => group ids
 */
-func (rsc *RedisearchClient) SynDump(indexName string) error {
-	return errors.New("not ready to use")
+func (rsc *RedisearchClient) SynDump(ctx context.Context, indexName string) (map[string][]string, error) {
+	reply, err := rsc.do(ctx, "FT.SYNDUMP", indexName).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]string, len(reply)/2)
+	for i := 0; i+1 < len(reply); i += 2 {
+		groups[replyString(reply[i])] = replyStringSlice(reply[i+1])
+	}
+
+	return groups, nil
 }
 
 /*
@@ -256,34 +509,105 @@ func (rsc *RedisearchClient) SpellCheck(indexName string, query string) error {
 FT.DICTADD {dict} {term} [{term} ...]
 Adds terms to a dictionary.
 */
-func (rsc *RedisearchClient) DictAdd(dict string, terms []interface{}) (int64, error) {
-	terms = append([]interface{}{"FT.DICTADD", dict}, terms...)
-	return rsc.UClient.Do(rsc.Ctx, terms...).Int64()
+func (rsc *RedisearchClient) DictAdd(ctx context.Context, dict string, terms ...string) (int64, error) {
+	args := []interface{}{"FT.DICTADD", dict}
+	for _, t := range terms {
+		args = append(args, t)
+	}
+
+	return rsc.do(ctx, args...).Int64()
 }
 
 /*
 FT.DICTDEL {dict} {term} [{term} ...]
 Deletes terms from a dictionary.
 */
-func (rsc *RedisearchClient) DictDel(dict string, terms []interface{}) (int64, error) {
-	terms = append([]interface{}{"FT.DICTDEL", dict}, terms...)
-	return rsc.UClient.Do(rsc.Ctx, terms...).Int64()
+func (rsc *RedisearchClient) DictDel(ctx context.Context, dict string, terms ...string) (int64, error) {
+	args := []interface{}{"FT.DICTDEL", dict}
+	for _, t := range terms {
+		args = append(args, t)
+	}
+
+	return rsc.do(ctx, args...).Int64()
 }
 
 /*
 FT.DICTDUMP {dict}
 Dumps all terms in the given dictionary.
 */
-func (rsc *RedisearchClient) DictDump(dict string) error {
-	return errors.New("not ready to use")
+func (rsc *RedisearchClient) DictDump(ctx context.Context, dict string) ([]string, error) {
+	return rsc.do(ctx, "FT.DICTDUMP", dict).StringSlice()
+}
+
+// SyncDict makes the dictionary contain exactly the desired terms.
+// Only the difference between the live dictionary and desired is sent, so it is
+// safe to run on every deploy. Returns the number of added and deleted terms.
+func (rsc *RedisearchClient) SyncDict(ctx context.Context, dict string, desired []string) (int64, int64, error) {
+	live, err := rsc.DictDump(ctx, dict)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	add, del := diffTerms(live, desired)
+
+	var added, deleted int64
+	if len(add) > 0 {
+		if added, err = rsc.DictAdd(ctx, dict, add...); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if len(del) > 0 {
+		if deleted, err = rsc.DictDel(ctx, dict, del...); err != nil {
+			return added, 0, err
+		}
+	}
+
+	return added, deleted, nil
+}
+
+// diffTerms returns the terms missing from live and the terms not in desired.
+// Both results keep the order of their source slice and contain no duplicates.
+func diffTerms(live, desired []string) ([]string, []string) {
+	liveSet := make(map[string]bool, len(live))
+	for _, t := range live {
+		liveSet[t] = true
+	}
+
+	desiredSet := make(map[string]bool, len(desired))
+	var add []string
+	for _, t := range desired {
+		if t == "" || desiredSet[t] {
+			continue
+		}
+		desiredSet[t] = true
+
+		if !liveSet[t] {
+			add = append(add, t)
+		}
+	}
+
+	var del []string
+	for _, t := range live {
+		if !desiredSet[t] {
+			del = append(del, t)
+		}
+	}
+
+	return add, del
 }
 
 /*
 FT.INFO {index}
 Returns information and statistics on the index.
 */
-func (rsc *RedisearchClient) Info(indexName string) (string, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT.INFO", indexName).Text()
+func (rsc *RedisearchClient) Info(ctx context.Context, indexName string) (*IndexInfo, error) {
+	reply, err := rsc.do(ctx, "FT.INFO", indexName).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseIndexInfo(reply)
 }
 
 /*
@@ -291,19 +615,5 @@ FT._LIST
 Returns a list of all existing indexes.
 */
 func (rsc *RedisearchClient) List() (interface{}, error) {
-	return rsc.UClient.Do(rsc.Ctx, "FT._LIST").Result()
-}
-
-/*
-FT.CONFIG <GET|HELP> {option}
-FT.CONFIG SET {option} {value}
-*/
-func (rsc *RedisearchClient) ConfigHelp(option string) error {
-	return errors.New("not ready to use")
-}
-func (rsc *RedisearchClient) ConfigGet(option string) error {
-	return errors.New("not ready to use")
-}
-func (rsc *RedisearchClient) ConfigSet(option string, val string) error {
-	return errors.New("not ready to use")
+	return rsc.do(rsc.Ctx, "FT._LIST").Result()
 }
@@ -0,0 +1,322 @@
+package redisearch
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/uretgec/go-redisearch/redisearch/redisearchtest"
+)
+
+// newTestClient returns a client connected to an in-memory fake server
+func newTestClient(t *testing.T) *RedisearchClient {
+	t.Helper()
+
+	srv, err := redisearchtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsc := NewRedisearchClient("test", []string{srv.Addr()}, []int{2}, []int{0}, []int{0})
+
+	t.Cleanup(func() {
+		rsc.CloseUniversalClient()
+		srv.Close()
+	})
+
+	return rsc
+}
+
+// seedTestIndex creates idx:drd with three dream documents
+func seedTestIndex(t *testing.T, rsc *RedisearchClient) {
+	t.Helper()
+
+	ftc := NewFtCreate("idx:drd").
+		AddDataType(HASH).
+		AddPrefix("drd:")
+	ftc.AddSchema(FieldTypeText, "name", "", true, ftc.AddSchemaTextOption(1, false, false, "")).
+		AddSchema(FieldTypeTag, "cats", "", false, ftc.AddSchemaTagOption(false, ",")).
+		AddSchema(FieldTypeNumeric, "updated", "", true, ftc.AddSchemaNumericOption(false))
+
+	if _, err := rsc.Create(ftc.indexname, ftc.Serialize()...); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := [][]interface{}{
+		{"name", "Dream Test 1", "cats", "dream,test", "updated", "100"},
+		{"name", "Dream Test 2", "cats", "dream", "updated", "200"},
+		{"name", "Hello World", "cats", "world", "updated", "300"},
+	}
+	for i, doc := range docs {
+		if _, err := rsc.HSet("drd:"+strconv.Itoa(i+1), doc...); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_diffTerms(t *testing.T) {
+	type args struct {
+		live    []string
+		desired []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantAdd []string
+		wantDel []string
+	}{
+		{
+			name: "Empty Dictionary",
+			args: args{
+				live:    nil,
+				desired: []string{"dream", "test"},
+			},
+			wantAdd: []string{"dream", "test"},
+			wantDel: nil,
+		},
+		{
+			name: "Add And Delete Delta",
+			args: args{
+				live:    []string{"dream", "old", "test"},
+				desired: []string{"test", "new", "dream", "new", ""},
+			},
+			wantAdd: []string{"new"},
+			wantDel: []string{"old"},
+		},
+		{
+			name: "Already In Sync",
+			args: args{
+				live:    []string{"dream", "test"},
+				desired: []string{"test", "dream"},
+			},
+			wantAdd: nil,
+			wantDel: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAdd, gotDel := diffTerms(tt.args.live, tt.args.desired)
+			if !reflect.DeepEqual(gotAdd, tt.wantAdd) {
+				t.Errorf("diffTerms() add = %v, want %v", gotAdd, tt.wantAdd)
+			}
+			if !reflect.DeepEqual(gotDel, tt.wantDel) {
+				t.Errorf("diffTerms() del = %v, want %v", gotDel, tt.wantDel)
+			}
+		})
+	}
+}
+
+func Test_tagCounts(t *testing.T) {
+	rows := []map[string]string{
+		{"v": "dream", "n": "2"},
+		{"v": "test", "n": "5"},
+		{"v": "Dream", "n": "1"},
+		{"v": "default", "n": "3"},
+		{"n": "4"},
+	}
+
+	tests := []struct {
+		name          string
+		caseSensitive bool
+		max           int
+		want          []TagCount
+	}{
+		{
+			name: "All Tags",
+			max:  0,
+			want: []TagCount{{"test", 5}, {"default", 3}, {"dream", 3}},
+		},
+		{
+			name: "Top Two",
+			max:  2,
+			want: []TagCount{{"test", 5}, {"default", 3}},
+		},
+		{
+			name:          "Case Sensitive",
+			caseSensitive: true,
+			max:           0,
+			want:          []TagCount{{"test", 5}, {"default", 3}, {"dream", 2}, {"Dream", 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tagCounts(rows, tt.caseSensitive, tt.max); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tagCounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisearchClient_TagCloud(t *testing.T) {
+	ctx := context.Background()
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	// tags differing in case are one value of a tag that is not CASESENSITIVE
+	if _, err := rsc.HSet("drd:4", "name", "Night", "cats", "DREAM, Sleep", "updated", "400"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := NewRecordingExecutor(rsc.Executor)
+	rsc.Executor = rec
+
+	tests := []struct {
+		name  string
+		query string
+		max   int
+		want  []TagCount
+	}{
+		{
+			name:  "All Documents",
+			query: "",
+			max:   0,
+			want:  []TagCount{{"dream", 3}, {"sleep", 1}, {"test", 1}, {"world", 1}},
+		},
+		{
+			name:  "Matching Documents",
+			query: "@name:(dream)",
+			max:   0,
+			want:  []TagCount{{"dream", 2}, {"test", 1}},
+		},
+		{
+			name:  "Top One",
+			query: "*",
+			max:   1,
+			want:  []TagCount{{"dream", 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec.Reset()
+
+			got, err := rsc.TagCloud(ctx, "idx:drd", IndexAttribute{Attribute: "cats", Type: FieldTypeTag, Separator: ","}, tt.query, tt.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TagCloud() = %v, want %v", got, tt.want)
+			}
+
+			// one aggregation for all values, not a search per value
+			if cmds := rec.Commands(); len(cmds) != 1 || cmds[0][0] != "FT.AGGREGATE" {
+				t.Errorf("TagCloud() sent %v, want a single FT.AGGREGATE", cmds)
+			}
+		})
+	}
+}
+
+func TestRedisearchClient_DoSearch(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	tests := []struct {
+		name      string
+		fts       *FtSearch
+		wantTotal int64
+		wantIDs   []string
+	}{
+		{
+			name:      "Term",
+			fts:       NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true),
+			wantTotal: 2,
+			wantIDs:   []string{"drd:1", "drd:2"},
+		},
+		{
+			name:      "Tag And Sort",
+			fts:       NewFtSearch("idx:drd").AddQuery("@cats:{dream}").AddSortBy("updated", false),
+			wantTotal: 2,
+			wantIDs:   []string{"drd:2", "drd:1"},
+		},
+		{
+			name:      "Filter And Limit",
+			fts:       NewFtSearch("idx:drd").AddQuery("*").AddFilter("updated", 150, 300, false, false).AddSortBy("updated", true).AddLimit(0, 1),
+			wantTotal: 2,
+			wantIDs:   []string{"drd:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rsc.DoSearch(context.Background(), tt.fts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []string
+			for _, doc := range got.Docs {
+				ids = append(ids, doc.ID)
+			}
+
+			if got.Total != tt.wantTotal || !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("DoSearch() = %d %v, want %d %v", got.Total, ids, tt.wantTotal, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestRedisearchClient_SyncDict(t *testing.T) {
+	rsc := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := rsc.DictAdd(ctx, "dict", "dream", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	added, deleted, err := rsc.SyncDict(ctx, "dict", []string{"dream", "world"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 || deleted != 1 {
+		t.Errorf("SyncDict() = %d, %d, want 1, 1", added, deleted)
+	}
+
+	got, err := rsc.DictDump(ctx, "dict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dream", "world"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DictDump() = %v, want %v", got, want)
+	}
+}
+
+func TestRedisearchClient_Synonyms(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+	ctx := context.Background()
+
+	recorder := NewRecordingExecutor(rsc.Executor)
+	rsc.Executor = recorder
+
+	if err := rsc.SynUpdate(ctx, "idx:drd", "g1", false, "dream", "sleep"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rsc.SynUpdate(ctx, "idx:drd", "g2", true, "dream", "vision"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rsc.SynUpdate(ctx, "idx:drd", "g3", false); err == nil {
+		t.Error("SynUpdate() without terms error = nil, want an error")
+	}
+
+	sent := recorder.Commands()
+	if len(sent) != 2 || !reflect.DeepEqual(sent[1], []interface{}{"FT.SYNUPDATE", "idx:drd", "g2", "SKIPINITIALSCAN", "dream", "vision"}) {
+		t.Errorf("commands = %v, want two FT.SYNUPDATE, the second with SKIPINITIALSCAN", sent)
+	}
+
+	got, err := rsc.SynDump(ctx, "idx:drd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"dream":  {"g1", "g2"},
+		"sleep":  {"g1"},
+		"vision": {"g2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SynDump() = %v, want %v", got, want)
+	}
+
+	if _, err := rsc.SynDump(ctx, "idx:missing"); err == nil {
+		t.Error("SynDump() of a missing index error = nil, want an error")
+	}
+}
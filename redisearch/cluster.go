@@ -0,0 +1,172 @@
+package redisearch
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ClusterSearchClient works with Redis OSS Cluster without the RSCoordinator module.
+// Every master only indexes the keys of its own slots, so index commands run on every master
+// and searches are scattered to all of them. Key commands (HSET, DEL, ...) are routed by slot.
+// Commands that would only reach one master (TagVals, Explain, cursors, ...) are not offered.
+type ClusterSearchClient struct {
+	Name string
+	Ctx  context.Context
+
+	rsc     *RedisearchClient
+	cluster *redis.ClusterClient
+}
+
+func NewClusterSearchClient(serviceName string, cluster *redis.ClusterClient) *ClusterSearchClient {
+	return &ClusterSearchClient{
+		Name: serviceName,
+		Ctx:  context.Background(),
+		rsc: &RedisearchClient{
+			Name:     serviceName,
+			UClient:  cluster,
+			Executor: NewGoRedisExecutor(cluster),
+			Ctx:      context.Background(),
+		},
+		cluster: cluster,
+	}
+}
+
+// AddHook installs a hook on every master, it sees each FT.* command once per master.
+// Add hooks before the client is shared between goroutines.
+func (csc *ClusterSearchClient) AddHook(hook Hook) *ClusterSearchClient {
+	csc.rsc.AddHook(hook)
+
+	return csc
+}
+
+// shards returns a client per master, ordered by address so merged ties are stable
+func (csc *ClusterSearchClient) shards(ctx context.Context) ([]*RedisearchClient, error) {
+	var mu sync.Mutex
+	var masters []*redis.Client
+
+	err := csc.cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		mu.Lock()
+		masters = append(masters, master)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(masters, func(i, j int) bool {
+		return masters[i].Options().Addr < masters[j].Options().Addr
+	})
+
+	shards := make([]*RedisearchClient, len(masters))
+	for i, master := range masters {
+		shards[i] = csc.rsc.withExecutor(NewGoRedisExecutor(master))
+	}
+
+	return shards, nil
+}
+
+// doOnMasters sends an index command to every master
+func (csc *ClusterSearchClient) doOnMasters(ctx context.Context, args ...interface{}) (string, error) {
+	shards, err := csc.shards(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := doOnShards(ctx, shards, args...); err != nil {
+		return "", err
+	}
+
+	return "OK", nil
+}
+
+func (csc *ClusterSearchClient) CloseUniversalClient() error {
+	return csc.rsc.CloseUniversalClient()
+}
+
+// Create runs FT.CREATE on every master
+func (csc *ClusterSearchClient) Create(indexName string, args ...interface{}) (string, error) {
+	return csc.doOnMasters(csc.Ctx, args...)
+}
+
+// Alter runs FT.ALTER on every master
+func (csc *ClusterSearchClient) Alter(indexName string, values ...interface{}) (string, error) {
+	values = append([]interface{}{"FT.ALTER", indexName, "SCHEMA", "ADD"}, values...)
+
+	return csc.doOnMasters(csc.Ctx, values...)
+}
+
+// DropIndex runs FT.DROPINDEX on every master
+func (csc *ClusterSearchClient) DropIndex(indexName string, deleteHash bool) (string, error) {
+	args := []interface{}{"FT.DROPINDEX", indexName}
+	if deleteHash {
+		args = append(args, "DD")
+	}
+
+	return csc.doOnMasters(csc.Ctx, args...)
+}
+
+func (csc *ClusterSearchClient) AliasAdd(name string, indexName string) (string, error) {
+	return csc.doOnMasters(csc.Ctx, "FT.ALIASADD", name, indexName)
+}
+func (csc *ClusterSearchClient) AliasUpdate(name string, indexName string) (string, error) {
+	return csc.doOnMasters(csc.Ctx, "FT.ALIASUPDATE", name, indexName)
+}
+func (csc *ClusterSearchClient) AliasDel(name string) (string, error) {
+	return csc.doOnMasters(csc.Ctx, "FT.ALIASDEL", name)
+}
+
+func (csc *ClusterSearchClient) HSet(key string, values ...interface{}) (int64, error) {
+	return csc.rsc.HSet(key, values...)
+}
+
+func (csc *ClusterSearchClient) HMSet(key string, values ...interface{}) (bool, error) {
+	return csc.rsc.HMSet(key, values...)
+}
+
+func (csc *ClusterSearchClient) HDel(key string, fields ...string) (int64, error) {
+	return csc.rsc.HDel(key, fields...)
+}
+
+func (csc *ClusterSearchClient) HGetAll(key string) (map[string]string, error) {
+	return csc.rsc.HGetAll(key)
+}
+
+// Del deletes the keys one by one, keys of different slots can not share a DEL
+func (csc *ClusterSearchClient) Del(keys ...string) (int64, error) {
+	var deleted int64
+	for _, key := range keys {
+		n, err := csc.rsc.Del(key)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// DoSearch scatters the search to every master and merges the replies by score, or by
+// sort key when the search has SORTBY. LIMIT is applied to the merged documents.
+func (csc *ClusterSearchClient) DoSearch(ctx context.Context, fts *FtSearch) (*SearchResult, error) {
+	shards, err := csc.shards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return scatterSearch(ctx, shards, fts)
+}
+
+// Aggregate scatters the aggregation to every master and merges the rows.
+// Only COUNT, SUM, MIN and MAX reducers can be merged, see scatterAggregate.
+func (csc *ClusterSearchClient) Aggregate(ctx context.Context, fta *FtAggregate) (*AggregateResult, error) {
+	shards, err := csc.shards(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return scatterAggregate(ctx, shards, fta)
+}
@@ -0,0 +1,63 @@
+package redisearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/uretgec/go-redisearch/redisearch/redisearchtest"
+)
+
+// newTestCluster serves every slot from one fake server, so no CLUSTER command is needed
+func newTestCluster(t *testing.T) *ClusterSearchClient {
+	t.Helper()
+
+	srv, err := redisearchtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := redis.NewClusterClient(&redis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]redis.ClusterSlot, error) {
+			return []redis.ClusterSlot{{Start: 0, End: 16383, Nodes: []redis.ClusterNode{{Addr: srv.Addr()}}}}, nil
+		},
+	})
+
+	csc := NewClusterSearchClient("test", cluster)
+
+	t.Cleanup(func() {
+		csc.CloseUniversalClient()
+		srv.Close()
+	})
+
+	return csc
+}
+
+func TestClusterSearchClient_AddHook(t *testing.T) {
+	hook := &recordingHook{}
+	csc := newTestCluster(t).AddHook(hook)
+	ctx := context.Background()
+
+	ftc := NewFtCreate("idx:drd").AddDataType(HASH).AddPrefix("drd:")
+	ftc.AddSchema(FieldTypeText, "name", "", true, ftc.AddSchemaTextOption(1, false, false, ""))
+
+	if _, err := csc.Create("idx:drd", ftc.Serialize()...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := csc.HSet("drd:1", "name", "Dream Test 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := csc.DoSearch(ctx, NewFtSearch("idx:drd").AddQuery("dream")); err != nil {
+		t.Fatal(err)
+	}
+
+	// the commands scattered to the masters run the hook, HSET is not an FT.* command
+	want := []string{
+		"before FT.CREATE", "after FT.CREATE",
+		"before FT.SEARCH", "after FT.SEARCH",
+	}
+	if !reflect.DeepEqual(hook.calls, want) {
+		t.Errorf("hook calls = %v, want %v", hook.calls, want)
+	}
+}
@@ -0,0 +1,265 @@
+// Via: https://oss.redis.com/redisearch/Configuring/
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+FT.CONFIG <GET|HELP> {option}
+FT.CONFIG SET {option} {value}
+
+Use "*" as option to get all options.
+*/
+
+// Runtime configuration options
+const (
+	ConfigTimeout              string = "TIMEOUT"
+	ConfigOnTimeout            string = "ON_TIMEOUT"
+	ConfigMinPrefix            string = "MINPREFIX"
+	ConfigMaxExpansions        string = "MAXEXPANSIONS"
+	ConfigMaxPrefixExpansions  string = "MAXPREFIXEXPANSIONS"
+	ConfigMaxSearchResults     string = "MAXSEARCHRESULTS"
+	ConfigMaxAggregateResults  string = "MAXAGGREGATERESULTS"
+	ConfigDefaultDialect       string = "DEFAULT_DIALECT"
+	ConfigForkGCRunInterval    string = "FORK_GC_RUN_INTERVAL"
+	ConfigForkGCRetryInterval  string = "FORK_GC_RETRY_INTERVAL"
+	ConfigForkGCCleanThreshold string = "FORK_GC_CLEAN_THRESHOLD"
+	ConfigGCScanSize           string = "GC_SCANSIZE"
+	ConfigGCPolicy             string = "GC_POLICY" // load time only
+	ConfigCursorMaxIdle        string = "CURSOR_MAX_IDLE"
+	ConfigUnionIteratorHeap    string = "UNION_ITERATOR_HEAP"
+)
+
+// ON_TIMEOUT values
+const (
+	OnTimeoutReturn string = "RETURN"
+	OnTimeoutFail   string = "FAIL"
+)
+
+// Options FT.CONFIG SET accepts at runtime and whether their value must be an integer
+var configSettable = map[string]bool{
+	ConfigTimeout:              true,
+	ConfigOnTimeout:            false,
+	ConfigMinPrefix:            true,
+	ConfigMaxExpansions:        true,
+	ConfigMaxPrefixExpansions:  true,
+	ConfigMaxSearchResults:     true,
+	ConfigMaxAggregateResults:  true,
+	ConfigDefaultDialect:       true,
+	ConfigForkGCRunInterval:    true,
+	ConfigForkGCRetryInterval:  true,
+	ConfigForkGCCleanThreshold: true,
+	ConfigGCScanSize:           true,
+	ConfigCursorMaxIdle:        true,
+	ConfigUnionIteratorHeap:    true,
+}
+
+// Common settings that can be read and applied as a unit
+type Config struct {
+	Timeout              int64  // milliseconds, 0 disables the timeout
+	OnTimeout            string // RETURN or FAIL
+	MinPrefix            int64
+	MaxExpansions        int64
+	MaxSearchResults     int64 // -1 is unlimited
+	MaxAggregateResults  int64 // -1 is unlimited
+	DefaultDialect       int64
+	ForkGCRunInterval    int64 // seconds
+	ForkGCRetryInterval  int64 // seconds
+	ForkGCCleanThreshold int64
+	GCScanSize           int64
+	GCPolicy             string // read only, set when the module is loaded
+}
+
+/*
+FT.CONFIG GET {option}
+Returns option name and value pairs. Unset options have an empty value.
+*/
+func (rsc *RedisearchClient) ConfigGet(ctx context.Context, option string) (map[string]string, error) {
+	reply, err := rsc.do(ctx, "FT.CONFIG", "GET", option).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string, len(reply))
+	for _, item := range reply {
+		pair := replyStringSlice(item)
+		if len(pair) < 2 {
+			continue
+		}
+		res[pair[0]] = pair[1]
+	}
+
+	return res, nil
+}
+
+/*
+FT.CONFIG SET {option} {value}
+Only known runtime options are accepted.
+*/
+func (rsc *RedisearchClient) ConfigSet(ctx context.Context, option string, val string) error {
+	if err := validateConfig(option, val); err != nil {
+		return err
+	}
+
+	return rsc.do(ctx, "FT.CONFIG", "SET", strings.ToUpper(option), val).Err()
+}
+
+/*
+FT.CONFIG HELP {option}
+Returns option name and description pairs.
+*/
+func (rsc *RedisearchClient) ConfigHelp(ctx context.Context, option string) (map[string]string, error) {
+	reply, err := rsc.do(ctx, "FT.CONFIG", "HELP", option).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	// [name, "Description", description, "Value", value]
+	res := make(map[string]string, len(reply))
+	for _, item := range reply {
+		fields := replyStringSlice(item)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var desc string
+		for i := 1; i+1 < len(fields); i += 2 {
+			if fields[i] == "Description" {
+				desc = fields[i+1]
+			}
+		}
+		res[fields[0]] = desc
+	}
+
+	return res, nil
+}
+
+// GetConfig reads all options into a Config
+func (rsc *RedisearchClient) GetConfig(ctx context.Context) (*Config, error) {
+	options, err := rsc.ConfigGet(ctx, "*")
+	if err != nil {
+		return nil, err
+	}
+
+	return newConfig(options)
+}
+
+// ApplyConfig sets every non zero option of cfg. GCPolicy is ignored.
+// Use ConfigSet to set an option to zero.
+func (rsc *RedisearchClient) ApplyConfig(ctx context.Context, cfg *Config) error {
+	options := cfg.options()
+
+	// validate everything before changing anything
+	for _, o := range options {
+		if err := validateConfig(o[0], o[1]); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range options {
+		if err := rsc.ConfigSet(ctx, o[0], o[1]); err != nil {
+			return fmt.Errorf("redisearch: config %s: %w", o[0], err)
+		}
+	}
+
+	return nil
+}
+
+func newConfig(options map[string]string) (*Config, error) {
+	cfg := &Config{
+		OnTimeout: options[ConfigOnTimeout],
+		GCPolicy:  options[ConfigGCPolicy],
+	}
+
+	// MAXPREFIXEXPANSIONS replaced MAXEXPANSIONS in newer versions
+	maxExpansions := ConfigMaxExpansions
+	if _, ok := options[ConfigMaxPrefixExpansions]; ok {
+		maxExpansions = ConfigMaxPrefixExpansions
+	}
+
+	for option, dst := range map[string]*int64{
+		ConfigTimeout:              &cfg.Timeout,
+		ConfigMinPrefix:            &cfg.MinPrefix,
+		maxExpansions:              &cfg.MaxExpansions,
+		ConfigMaxSearchResults:     &cfg.MaxSearchResults,
+		ConfigMaxAggregateResults:  &cfg.MaxAggregateResults,
+		ConfigDefaultDialect:       &cfg.DefaultDialect,
+		ConfigForkGCRunInterval:    &cfg.ForkGCRunInterval,
+		ConfigForkGCRetryInterval:  &cfg.ForkGCRetryInterval,
+		ConfigForkGCCleanThreshold: &cfg.ForkGCCleanThreshold,
+		ConfigGCScanSize:           &cfg.GCScanSize,
+	} {
+		val, ok := options[option]
+		if !ok || val == "" {
+			continue
+		}
+
+		if val == "unlimited" {
+			*dst = -1
+			continue
+		}
+
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redisearch: config %s: %w", option, err)
+		}
+		*dst = n
+	}
+
+	return cfg, nil
+}
+
+// options returns the non zero settable options in a stable order
+func (cfg *Config) options() [][2]string {
+	var options [][2]string
+
+	add := func(option string, val int64) {
+		if val != 0 {
+			options = append(options, [2]string{option, strconv.FormatInt(val, 10)})
+		}
+	}
+
+	add(ConfigTimeout, cfg.Timeout)
+	if cfg.OnTimeout != "" {
+		options = append(options, [2]string{ConfigOnTimeout, cfg.OnTimeout})
+	}
+	add(ConfigMinPrefix, cfg.MinPrefix)
+	add(ConfigMaxExpansions, cfg.MaxExpansions)
+	add(ConfigMaxSearchResults, cfg.MaxSearchResults)
+	add(ConfigMaxAggregateResults, cfg.MaxAggregateResults)
+	add(ConfigDefaultDialect, cfg.DefaultDialect)
+	add(ConfigForkGCRunInterval, cfg.ForkGCRunInterval)
+	add(ConfigForkGCRetryInterval, cfg.ForkGCRetryInterval)
+	add(ConfigForkGCCleanThreshold, cfg.ForkGCCleanThreshold)
+	add(ConfigGCScanSize, cfg.GCScanSize)
+
+	return options
+}
+
+func validateConfig(option string, val string) error {
+	option = strings.ToUpper(option)
+
+	numeric, ok := configSettable[option]
+	if !ok {
+		return fmt.Errorf("redisearch: unknown or read only config option %q", option)
+	}
+
+	if option == ConfigOnTimeout {
+		if v := strings.ToUpper(val); v != OnTimeoutReturn && v != OnTimeoutFail {
+			return fmt.Errorf("redisearch: config %s must be %s or %s", option, OnTimeoutReturn, OnTimeoutFail)
+		}
+		return nil
+	}
+
+	if numeric {
+		if _, err := strconv.ParseInt(val, 10, 64); err != nil {
+			return fmt.Errorf("redisearch: config %s must be an integer", option)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_newConfig(t *testing.T) {
+	options := map[string]string{
+		"TIMEOUT":              "500",
+		"ON_TIMEOUT":           "return",
+		"MINPREFIX":            "2",
+		"MAXEXPANSIONS":        "100",
+		"MAXPREFIXEXPANSIONS":  "200",
+		"MAXSEARCHRESULTS":     "10000",
+		"MAXAGGREGATERESULTS":  "unlimited",
+		"DEFAULT_DIALECT":      "1",
+		"FORK_GC_RUN_INTERVAL": "30",
+		"GC_POLICY":            "fork",
+		"FRISOINI":             "",
+	}
+
+	want := &Config{
+		Timeout:             500,
+		OnTimeout:           "return",
+		MinPrefix:           2,
+		MaxExpansions:       200,
+		MaxSearchResults:    10000,
+		MaxAggregateResults: -1,
+		DefaultDialect:      1,
+		ForkGCRunInterval:   30,
+		GCPolicy:            "fork",
+	}
+
+	got, err := newConfig(options)
+	if err != nil {
+		t.Fatalf("newConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_validateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  string
+		val     string
+		wantErr bool
+	}{
+		{name: "Numeric Option", option: "TIMEOUT", val: "500"},
+		{name: "Lower Case Option", option: "maxsearchresults", val: "1000"},
+		{name: "On Timeout", option: "ON_TIMEOUT", val: "fail"},
+		{name: "Invalid On Timeout", option: "ON_TIMEOUT", val: "ignore", wantErr: true},
+		{name: "Not A Number", option: "MINPREFIX", val: "two", wantErr: true},
+		{name: "Load Time Option", option: "GC_POLICY", val: "legacy", wantErr: true},
+		{name: "Unknown Option", option: "TIMEOUTT", val: "500", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateConfig(tt.option, tt.val); (err != nil) != tt.wantErr {
+				t.Errorf("validateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
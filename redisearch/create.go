@@ -38,7 +38,6 @@ const (
 	FieldTypeGeo     string = "GEO"
 )
 
-// TODO: json entegrasyonu
 type FtSchema struct {
 	identifier string // field name
 	attribute  string // AS
@@ -275,7 +274,7 @@ func (ftc *FtCreate) Serialize() []interface{} {
 	}
 
 	if ftc.score > 0 {
-		queryCode = append(queryCode, "SCORE", ftc.language)
+		queryCode = append(queryCode, "SCORE", ftc.score)
 	}
 
 	if ftc.scorefield != "" {
@@ -382,6 +381,10 @@ func (ftc *FtCreate) Serialize() []interface{} {
 					queryCode = append(queryCode, "SEPARATOR", sc.option.separator)
 				}
 
+				if sc.option.casesensitive {
+					queryCode = append(queryCode, "CASESENSITIVE")
+				}
+
 				if sc.sortable {
 					queryCode = append(queryCode, "SORTABLE")
 
@@ -0,0 +1,43 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFtCreate_Serialize(t *testing.T) {
+	tags := NewFtCreate("idx:drd").AddDataType(HASH)
+	cats := tags.AddSchemaTagOption(false, ",")
+	cats.casesensitive = true
+	tags.AddSchema(FieldTypeTag, "cats", "", true, cats)
+
+	tests := []struct {
+		name string
+		ftc  *FtCreate
+		want []interface{}
+	}{
+		{
+			name: "Score",
+			ftc:  NewFtCreate("idx:drd").AddDataType(HASH).AddLanguage("english").AddScore(0.5).AddScoreField("rank"),
+			want: []interface{}{
+				"FT.CREATE", "idx:drd", "ON", HASH,
+				"LANGUAGE", "english", "SCORE", 0.5, "SCORE_FIELD", "rank",
+			},
+		},
+		{
+			name: "Case Sensitive Tag",
+			ftc:  tags,
+			want: []interface{}{
+				"FT.CREATE", "idx:drd", "ON", HASH,
+				"SCHEMA", "cats", "TAG", "SEPARATOR", ",", "CASESENSITIVE", "SORTABLE",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ftc.Serialize(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
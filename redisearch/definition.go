@@ -0,0 +1,298 @@
+package redisearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+Declarative form of an FtCreate as a JSON document:
+
+	{
+	  "name": "idx:drd",
+	  "on": "HASH",
+	  "prefixes": ["drd:"],
+	  "language": "english",
+	  "stopwords": ["a", "the"],
+	  "fields": [
+	    {"name": "name", "type": "TEXT", "sortable": true, "weight": 2},
+	    {"name": "cats", "type": "TAG", "separator": ","},
+	    {"name": "updated", "type": "NUMERIC", "sortable": true}
+	  ]
+	}
+
+Unknown keys are rejected, so typos fail loudly instead of creating a different index.
+*/
+type indexDocument struct {
+	Name            string          `json:"name"`
+	On              string          `json:"on,omitempty"`
+	Prefixes        []string        `json:"prefixes,omitempty"`
+	Filter          string          `json:"filter,omitempty"`
+	Language        string          `json:"language,omitempty"`
+	LanguageField   string          `json:"language_field,omitempty"`
+	Score           float64         `json:"score,omitempty"`
+	ScoreField      string          `json:"score_field,omitempty"`
+	PayloadField    string          `json:"payload_field,omitempty"`
+	MaxTextFields   bool            `json:"max_text_fields,omitempty"`
+	Temporary       int             `json:"temporary,omitempty"`
+	NoOffsets       bool            `json:"no_offsets,omitempty"`
+	NoFields        bool            `json:"no_fields,omitempty"`
+	NoFreqs         bool            `json:"no_freqs,omitempty"`
+	SkipInitialScan bool            `json:"skip_initial_scan,omitempty"`
+	StopWords       []string        `json:"stopwords,omitempty"`
+	Fields          []fieldDocument `json:"fields"`
+}
+
+type fieldDocument struct {
+	Name          string  `json:"name"`
+	As            string  `json:"as,omitempty"`
+	Type          string  `json:"type"`
+	Sortable      bool    `json:"sortable,omitempty"`
+	UNF           bool    `json:"unf,omitempty"`
+	NoStem        bool    `json:"nostem,omitempty"`
+	NoIndex       bool    `json:"noindex,omitempty"`
+	Weight        float32 `json:"weight,omitempty"`
+	Phonetic      string  `json:"phonetic,omitempty"`
+	Separator     string  `json:"separator,omitempty"`
+	CaseSensitive bool    `json:"casesensitive,omitempty"`
+}
+
+// IndexName returns the name of the index the builder creates
+func (ftc *FtCreate) IndexName() string {
+	return ftc.indexname
+}
+
+func (ftc *FtCreate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ftc.document())
+}
+
+func (ftc *FtCreate) UnmarshalJSON(data []byte) error {
+	var doc indexDocument
+	if err := decodeStrict(data, &doc); err != nil {
+		return err
+	}
+
+	built, err := doc.build()
+	if err != nil {
+		return err
+	}
+	*ftc = *built
+
+	return nil
+}
+
+func (s FtSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.document())
+}
+
+func (s *FtSchema) UnmarshalJSON(data []byte) error {
+	var doc fieldDocument
+	if err := decodeStrict(data, &doc); err != nil {
+		return err
+	}
+
+	built, err := doc.build()
+	if err != nil {
+		return err
+	}
+	*s = built
+
+	return nil
+}
+
+// LoadIndexDefinition reads a .json index document
+func LoadIndexDefinition(path string) (*FtCreate, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return nil, fmt.Errorf("redisearch: %s: index documents are .json files", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ftc := &FtCreate{}
+	if err := ftc.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("redisearch: %s: %v", path, err)
+	}
+
+	return ftc, nil
+}
+
+// LoadIndexDefinitions reads every .json document of dir in file name order.
+// Other files are skipped, two documents with the same index name are an error.
+func LoadIndexDefinitions(dir string) ([]*FtCreate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if strings.EqualFold(filepath.Ext(e.Name()), ".json") && !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	seen := make(map[string]string, len(names))
+	defs := make([]*FtCreate, 0, len(names))
+	for _, name := range names {
+		ftc, err := LoadIndexDefinition(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		if prev, ok := seen[ftc.indexname]; ok {
+			return nil, fmt.Errorf("redisearch: index %s is defined in both %s and %s", ftc.indexname, prev, name)
+		}
+		seen[ftc.indexname] = name
+
+		defs = append(defs, ftc)
+	}
+
+	return defs, nil
+}
+
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	return dec.Decode(v)
+}
+
+func (ftc *FtCreate) document() indexDocument {
+	doc := indexDocument{
+		Name:            ftc.indexname,
+		On:              ftc.datatype,
+		Prefixes:        ftc.prefix,
+		Filter:          ftc.filterexp,
+		Language:        ftc.language,
+		LanguageField:   ftc.languagefield,
+		Score:           ftc.score,
+		ScoreField:      ftc.scorefield,
+		PayloadField:    ftc.payloadfiled,
+		MaxTextFields:   ftc.maxtextfields,
+		NoOffsets:       ftc.nooffsets,
+		NoFields:        ftc.nofields,
+		NoFreqs:         ftc.nofreqs,
+		SkipInitialScan: ftc.skipinitialscan,
+		StopWords:       ftc.stopwords,
+		Fields:          make([]fieldDocument, 0, len(ftc.schema)),
+	}
+	if ftc.temporary {
+		doc.Temporary = ftc.temporaryseconds
+	}
+
+	for _, s := range ftc.schema {
+		doc.Fields = append(doc.Fields, s.document())
+	}
+
+	return doc
+}
+
+func (s FtSchema) document() fieldDocument {
+	return fieldDocument{
+		Name:          s.identifier,
+		As:            s.attribute,
+		Type:          s.fieldtype,
+		Sortable:      s.sortable,
+		UNF:           s.option.unf,
+		NoStem:        s.option.nostem,
+		NoIndex:       s.option.noindex,
+		Weight:        s.option.weight,
+		Phonetic:      s.option.phonetic,
+		Separator:     s.option.separator,
+		CaseSensitive: s.option.casesensitive,
+	}
+}
+
+func (doc *indexDocument) build() (*FtCreate, error) {
+	if doc.Name == "" {
+		return nil, errors.New("index document without name")
+	}
+	if len(doc.Fields) == 0 {
+		return nil, fmt.Errorf("index %s has no fields", doc.Name)
+	}
+
+	on := strings.ToUpper(doc.On)
+	switch on {
+	case "":
+		on = HASH
+	case HASH, "JSON":
+	default:
+		return nil, fmt.Errorf("index %s: unknown data type %q", doc.Name, doc.On)
+	}
+
+	ftc := NewFtCreate(doc.Name).
+		AddDataType(on).
+		AddPrefix(doc.Prefixes...).
+		AddFilterExp(doc.Filter).
+		AddLanguage(doc.Language).
+		AddLanguageField(doc.LanguageField).
+		AddScore(doc.Score).
+		AddScoreField(doc.ScoreField).
+		AddPayloadField(doc.PayloadField).
+		AddMaxTextFields(doc.MaxTextFields).
+		AddTemporarySeconds(doc.Temporary > 0, doc.Temporary).
+		AddNoOffsets(doc.NoOffsets).
+		AddNoFields(doc.NoFields).
+		AddNoFreqs(doc.NoFreqs).
+		AddSkipInitialScan(doc.SkipInitialScan).
+		AddStopWords(doc.StopWords...)
+
+	for _, f := range doc.Fields {
+		s, err := f.build()
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %v", doc.Name, err)
+		}
+		ftc.schema = append(ftc.schema, s)
+	}
+
+	return ftc, nil
+}
+
+func (f *fieldDocument) build() (FtSchema, error) {
+	if f.Name == "" {
+		return FtSchema{}, errors.New("field without name")
+	}
+
+	typ := strings.ToUpper(f.Type)
+	switch typ {
+	case FieldTypeText, FieldTypeTag, FieldTypeNumeric, FieldTypeGeo:
+	default:
+		return FtSchema{}, fmt.Errorf("field %s has unknown type %q", f.Name, f.Type)
+	}
+
+	if typ != FieldTypeText && (f.NoStem || f.Weight != 0 || f.Phonetic != "") {
+		return FtSchema{}, fmt.Errorf("field %s: nostem, weight and phonetic only apply to TEXT", f.Name)
+	}
+	if typ != FieldTypeTag && (f.Separator != "" || f.CaseSensitive) {
+		return FtSchema{}, fmt.Errorf("field %s: separator and casesensitive only apply to TAG", f.Name)
+	}
+	if f.UNF && !f.Sortable {
+		return FtSchema{}, fmt.Errorf("field %s: unf needs sortable", f.Name)
+	}
+
+	return FtSchema{
+		identifier: f.Name,
+		attribute:  f.As,
+		fieldtype:  typ,
+		sortable:   f.Sortable,
+		option: FtSchemaOption{
+			unf:           f.UNF,
+			nostem:        f.NoStem,
+			noindex:       f.NoIndex,
+			phonetic:      f.Phonetic,
+			weight:        f.Weight,
+			separator:     f.Separator,
+			casesensitive: f.CaseSensitive,
+		},
+	}, nil
+}
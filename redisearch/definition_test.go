@@ -0,0 +1,134 @@
+package redisearch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testIndexJSON = `{
+  "name": "idx:drd",
+  "on": "hash",
+  "prefixes": ["drd:", "dream:"],
+  "language": "english",
+  "stopwords": ["a", "the"],
+  "fields": [
+    {"name": "name", "type": "TEXT", "sortable": true, "weight": 2.5},
+    {"name": "description", "as": "desc", "type": "TEXT", "phonetic": "dm:en"},
+    {"name": "cats", "type": "TAG", "separator": ",", "casesensitive": true},
+    {"name": "updated", "type": "NUMERIC", "sortable": true, "unf": true}
+  ]
+}`
+
+func TestFtCreate_Definition(t *testing.T) {
+	ftc := NewFtCreate("idx:drd").
+		AddDataType(HASH).
+		AddPrefix("drd:", "dream:").
+		AddLanguage("english").
+		AddStopWords("a", "the")
+	ftc.AddSchema(FieldTypeText, "name", "", true, ftc.AddSchemaTextOption(2.5, false, false, "")).
+		AddSchema(FieldTypeText, "description", "desc", false, ftc.AddSchemaTextOption(0, false, false, PhoneticDoubleMetaphoneEnglish))
+	cats := ftc.AddSchemaTagOption(false, ",")
+	cats.casesensitive = true
+	updated := ftc.AddSchemaNumericOption(false)
+	updated.unf = true
+	ftc.AddSchema(FieldTypeTag, "cats", "", false, cats).
+		AddSchema(FieldTypeNumeric, "updated", "", true, updated)
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(ftc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := &FtCreate{}
+		if err := json.Unmarshal(data, got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Serialize(), ftc.Serialize()) {
+			t.Errorf("JSON round trip = %v, want %v", got.Serialize(), ftc.Serialize())
+		}
+	})
+
+	t.Run("Hand Written JSON", func(t *testing.T) {
+		got := &FtCreate{}
+		if err := json.Unmarshal([]byte(testIndexJSON), got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got.Serialize(), ftc.Serialize()) {
+			t.Errorf("json.Unmarshal() = %v, want %v", got.Serialize(), ftc.Serialize())
+		}
+	})
+}
+
+func TestFtCreate_UnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{"Unknown Key", `{"name": "idx", "prefix": ["a"], "fields": [{"name": "a", "type": "TEXT"}]}`, `unknown field "prefix"`},
+		{"Unknown Field Key", `{"name": "idx", "fields": [{"name": "a", "type": "TEXT", "sortabel": true}]}`, `unknown field "sortabel"`},
+		{"Unknown Type", `{"name": "idx", "fields": [{"name": "a", "type": "VECTOR"}]}`, `unknown type "VECTOR"`},
+		{"Option Of Other Type", `{"name": "idx", "fields": [{"name": "a", "type": "NUMERIC", "separator": ","}]}`, "only apply to TAG"},
+		{"No Fields", `{"name": "idx"}`, "has no fields"},
+		{"String Score", `{"name": "idx", "score": "high", "fields": [{"name": "a", "type": "TEXT"}]}`, "score"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&FtCreate{}).UnmarshalJSON([]byte(tt.doc))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("UnmarshalJSON() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadIndexDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b_dreams.json": testIndexJSON,
+		"a_terms.json":  `{"name": "idx:terms", "prefixes": ["term:"], "fields": [{"name": "uid", "type": "TAG"}]}`,
+		"c_terms.yaml":  "name: idx:terms",
+		"README.md":     "not an index",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	defs, err := LoadIndexDefinitions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, ftc := range defs {
+		names = append(names, ftc.IndexName())
+	}
+	if want := []string{"idx:terms", "idx:drd"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("LoadIndexDefinitions() = %q, want %q", names, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "c_dreams.json"), []byte(testIndexJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadIndexDefinitions(dir); err == nil || !strings.Contains(err.Error(), "defined in both b_dreams.json and c_dreams.json") {
+		t.Errorf("LoadIndexDefinitions() error = %v, want a duplicate index error", err)
+	}
+}
+
+func TestLoadIndexDefinition_Extension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dreams.yaml")
+	if err := os.WriteFile(path, []byte(testIndexJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadIndexDefinition(path); err == nil || !strings.Contains(err.Error(), "are .json files") {
+		t.Errorf("LoadIndexDefinition() error = %v, want an extension error", err)
+	}
+}
@@ -0,0 +1,514 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// What EnsureIndex does when the index exists with a different definition
+type EnsurePolicy int
+
+const (
+	EnsureNoop    EnsurePolicy = iota // keep the existing index as is
+	EnsureAlter                       // add missing attributes with FT.ALTER, fail on any other difference
+	EnsureFail                        // return an *IndexMismatchError
+	EnsureRebuild                     // build a versioned index, move the alias to it and drop the old one
+)
+
+func (p EnsurePolicy) check() error {
+	if p < EnsureNoop || p > EnsureRebuild {
+		return fmt.Errorf("redisearch: unknown EnsurePolicy %d", int(p))
+	}
+
+	return nil
+}
+
+// How often EnsureRebuild polls FT.INFO while the new index runs its initial scan
+var EnsureIndexingPollInterval = 100 * time.Millisecond
+
+// Differences between an index definition and the live index
+type IndexDiff struct {
+	Added   []IndexAttribute // in the definition, missing from the index
+	Removed []IndexAttribute // in the index, missing from the definition
+	Changed []string         // settings and attributes whose options differ
+}
+
+func (d *IndexDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d *IndexDiff) String() string {
+	var parts []string
+	for _, a := range d.Added {
+		parts = append(parts, "missing attribute @"+a.Attribute)
+	}
+	for _, a := range d.Removed {
+		parts = append(parts, "extra attribute @"+a.Attribute)
+	}
+
+	return strings.Join(append(parts, d.Changed...), "; ")
+}
+
+// Returned by EnsureIndex when the live index does not match the definition and the policy does not fix it
+type IndexMismatchError struct {
+	Index string
+	Diff  *IndexDiff
+}
+
+func (e *IndexMismatchError) Error() string {
+	return "redisearch: index " + e.Index + " does not match its definition: " + e.Diff.String()
+}
+
+// Outcome of EnsureIndex
+type EnsureResult struct {
+	Index   string     // index behind the definition name, a versioned name when EnsureRebuild manages it through an alias
+	Created bool       // the index did not exist
+	Altered []string   // attributes added with FT.ALTER
+	Rebuilt bool       // a new index replaced the old one
+	Diff    *IndexDiff // differences found on the existing index, nil when it was created
+	Skipped bool       // EnsureIndexLocked left the index to the instance holding the lock
+}
+
+/*
+EnsureIndex creates the index of ftc when it is missing and otherwise reconciles it according to policy.
+It is safe to run from every instance at startup: an index or attribute another instance created
+in the meantime counts as done.
+
+With EnsureRebuild the definition name is an alias. A new index {name}_v{n} is created on the same prefixes,
+EnsureIndex waits for its initial scan, points the alias at it and drops the previous index without its documents.
+Run rebuilds under a lock when several instances start together, see EnsureIndexLocked.
+*/
+func (rsc *RedisearchClient) EnsureIndex(ctx context.Context, ftc *FtCreate, policy EnsurePolicy) (*EnsureResult, error) {
+	if err := policy.check(); err != nil {
+		return nil, err
+	}
+
+	name := ftc.indexname
+
+	info, err := rsc.Info(ctx, name)
+	if isUnknownIndex(err) {
+		return rsc.createIndex(ctx, ftc, policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &EnsureResult{Index: info.Name, Diff: DiffIndex(ftc, info)}
+	if res.Diff.Empty() {
+		return res, nil
+	}
+
+	switch policy {
+	case EnsureNoop:
+		return res, nil
+
+	case EnsureAlter:
+		if len(res.Diff.Removed) > 0 || len(res.Diff.Changed) > 0 {
+			return res, &IndexMismatchError{Index: name, Diff: res.Diff}
+		}
+		if err := checkLease(ctx); err != nil {
+			return res, err
+		}
+
+		for _, a := range res.Diff.Added {
+			err := rsc.alterIndex(ctx, info.Name, ftc.schemaOf(a.Attribute))
+			if err != nil && !isDuplicateField(err) {
+				return res, err
+			}
+			res.Altered = append(res.Altered, a.Attribute)
+		}
+
+		return res, nil
+
+	case EnsureRebuild:
+		return res, rsc.rebuildIndex(ctx, ftc, info.Name, res)
+	}
+
+	return res, &IndexMismatchError{Index: name, Diff: res.Diff}
+}
+
+func (rsc *RedisearchClient) createIndex(ctx context.Context, ftc *FtCreate, policy EnsurePolicy) (*EnsureResult, error) {
+	name := ftc.indexname
+	if policy == EnsureRebuild {
+		var err error
+		if name, err = rsc.nextIndexVersion(ctx, ftc.indexname, ftc.indexname); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkLease(ctx); err != nil {
+		return nil, err
+	}
+
+	err := rsc.do(ctx, ftc.withName(name).Serialize()...).Err()
+	if isIndexExists(err) && policy != EnsureRebuild {
+		// another instance won the race, compare against what it created
+		return rsc.EnsureIndex(ctx, ftc, policy)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if policy == EnsureRebuild {
+		if err := rsc.do(ctx, "FT.ALIASADD", ftc.indexname, name).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	rsc.invalidateIndex(ctx, ftc.indexname)
+
+	return &EnsureResult{Index: name, Created: true}, nil
+}
+
+func (rsc *RedisearchClient) alterIndex(ctx context.Context, indexName string, s FtSchema) error {
+	args := append([]interface{}{"FT.ALTER", indexName, "SCHEMA", "ADD"}, schemaArgs([]FtSchema{s})...)
+	if err := rsc.do(ctx, args...).Err(); err != nil {
+		return err
+	}
+
+	rsc.invalidateIndex(ctx, indexName)
+
+	return nil
+}
+
+func (rsc *RedisearchClient) rebuildIndex(ctx context.Context, ftc *FtCreate, current string, res *EnsureResult) error {
+	alias := ftc.indexname
+
+	next, err := rsc.nextIndexVersion(ctx, alias, current)
+	if err != nil {
+		return err
+	}
+	if err := checkLease(ctx); err != nil {
+		return err
+	}
+
+	if err := rsc.do(ctx, ftc.withName(next).Serialize()...).Err(); err != nil {
+		return err
+	}
+	if err := rsc.waitIndexed(ctx, next); err != nil {
+		return err
+	}
+
+	// the initial scan can be long, make sure no other instance took over meanwhile
+	if err := checkLease(ctx); err != nil {
+		return err
+	}
+
+	if current == alias {
+		// a plain index owns the name, it has to go before the alias can take it
+		if err := rsc.do(ctx, "FT.DROPINDEX", current).Err(); err != nil {
+			return err
+		}
+		if err := rsc.do(ctx, "FT.ALIASADD", alias, next).Err(); err != nil {
+			return err
+		}
+	} else {
+		if err := rsc.do(ctx, "FT.ALIASUPDATE", alias, next).Err(); err != nil {
+			return err
+		}
+		if err := rsc.do(ctx, "FT.DROPINDEX", current).Err(); err != nil {
+			return err
+		}
+	}
+
+	res.Index = next
+	res.Rebuilt = true
+
+	for _, name := range []string{alias, current, next} {
+		rsc.invalidateIndex(ctx, name)
+	}
+
+	return nil
+}
+
+// nextIndexVersion returns the first unused {alias}_v{n} after the current index
+func (rsc *RedisearchClient) nextIndexVersion(ctx context.Context, alias string, current string) (string, error) {
+	version := 1
+	if n, err := strconv.Atoi(strings.TrimPrefix(current, alias+"_v")); err == nil && current != alias {
+		version = n + 1
+	}
+
+	for ; ; version++ {
+		name := versionedIndexName(alias, version)
+
+		_, err := rsc.Info(ctx, name)
+		if isUnknownIndex(err) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// waitIndexed blocks until the initial scan of the index is over
+func (rsc *RedisearchClient) waitIndexed(ctx context.Context, indexName string) error {
+	for {
+		info, err := rsc.Info(ctx, indexName)
+		if err != nil {
+			return err
+		}
+		if !info.Indexing {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(EnsureIndexingPollInterval):
+		}
+	}
+}
+
+func versionedIndexName(name string, version int) string {
+	return name + "_v" + strconv.Itoa(version)
+}
+
+// withName returns a copy of the builder that creates indexName
+func (ftc *FtCreate) withName(indexName string) *FtCreate {
+	c := *ftc
+	c.indexname = indexName
+	if c.datatype == "" {
+		c.datatype = HASH
+	}
+
+	return &c
+}
+
+// schemaOf returns the schema entry queried as attr
+func (ftc *FtCreate) schemaOf(attr string) FtSchema {
+	for _, s := range ftc.schema {
+		if s.attribute == attr || (s.attribute == "" && s.identifier == attr) {
+			return s
+		}
+	}
+
+	return FtSchema{}
+}
+
+// schemaArgs returns the attribute arguments FT.CREATE writes after SCHEMA
+func schemaArgs(schema []FtSchema) []interface{} {
+	args := (&FtCreate{schema: schema}).Serialize()
+	for i, a := range args {
+		if a == "SCHEMA" {
+			return args[i+1:]
+		}
+	}
+
+	return nil
+}
+
+// DiffIndex compares the definition of ftc with the FT.INFO of the live index
+func DiffIndex(ftc *FtCreate, info *IndexInfo) *IndexDiff {
+	diff := &IndexDiff{}
+
+	datatype := ftc.datatype
+	if datatype == "" {
+		datatype = HASH
+	}
+	if !strings.EqualFold(datatype, info.DataType) {
+		diff.Changed = append(diff.Changed, fmt.Sprintf("ON %s, index has %s", datatype, info.DataType))
+	}
+	if strings.Join(ftc.prefix, " ") != strings.Join(info.Prefixes, " ") {
+		diff.Changed = append(diff.Changed, fmt.Sprintf("PREFIX %q, index has %q", ftc.prefix, info.Prefixes))
+	}
+	if ftc.filterexp != info.Filter {
+		diff.Changed = append(diff.Changed, fmt.Sprintf("FILTER %q, index has %q", ftc.filterexp, info.Filter))
+	}
+	if !strings.EqualFold(defaultString(ftc.language, "english"), defaultString(info.Language, "english")) {
+		diff.Changed = append(diff.Changed, fmt.Sprintf("LANGUAGE %s, index has %s", ftc.language, info.Language))
+	}
+
+	settings := []struct {
+		name, want, have, def string
+	}{
+		{"LANGUAGE_FIELD", ftc.languagefield, info.LanguageField, "__language"},
+		{"SCORE_FIELD", ftc.scorefield, info.ScoreField, "__score"},
+		{"PAYLOAD_FIELD", ftc.payloadfiled, info.PayloadField, "__payload"},
+	}
+	for _, s := range settings {
+		if defaultString(s.want, s.def) != defaultString(s.have, s.def) {
+			diff.Changed = append(diff.Changed, fmt.Sprintf("%s %s, index has %s", s.name, defaultString(s.want, s.def), defaultString(s.have, s.def)))
+		}
+	}
+
+	score, infoScore := ftc.score, info.Score
+	if score == 0 {
+		score = 1
+	}
+	if infoScore == 0 {
+		infoScore = 1
+	}
+	if score != infoScore {
+		diff.Changed = append(diff.Changed, fmt.Sprintf("SCORE %v, index has %v", score, infoScore))
+	}
+
+	options := []struct {
+		name string
+		want bool
+	}{
+		{"NOOFFSETS", ftc.nooffsets},
+		{"NOFREQS", ftc.nofreqs},
+		{"NOFIELDS", ftc.nofields},
+		{"MAXTEXTFIELDS", ftc.maxtextfields},
+	}
+	for _, o := range options {
+		if have := containsFold(info.Options, o.name); o.want != have {
+			diff.Changed = append(diff.Changed, fmt.Sprintf("%s %t, index has %t", o.name, o.want, have))
+		}
+	}
+
+	if !sameStopWords(ftc.stopwords, info.StopWords) {
+		diff.Changed = append(diff.Changed, fmt.Sprintf("STOPWORDS %s, index has %s", stopWordsString(ftc.stopwords), stopWordsString(info.StopWords)))
+	}
+
+	want := ftc.attributes()
+	for _, w := range want {
+		have := findAttribute(info.Attributes, w.Attribute)
+		if have == nil || have.Attribute != w.Attribute {
+			diff.Added = append(diff.Added, w)
+			continue
+		}
+
+		if problems := diffAttribute(w, *have); len(problems) > 0 {
+			diff.Changed = append(diff.Changed, "attribute @"+w.Attribute+": "+strings.Join(problems, ", "))
+		}
+	}
+
+	for _, h := range info.Attributes {
+		if a := findAttribute(want, h.Attribute); a == nil || a.Attribute != h.Attribute {
+			diff.Removed = append(diff.Removed, h)
+		}
+	}
+
+	return diff
+}
+
+func diffAttribute(want, have IndexAttribute) []string {
+	var problems []string
+	if want.Identifier != have.Identifier {
+		problems = append(problems, fmt.Sprintf("identifier %s, index has %s", want.Identifier, have.Identifier))
+	}
+	if want.Type != have.Type {
+		problems = append(problems, fmt.Sprintf("type %s, index has %s", want.Type, have.Type))
+		return problems
+	}
+
+	flags := []struct {
+		name       string
+		want, have bool
+	}{
+		{"SORTABLE", want.Sortable, have.Sortable},
+		{"UNF", want.UNF, have.UNF},
+		{"NOSTEM", want.NoStem, have.NoStem},
+		{"NOINDEX", want.NoIndex, have.NoIndex},
+		{"CASESENSITIVE", want.CaseSensitive, have.CaseSensitive},
+	}
+	for _, f := range flags {
+		if f.want != f.have {
+			problems = append(problems, fmt.Sprintf("%s %t, index has %t", f.name, f.want, f.have))
+		}
+	}
+
+	switch want.Type {
+	case FieldTypeText:
+		weight := want.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if float32(weight) != float32(have.Weight) {
+			problems = append(problems, fmt.Sprintf("WEIGHT %v, index has %v", weight, have.Weight))
+		}
+		if want.Phonetic != have.Phonetic {
+			problems = append(problems, fmt.Sprintf("PHONETIC %q, index has %q", want.Phonetic, have.Phonetic))
+		}
+	case FieldTypeTag:
+		if defaultString(want.Separator, ",") != defaultString(have.Separator, ",") {
+			problems = append(problems, fmt.Sprintf("SEPARATOR %q, index has %q", want.Separator, have.Separator))
+		}
+	}
+
+	return problems
+}
+
+// sameStopWords compares stop word lists in any order, nil stands for the default list
+func sameStopWords(want, have []string) bool {
+	if (want == nil) != (have == nil) {
+		return false
+	}
+	if len(want) != len(have) {
+		return false
+	}
+
+	set := make(map[string]bool, len(have))
+	for _, sw := range have {
+		set[strings.ToLower(sw)] = true
+	}
+	for _, sw := range want {
+		if !set[strings.ToLower(sw)] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func stopWordsString(words []string) string {
+	if words == nil {
+		return "default"
+	}
+
+	return fmt.Sprintf("%q", words)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultString(s string, def string) string {
+	if s == "" {
+		return def
+	}
+
+	return s
+}
+
+func isUnknownIndex(err error) bool {
+	return redisErrorContains(err, "unknown index name", "no such index")
+}
+
+func isIndexExists(err error) bool {
+	return redisErrorContains(err, "index already exists")
+}
+
+func isDuplicateField(err error) bool {
+	return redisErrorContains(err, "duplicate field")
+}
+
+func redisErrorContains(err error, msgs ...string) bool {
+	var rerr redis.Error
+	if err == nil || !errors.As(err, &rerr) {
+		return false
+	}
+
+	text := strings.ToLower(rerr.Error())
+	for _, msg := range msgs {
+		if strings.Contains(text, msg) {
+			return true
+		}
+	}
+
+	return false
+}
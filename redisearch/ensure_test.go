@@ -0,0 +1,169 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// seedIndexDefinition is the definition seedTestIndex creates
+func seedIndexDefinition() *FtCreate {
+	ftc := NewFtCreate("idx:drd").AddDataType(HASH).AddPrefix("drd:")
+	ftc.AddSchema(FieldTypeText, "name", "", true, ftc.AddSchemaTextOption(1, false, false, "")).
+		AddSchema(FieldTypeTag, "cats", "", false, ftc.AddSchemaTagOption(false, ",")).
+		AddSchema(FieldTypeNumeric, "updated", "", true, ftc.AddSchemaNumericOption(false))
+
+	return ftc
+}
+
+func TestRedisearchClient_EnsureIndex(t *testing.T) {
+	ctx := context.Background()
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	t.Run("Create Missing", func(t *testing.T) {
+		ftc := NewFtCreate("idx:terms").AddDataType(HASH).AddPrefix("term:")
+		ftc.AddSchema(FieldTypeTag, "uid", "", false, ftc.AddSchemaTagOption(false, ""))
+
+		res, err := rsc.EnsureIndex(ctx, ftc, EnsureFail)
+		if err != nil || !res.Created || res.Index != "idx:terms" {
+			t.Fatalf("EnsureIndex() = %+v, %v, want a created index", res, err)
+		}
+
+		// the second run finds the same definition
+		res, err = rsc.EnsureIndex(ctx, ftc, EnsureFail)
+		if err != nil || res.Created || !res.Diff.Empty() {
+			t.Errorf("EnsureIndex() = %+v, %v, want an unchanged index", res, err)
+		}
+	})
+
+	t.Run("Unknown Policy", func(t *testing.T) {
+		if _, err := rsc.EnsureIndex(ctx, seedIndexDefinition(), EnsurePolicy(7)); err == nil {
+			t.Error("EnsureIndex() error = nil, want the unknown policy refused")
+		}
+		if _, err := rsc.EnsureIndexLocked(ctx, seedIndexDefinition(), EnsurePolicy(-1), nil); err == nil {
+			t.Error("EnsureIndexLocked() error = nil, want the unknown policy refused")
+		}
+	})
+
+	t.Run("Added Attribute", func(t *testing.T) {
+		ftc := seedIndexDefinition()
+		ftc.AddSchema(FieldTypeText, "slug", "", false, ftc.AddSchemaTextOption(0, true, false, ""))
+
+		var merr *IndexMismatchError
+		if _, err := rsc.EnsureIndex(ctx, ftc, EnsureFail); !errors.As(err, &merr) || len(merr.Diff.Added) != 1 {
+			t.Fatalf("EnsureIndex(EnsureFail) error = %v, want a mismatch on slug", err)
+		}
+
+		if res, err := rsc.EnsureIndex(ctx, ftc, EnsureNoop); err != nil || len(res.Diff.Added) != 1 || res.Altered != nil {
+			t.Fatalf("EnsureIndex(EnsureNoop) = %+v, %v", res, err)
+		}
+
+		res, err := rsc.EnsureIndex(ctx, ftc, EnsureAlter)
+		if err != nil || !reflect.DeepEqual(res.Altered, []string{"slug"}) {
+			t.Fatalf("EnsureIndex(EnsureAlter) = %+v, %v, want slug altered", res, err)
+		}
+
+		if _, err := rsc.EnsureIndex(ctx, ftc, EnsureFail); err != nil {
+			t.Errorf("EnsureIndex() after alter error = %v", err)
+		}
+	})
+
+	t.Run("Changed Attribute", func(t *testing.T) {
+		ftc := NewFtCreate("idx:drd").AddDataType(HASH).AddPrefix("drd:")
+		ftc.AddSchema(FieldTypeText, "name", "", false, ftc.AddSchemaTextOption(1, false, false, "")).
+			AddSchema(FieldTypeTag, "cats", "", false, ftc.AddSchemaTagOption(false, ",")).
+			AddSchema(FieldTypeNumeric, "updated", "", true, ftc.AddSchemaNumericOption(false)).
+			AddSchema(FieldTypeText, "slug", "", false, ftc.AddSchemaTextOption(0, true, false, ""))
+
+		var merr *IndexMismatchError
+		_, err := rsc.EnsureIndex(ctx, ftc, EnsureAlter)
+		if !errors.As(err, &merr) {
+			t.Fatalf("EnsureIndex(EnsureAlter) error = %v, want a mismatch", err)
+		}
+		if want := []string{"attribute @name: SORTABLE false, index has true"}; !reflect.DeepEqual(merr.Diff.Changed, want) {
+			t.Errorf("Diff.Changed = %q, want %q", merr.Diff.Changed, want)
+		}
+	})
+
+	t.Run("Rebuild", func(t *testing.T) {
+		ftc := seedIndexDefinition()
+		ftc.AddSchema(FieldTypeText, "slug", "", false, ftc.AddSchemaTextOption(2, false, false, ""))
+
+		// a plain index owns the name: it is replaced by an alias on the first rebuild
+		res, err := rsc.EnsureIndex(ctx, ftc, EnsureRebuild)
+		if err != nil || !res.Rebuilt || res.Index != "idx:drd_v1" {
+			t.Fatalf("EnsureIndex(EnsureRebuild) = %+v, %v, want idx:drd_v1", res, err)
+		}
+
+		ftc = seedIndexDefinition()
+		res, err = rsc.EnsureIndex(ctx, ftc, EnsureRebuild)
+		if err != nil || !res.Rebuilt || res.Index != "idx:drd_v2" {
+			t.Fatalf("EnsureIndex(EnsureRebuild) = %+v, %v, want idx:drd_v2", res, err)
+		}
+
+		if _, err := rsc.Info(ctx, "idx:drd_v1"); !isUnknownIndex(err) {
+			t.Errorf("Info(idx:drd_v1) error = %v, want the old index dropped", err)
+		}
+
+		// the alias serves the documents of the new index
+		sr, err := rsc.DoSearch(ctx, NewFtSearch("idx:drd").AddQuery("*"))
+		if err != nil || sr.Total != 3 {
+			t.Errorf("DoSearch(alias) = %+v, %v, want 3 documents", sr, err)
+		}
+
+		if res, err := rsc.EnsureIndex(ctx, ftc, EnsureRebuild); err != nil || res.Rebuilt || res.Index != "idx:drd_v2" {
+			t.Errorf("EnsureIndex(EnsureRebuild) = %+v, %v, want no rebuild", res, err)
+		}
+	})
+}
+
+func TestDiffIndex_Settings(t *testing.T) {
+	ctx := context.Background()
+	rsc := newTestClient(t)
+
+	// without stop words the definition keeps the default list
+	definition := func(stopwords ...string) *FtCreate {
+		ftc := NewFtCreate("idx:opts").AddDataType(HASH).AddPrefix("opt:").
+			AddLanguageField("lang").AddScore(0.5).AddScoreField("rank").AddPayloadField("extra").
+			AddNoOffsets(true).AddNoFreqs(true).AddStopWords(stopwords...)
+		ftc.AddSchema(FieldTypeText, "name", "", false, ftc.AddSchemaTextOption(1, false, false, ""))
+		return ftc
+	}
+
+	if _, err := rsc.Create("idx:opts", definition("a", "the").Serialize()...); err != nil {
+		t.Fatal(err)
+	}
+	info, err := rsc.Info(ctx, "idx:opts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ftc  *FtCreate
+		want []string
+	}{
+		{"Same", definition("a", "the"), nil},
+		{"Stop Words Order", definition("the", "a"), nil},
+		{"Stop Words", definition("a"), []string{`STOPWORDS ["a"], index has ["a" "the"]`}},
+		{"Default Stop Words", definition(), []string{`STOPWORDS default, index has ["a" "the"]`}},
+		{"Score", definition("a", "the").AddScore(0), []string{"SCORE 1, index has 0.5"}},
+		{"Score Field", definition("a", "the").AddScoreField(""), []string{"SCORE_FIELD __score, index has rank"}},
+		{"Language Field", definition("a", "the").AddLanguageField("locale"), []string{"LANGUAGE_FIELD locale, index has lang"}},
+		{"Payload Field", definition("a", "the").AddPayloadField(""), []string{"PAYLOAD_FIELD __payload, index has extra"}},
+		{"Options", definition("a", "the").AddNoOffsets(false).AddNoFields(true), []string{
+			"NOOFFSETS false, index has true",
+			"NOFIELDS true, index has false",
+		}},
+		{"Max Text Fields", definition("a", "the").AddMaxTextFields(true), []string{"MAXTEXTFIELDS true, index has false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffIndex(tt.ftc, info).Changed; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffIndex().Changed = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
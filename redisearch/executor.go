@@ -0,0 +1,214 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Executor sends raw commands to Redis. Every client method goes through it,
+// so tests and custom transports can replace go-redis.
+type Executor interface {
+	// Do sends one command and returns its reply
+	Do(ctx context.Context, args ...interface{}) *redis.Cmd
+
+	// Pipeline sends all commands in one round trip. The returned error is the first failed command error.
+	Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error)
+}
+
+type goRedisExecutor struct {
+	client redis.UniversalClient
+}
+
+// NewGoRedisExecutor adapts a go-redis v8 client: redis.Client, redis.ClusterClient or redis.UniversalClient
+func NewGoRedisExecutor(client redis.UniversalClient) Executor {
+	return &goRedisExecutor{client: client}
+}
+
+func (e *goRedisExecutor) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	return e.client.Do(ctx, args...)
+}
+
+func (e *goRedisExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	pipe := e.client.Pipeline()
+
+	res := make([]*redis.Cmd, 0, len(cmds))
+	for _, args := range cmds {
+		res = append(res, pipe.Do(ctx, args...))
+	}
+
+	_, err := pipe.Exec(ctx)
+
+	return res, err
+}
+
+// RecordingExecutor captures every issued command and forwards it to the next executor.
+// Without a next executor every command gets an empty reply.
+type RecordingExecutor struct {
+	next     Executor
+	mu       sync.Mutex
+	commands [][]interface{}
+}
+
+func NewRecordingExecutor(next Executor) *RecordingExecutor {
+	return &RecordingExecutor{next: next}
+}
+
+func (e *RecordingExecutor) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	e.record(args)
+
+	if e.next == nil {
+		return redis.NewCmd(ctx, args...)
+	}
+
+	return e.next.Do(ctx, args...)
+}
+
+func (e *RecordingExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	for _, args := range cmds {
+		e.record(args)
+	}
+
+	if e.next == nil {
+		res := make([]*redis.Cmd, 0, len(cmds))
+		for _, args := range cmds {
+			res = append(res, redis.NewCmd(ctx, args...))
+		}
+		return res, nil
+	}
+
+	return e.next.Pipeline(ctx, cmds)
+}
+
+func (e *RecordingExecutor) record(args []interface{}) {
+	e.mu.Lock()
+	e.commands = append(e.commands, append([]interface{}(nil), args...))
+	e.mu.Unlock()
+}
+
+// Commands returns a copy of the recorded commands in issue order
+func (e *RecordingExecutor) Commands() [][]interface{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([][]interface{}(nil), e.commands...)
+}
+
+// Reset clears the recorded commands
+func (e *RecordingExecutor) Reset() {
+	e.mu.Lock()
+	e.commands = nil
+	e.mu.Unlock()
+}
+
+// ErrNoScriptedReply is returned once a ScriptedExecutor runs out of replies
+var ErrNoScriptedReply = errors.New("redisearch: no scripted reply left")
+
+type scriptedReply struct {
+	val interface{}
+	err error
+}
+
+// ScriptedExecutor answers commands with queued replies in order.
+// Replies use the RESP2 shapes of go-redis: string, int64, []interface{} and nil.
+type ScriptedExecutor struct {
+	mu      sync.Mutex
+	replies []scriptedReply
+}
+
+func NewScriptedExecutor() *ScriptedExecutor {
+	return &ScriptedExecutor{}
+}
+
+// AddReply queues a successful reply
+func (e *ScriptedExecutor) AddReply(val interface{}) *ScriptedExecutor {
+	e.mu.Lock()
+	e.replies = append(e.replies, scriptedReply{val: val})
+	e.mu.Unlock()
+
+	return e
+}
+
+// AddError queues a failed reply, use redis.Nil for a nil reply
+func (e *ScriptedExecutor) AddError(err error) *ScriptedExecutor {
+	e.mu.Lock()
+	e.replies = append(e.replies, scriptedReply{err: err})
+	e.mu.Unlock()
+
+	return e
+}
+
+// Pending returns the number of replies not consumed yet
+func (e *ScriptedExecutor) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.replies)
+}
+
+func (e *ScriptedExecutor) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	cmd := redis.NewCmd(ctx, args...)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.replies) == 0 {
+		cmd.SetErr(ErrNoScriptedReply)
+		return cmd
+	}
+
+	reply := e.replies[0]
+	e.replies = e.replies[1:]
+
+	if reply.err != nil {
+		cmd.SetErr(reply.err)
+	} else {
+		cmd.SetVal(reply.val)
+	}
+
+	return cmd
+}
+
+func (e *ScriptedExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	var firstErr error
+
+	res := make([]*redis.Cmd, 0, len(cmds))
+	for _, args := range cmds {
+		cmd := e.Do(ctx, args...)
+		if err := cmd.Err(); err != nil && err != redis.Nil && firstErr == nil {
+			firstErr = err
+		}
+		res = append(res, cmd)
+	}
+
+	return res, firstErr
+}
+
+// appendArgs flattens a single slice or map argument the way go-redis does for HSET
+func appendArgs(dst, src []interface{}) []interface{} {
+	if len(src) == 1 {
+		switch v := src[0].(type) {
+		case []string:
+			for _, s := range v {
+				dst = append(dst, s)
+			}
+			return dst
+		case []interface{}:
+			return append(dst, v...)
+		case map[string]interface{}:
+			for k, v := range v {
+				dst = append(dst, k, v)
+			}
+			return dst
+		case map[string]string:
+			for k, v := range v {
+				dst = append(dst, k, v)
+			}
+			return dst
+		}
+	}
+
+	return append(dst, src...)
+}
@@ -0,0 +1,128 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestScriptedExecutor_DoSearch(t *testing.T) {
+	scripted := NewScriptedExecutor().
+		AddReply([]interface{}{int64(2), "drd:1", "1.5", "drd:2", "0.5"}).
+		AddError(errors.New("idx:none: no such index"))
+
+	rec := NewRecordingExecutor(scripted)
+	rsc := NewRedisearchClientWithExecutor("test", rec)
+
+	fts := NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true).AddWithScores(true)
+
+	got, err := rsc.DoSearch(context.Background(), fts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &SearchResult{
+		Total: 2,
+		Docs:  []Document{{ID: "drd:1", Score: 1.5}, {ID: "drd:2", Score: 0.5}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DoSearch() = %+v, want %+v", got, want)
+	}
+
+	if _, err := rsc.DoSearch(context.Background(), NewFtSearch("idx:none").AddQuery("*")); err == nil {
+		t.Error("DoSearch() on scripted error should fail")
+	}
+
+	if _, err := rsc.DoSearch(context.Background(), fts); err != ErrNoScriptedReply {
+		t.Errorf("DoSearch() error = %v, want %v", err, ErrNoScriptedReply)
+	}
+
+	cmds := rec.Commands()
+	if len(cmds) != 3 || !reflect.DeepEqual(cmds[0], fts.Serialize()) {
+		t.Errorf("Commands() = %v", cmds)
+	}
+}
+
+func TestRecordingExecutor_Pipeline(t *testing.T) {
+	rec := NewRecordingExecutor(nil)
+	rsc := NewRedisearchClientWithExecutor("test", rec)
+
+	cmds := [][]interface{}{{"HSET", "drd:1", "uid", "1"}, {"DEL", "drd:2"}}
+	replies, err := rsc.pipeline(context.Background(), cmds)
+	if err != nil || len(replies) != 2 {
+		t.Fatalf("pipeline() = %v, %v", replies, err)
+	}
+
+	if got := rec.Commands(); !reflect.DeepEqual(got, cmds) {
+		t.Errorf("Commands() = %v, want %v", got, cmds)
+	}
+
+	rec.Reset()
+	if got := rec.Commands(); len(got) != 0 {
+		t.Errorf("Commands() after Reset = %v", got)
+	}
+}
+
+func TestScriptedExecutor_Pipeline(t *testing.T) {
+	scripted := NewScriptedExecutor().
+		AddReply(int64(1)).
+		AddError(redis.Nil).
+		AddError(errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"))
+
+	replies, err := scripted.Pipeline(context.Background(), [][]interface{}{{"HSET"}, {"HGET"}, {"HSET"}})
+	if err == nil || err.Error()[:9] != "WRONGTYPE" {
+		t.Errorf("Pipeline() error = %v, want WRONGTYPE", err)
+	}
+
+	if n, _ := replies[0].Int64(); n != 1 {
+		t.Errorf("Pipeline() reply 0 = %d, want 1", n)
+	}
+
+	if replies[1].Err() != redis.Nil {
+		t.Errorf("Pipeline() reply 1 error = %v, want redis.Nil", replies[1].Err())
+	}
+
+	if scripted.Pending() != 0 {
+		t.Errorf("Pending() = %d, want 0", scripted.Pending())
+	}
+}
+
+func Test_appendArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		src  []interface{}
+		want []interface{}
+	}{
+		{name: "Pairs", src: []interface{}{"uid", "1", "name", "Dream"}, want: []interface{}{"HSET", "drd:1", "name", "Dream", "uid", "1"}},
+		{name: "String Slice", src: []interface{}{[]string{"uid", "1"}}, want: []interface{}{"HSET", "drd:1", "uid", "1"}},
+		{name: "Map", src: []interface{}{map[string]string{"uid": "1", "name": "Dream"}}, want: []interface{}{"HSET", "drd:1", "name", "Dream", "uid", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := appendArgs([]interface{}{"HSET", "drd:1"}, tt.src)
+
+			// map iteration order is random, compare pairs sorted by field
+			if len(got) > 2 {
+				pairs := got[2:]
+				sort.Sort(argPairs(pairs))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type argPairs []interface{}
+
+func (p argPairs) Len() int           { return len(p) / 2 }
+func (p argPairs) Less(i, j int) bool { return p[2*i].(string) < p[2*j].(string) }
+func (p argPairs) Swap(i, j int) {
+	p[2*i], p[2*j] = p[2*j], p[2*i]
+	p[2*i+1], p[2*j+1] = p[2*j+1], p[2*i+1]
+}
@@ -0,0 +1,266 @@
+// Via: https://oss.redis.com/redisearch/Commands/#ftexplain
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+/*
+FT.EXPLAIN {index} {query} [DIALECT {dialect}]
+FT.EXPLAINCLI {index} {query} [DIALECT {dialect}]
+
+Returns the execution plan for a complex query. FT.EXPLAIN returns the plan as a single string,
+FT.EXPLAINCLI returns it as an array of lines which is easier to read in redis-cli.
+
+INTERSECT {
+  UNION {
+    @name:dream
+    @name:+dream(expanded)
+  }
+  NUMERIC {0.000000 <= @updated <= inf}
+}
+*/
+
+// Execution plan node types
+const (
+	ExplainIntersect = "INTERSECT"
+	ExplainUnion     = "UNION"
+	ExplainNot       = "NOT"
+	ExplainOptional  = "OPTIONAL"
+	ExplainExact     = "EXACT"
+	ExplainNumeric   = "NUMERIC"
+	ExplainGeo       = "GEO"
+	ExplainTag       = "TAG"
+	ExplainPrefix    = "PREFIX"
+	ExplainFuzzy     = "FUZZY"
+	ExplainLexRange  = "LEXRANGE"
+	ExplainIdList    = "IDS"
+	ExplainWildcard  = "WILDCARD"
+	ExplainEmpty     = "EMPTY"
+	ExplainTerm      = "TERM"
+)
+
+// Single node of the execution plan
+type ExplainNode struct {
+	Type     string // INTERSECT, UNION, NUMERIC, TAG, PREFIX, TERM ...
+	Field    string // @field modifier, empty when the node applies to all fields
+	Value    string // leaf content: term, prefix, numeric range ...
+	Children []*ExplainNode
+}
+
+// Parsed FT.EXPLAIN reply
+type ExplainResult struct {
+	Raw  string
+	Root *ExplainNode
+}
+
+/*
+FT.EXPLAIN {index} {query} [DIALECT {dialect}]
+dialect 0 means server default.
+*/
+func (rsc *RedisearchClient) Explain(ctx context.Context, indexName string, query string, dialect int) (*ExplainResult, error) {
+	raw, err := rsc.do(ctx, explainArgs("FT.EXPLAIN", indexName, query, dialect)...).Text()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := ParseExplain(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExplainResult{Raw: raw, Root: root}, nil
+}
+
+/*
+FT.EXPLAINCLI {index} {query} [DIALECT {dialect}]
+*/
+func (rsc *RedisearchClient) ExplainCli(ctx context.Context, indexName string, query string, dialect int) (*ExplainResult, error) {
+	lines, err := rsc.do(ctx, explainArgs("FT.EXPLAINCLI", indexName, query, dialect)...).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := strings.Join(lines, "\n")
+	root, err := ParseExplain(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExplainResult{Raw: raw, Root: root}, nil
+}
+
+func explainArgs(cmd string, indexName string, query string, dialect int) []interface{} {
+	args := []interface{}{cmd, indexName, query}
+	if dialect > 0 {
+		args = append(args, "DIALECT", dialect)
+	}
+
+	return args
+}
+
+// ParseExplain converts a FT.EXPLAIN or FT.EXPLAINCLI plan into a node tree.
+// A plan with several top level nodes is wrapped into an INTERSECT node.
+func ParseExplain(raw string) (*ExplainNode, error) {
+	root := &ExplainNode{Type: ExplainIntersect}
+	stack := []*ExplainNode{root}
+
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		parent := stack[len(stack)-1]
+
+		// "}" or "} => { $weight: 0.5; }" closes the current node
+		if strings.HasPrefix(line, "}") {
+			if len(stack) == 1 {
+				return nil, errors.New("explain plan has unbalanced braces")
+			}
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		node := parseExplainLine(line)
+		parent.Children = append(parent.Children, node)
+
+		if strings.HasSuffix(line, "{") {
+			stack = append(stack, node)
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, errors.New("explain plan has unbalanced braces")
+	}
+
+	if len(root.Children) == 1 {
+		return root.Children[0], nil
+	}
+
+	return root, nil
+}
+
+// parseExplainLine reads one plan line: "TYPE {", "@field:TYPE {", "TYPE {value}",
+// "TAG:@field {", "<WILDCARD>" or a plain term.
+func parseExplainLine(line string) *ExplainNode {
+	node := &ExplainNode{}
+	line = strings.TrimSuffix(line, "{")
+	line = strings.TrimSpace(line)
+
+	// @field:UNION / @field:term
+	if strings.HasPrefix(line, "@") {
+		if i := strings.Index(line, ":"); i > 0 {
+			node.Field = line[1:i]
+			line = line[i+1:]
+		}
+	}
+
+	switch line {
+	case "<WILDCARD>":
+		node.Type = ExplainWildcard
+		return node
+	case "<empty>", "":
+		node.Type = ExplainEmpty
+		return node
+	}
+
+	// TYPE {value} or TYPE{value} on a single line
+	if i := strings.Index(line, "{"); i > 0 && strings.HasSuffix(line, "}") {
+		keyword := strings.TrimSpace(line[:i])
+		if typ, field, ok := explainKeyword(keyword); ok {
+			node.Type = typ
+			if field != "" {
+				node.Field = field
+			}
+			node.Value = strings.TrimSpace(line[i+1 : len(line)-1])
+
+			// NUMERIC {0.000000 <= @updated <= inf}
+			if node.Field == "" && (typ == ExplainNumeric || typ == ExplainGeo) {
+				node.Field = explainRangeField(node.Value)
+			}
+			return node
+		}
+	}
+
+	if typ, field, ok := explainKeyword(line); ok {
+		node.Type = typ
+		if field != "" {
+			node.Field = field
+		}
+		return node
+	}
+
+	node.Type = ExplainTerm
+	node.Value = line
+
+	return node
+}
+
+// explainKeyword matches node keywords like "UNION", "TAG:@cats" or "GEO @loc:".
+func explainKeyword(keyword string) (string, string, bool) {
+	var field string
+
+	if i := strings.IndexAny(keyword, ": "); i > 0 {
+		field = strings.Trim(keyword[i+1:], "@: ")
+		keyword = keyword[:i]
+	}
+
+	switch keyword {
+	case "INTERSECT", "UNION", "NOT", "OPTIONAL", "EXACT", "NUMERIC", "GEO", "TAG", "PREFIX", "FUZZY", "LEXRANGE", "IDS":
+		return keyword, field, true
+	}
+
+	return "", "", false
+}
+
+func explainRangeField(value string) string {
+	for _, part := range strings.Fields(value) {
+		if strings.HasPrefix(part, "@") {
+			return strings.TrimSuffix(part[1:], ":")
+		}
+	}
+
+	return ""
+}
+
+// String pretty prints the node tree with two space indentation.
+func (n *ExplainNode) String() string {
+	var sb strings.Builder
+	n.write(&sb, 0)
+
+	return sb.String()
+}
+
+func (n *ExplainNode) write(sb *strings.Builder, depth int) {
+	sb.WriteString(strings.Repeat("  ", depth))
+	sb.WriteString(n.Type)
+
+	if n.Field != "" {
+		sb.WriteString(" @" + n.Field)
+	}
+
+	if n.Value != "" {
+		sb.WriteString(" " + n.Value)
+	}
+
+	sb.WriteString("\n")
+
+	for _, c := range n.Children {
+		c.write(sb, depth+1)
+	}
+}
+
+// Walk visits the node and all of its children depth first.
+// Returning false from fn skips the children of that node.
+func (n *ExplainNode) Walk(fn func(node *ExplainNode) bool) {
+	if !fn(n) {
+		return
+	}
+
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
@@ -0,0 +1,77 @@
+package redisearch
+
+import (
+	"testing"
+)
+
+func TestParseExplain(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Multi Fields Union",
+			raw: `@name|slug:UNION {
+  @name|slug:dream
+  @name|slug:+dream(expanded)
+  @name|slug:EXACT {
+    dream
+    test
+  }
+}
+`,
+			want: `UNION @name|slug
+  TERM @name|slug dream
+  TERM @name|slug +dream(expanded)
+  EXACT @name|slug
+    TERM dream
+    TERM test
+`,
+		},
+		{
+			name: "Tag Numeric And Prefix",
+			raw: `INTERSECT {
+  TAG:@cats {
+    dream
+    test
+  }
+  NUMERIC {1640995200.000000 <= @updated <= inf}
+  @name:PREFIX{dre*}
+  NOT{
+    <WILDCARD>
+  }
+}
+`,
+			want: `INTERSECT
+  TAG @cats
+    TERM dream
+    TERM test
+  NUMERIC @updated 1640995200.000000 <= @updated <= inf
+  PREFIX @name dre*
+  NOT
+    WILDCARD
+`,
+		},
+		{
+			name:    "Unbalanced Braces",
+			raw:     "INTERSECT {\n  dream\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExplain(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseExplain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseExplain() = \n%v, want \n%v", got, tt.want)
+			}
+		})
+	}
+}
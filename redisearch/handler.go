@@ -0,0 +1,328 @@
+package redisearch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Defaults of SearchEndpoint
+const (
+	DefaultSuggestMax     = 5
+	DefaultSuggestMaxSize = 20
+)
+
+// Query parameters of /suggest
+const (
+	SuggestParamPrefix = "prefix"
+	SuggestParamMax    = "max"
+)
+
+// One index served by a SearchHandler
+type SearchEndpoint struct {
+	Binder          *SearchBinder           // whitelist of /search, its q and facet parameters filter /aggregate too
+	HighlightFields []string                // TEXT fields highlighted when q is given
+	HighlightOpen   string                  // "<b>" when empty
+	HighlightClose  string                  // "</b>" when empty
+	FacetCounts     int                     // top values counted per facet field, one TagCloud aggregate each in one pipeline; 0 disables the counts
+	SuggestKey      string                  // FT.SUGGET dictionary of /suggest, disabled when empty
+	SuggestMax      int                     // suggestions without ?max=, DefaultSuggestMax when 0
+	SuggestMaxSize  int                     // largest ?max=, DefaultSuggestMaxSize when 0
+	Aggregates      map[string]*FtAggregate // pipelines of /aggregate/{name}, run on the index of the binder
+}
+
+// /search reply
+type SearchResponse struct {
+	SearchResult
+	Page   int64
+	Size   int64
+	Pages  int64
+	Query  string
+	Sort   string
+	Facets map[string][]TagCount // counts of the documents matching the search without the filter of the field itself, when FacetCounts is set
+}
+
+// /suggest reply
+type SuggestResponse struct {
+	Prefix      string
+	Suggestions []string
+}
+
+// Body of every error reply
+type ErrorResponse struct {
+	Error string
+}
+
+/*
+SearchHandler serves configured indexes as JSON over GET:
+
+	/{name}/search?q=dream&cats=sleep&sort=-updated&page=2   SearchResponse
+	/{name}/suggest?prefix=dr&max=5                          SuggestResponse
+	/{name}/aggregate/{pipeline}?q=dream                     AggregateResult
+
+Refused parameters are answered with 400 and unknown paths with 404. Other errors are
+answered with a bare 500, pass them to AddErrorLog to see them.
+
+	handler := client.NewSearchHandler().AddEndpoint("dreams", redisearch.SearchEndpoint{
+		Binder:          binder,
+		HighlightFields: []string{"name"},
+		SuggestKey:      "sug:dreams",
+	})
+	mux.Handle("/api/", http.StripPrefix("/api", handler))
+*/
+type SearchHandler struct {
+	rsc       *RedisearchClient
+	endpoints map[string]SearchEndpoint
+	errorLog  func(r *http.Request, err error)
+}
+
+func (rsc *RedisearchClient) NewSearchHandler() *SearchHandler {
+	return &SearchHandler{
+		rsc:       rsc,
+		endpoints: make(map[string]SearchEndpoint),
+	}
+}
+
+// AddEndpoint serves endpoint under /{name}/. Add every endpoint before serving requests.
+func (sh *SearchHandler) AddEndpoint(name string, endpoint SearchEndpoint) *SearchHandler {
+	if endpoint.HighlightOpen == "" {
+		endpoint.HighlightOpen = "<b>"
+	}
+	if endpoint.HighlightClose == "" {
+		endpoint.HighlightClose = "</b>"
+	}
+	if endpoint.SuggestMaxSize <= 0 {
+		endpoint.SuggestMaxSize = DefaultSuggestMaxSize
+	}
+	if endpoint.SuggestMax <= 0 {
+		endpoint.SuggestMax = DefaultSuggestMax
+	}
+	if endpoint.SuggestMax > endpoint.SuggestMaxSize {
+		endpoint.SuggestMax = endpoint.SuggestMaxSize
+	}
+
+	sh.endpoints[name] = endpoint
+
+	return sh
+}
+
+// AddErrorLog receives the errors answered with 500
+func (sh *SearchHandler) AddErrorLog(fn func(r *http.Request, err error)) *SearchHandler {
+	sh.errorLog = fn
+
+	return sh
+}
+
+func (sh *SearchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		sh.writeError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	endpoint, ok := sh.endpoints[parts[0]]
+	if !ok || len(parts) < 2 {
+		sh.writeError(w, r, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	var res interface{}
+	var err error
+	switch {
+	case parts[1] == "search" && len(parts) == 2:
+		res, err = sh.search(r, endpoint)
+	case parts[1] == "suggest" && len(parts) == 2 && endpoint.SuggestKey != "":
+		res, err = sh.suggest(r, endpoint)
+	case parts[1] == "aggregate" && len(parts) == 3 && endpoint.Aggregates[parts[2]] != nil:
+		res, err = sh.aggregate(r, endpoint, endpoint.Aggregates[parts[2]])
+	default:
+		sh.writeError(w, r, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	var berr *BindError
+	switch {
+	case errors.As(err, &berr):
+		sh.writeError(w, r, berr.StatusCode(), berr)
+	case err != nil:
+		sh.writeError(w, r, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func (sh *SearchHandler) search(r *http.Request, endpoint SearchEndpoint) (*SearchResponse, error) {
+	bs, err := endpoint.Binder.Bind(r.URL.Query())
+	if err != nil {
+		return nil, err
+	}
+
+	if bs.Query != "" && len(endpoint.HighlightFields) > 0 {
+		bs.AddHighlight(endpoint.HighlightFields, endpoint.HighlightOpen, endpoint.HighlightClose)
+	}
+
+	sr, err := sh.rsc.DoSearch(r.Context(), bs.FtSearch)
+	if err != nil {
+		return nil, err
+	}
+	if sr.Docs == nil {
+		sr.Docs = []Document{}
+	}
+
+	res := &SearchResponse{
+		SearchResult: *sr,
+		Page:         bs.Page,
+		Size:         bs.Size,
+		Pages:        (sr.Total + bs.Size - 1) / bs.Size,
+		Query:        bs.Query,
+		Sort:         bs.Sort,
+	}
+
+	if endpoint.FacetCounts > 0 {
+		if res.Facets, err = sh.facets(r, endpoint, bs); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// facets counts the values of every facet field, each without its own filter. The TagCloud
+// aggregates of all fields share one pipeline.
+func (sh *SearchHandler) facets(r *http.Request, endpoint SearchEndpoint, bs *BoundSearch) (map[string][]TagCount, error) {
+	config := endpoint.Binder.Config()
+	if len(config.FacetFields) == 0 {
+		return map[string][]TagCount{}, nil
+	}
+
+	attrs := make([]IndexAttribute, len(config.FacetFields))
+	cmds := make([][]interface{}, len(config.FacetFields))
+	for i, field := range config.FacetFields {
+		attrs[i] = endpoint.Binder.facetAttribute(field)
+		cmds[i] = tagCloudAggregate(config.Index, attrs[i], bs.queryWithout(field)).Serialize()
+	}
+
+	replies, err := sh.rsc.pipeline(r.Context(), cmds)
+	if err != nil {
+		return nil, err
+	}
+
+	facets := make(map[string][]TagCount, len(cmds))
+	for i, field := range config.FacetFields {
+		agg, err := parseAggregateResult(replies[i].Val(), false)
+		if err != nil {
+			return nil, err
+		}
+		facets[field] = tagCounts(agg.Rows, attrs[i].CaseSensitive, endpoint.FacetCounts)
+	}
+
+	return facets, nil
+}
+
+func (sh *SearchHandler) suggest(r *http.Request, endpoint SearchEndpoint) (*SuggestResponse, error) {
+	values := r.URL.Query()
+	if err := checkSuggestParams(values); err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSpace(values.Get(SuggestParamPrefix))
+	if prefix == "" {
+		return nil, &BindError{Param: SuggestParamPrefix, Reason: "missing"}
+	}
+
+	max := endpoint.SuggestMax
+	if val := values.Get(SuggestParamMax); val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 1 || n > endpoint.SuggestMaxSize {
+			return nil, &BindError{Param: SuggestParamMax, Reason: "want a number from 1 to " + strconv.Itoa(endpoint.SuggestMaxSize)}
+		}
+		max = n
+	}
+
+	reply, err := sh.rsc.SugGet(endpoint.SuggestKey, prefix, max)
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	sugs := replyStringSlice(reply)
+	if sugs == nil {
+		sugs = []string{}
+	}
+
+	return &SuggestResponse{Prefix: prefix, Suggestions: sugs}, nil
+}
+
+func checkSuggestParams(values url.Values) error {
+	for _, param := range []string{SuggestParamPrefix, SuggestParamMax} {
+		if len(values[param]) > 1 {
+			return &BindError{Param: param, Reason: "given more than once"}
+		}
+	}
+	for param := range values {
+		if param != SuggestParamPrefix && param != SuggestParamMax {
+			return &BindError{Param: param, Reason: "unknown parameter"}
+		}
+	}
+
+	return nil
+}
+
+// aggregate runs a copy of pipeline filtered by the q and facet parameters of the request
+func (sh *SearchHandler) aggregate(r *http.Request, endpoint SearchEndpoint, pipeline *FtAggregate) (*AggregateResult, error) {
+	values := r.URL.Query()
+	for _, param := range []string{BindParamSort, BindParamPage, BindParamSize} {
+		if _, ok := values[param]; ok {
+			return nil, &BindError{Param: param, Reason: "not supported by aggregates"}
+		}
+	}
+
+	bs, err := endpoint.Binder.Bind(values)
+	if err != nil {
+		return nil, err
+	}
+
+	fta := *pipeline
+	fta.AddIndexName(endpoint.Binder.Config().Index)
+	if bs.query != "*" {
+		if fta.query != "" && fta.query != "*" {
+			fta.AddQuery("(" + fta.query + ") " + bs.query)
+		} else {
+			fta.AddQuery(bs.query)
+		}
+	}
+
+	res, err := sh.rsc.Aggregate(r.Context(), &fta)
+	if err != nil {
+		return nil, err
+	}
+	if res.Rows == nil {
+		res.Rows = []map[string]string{}
+	}
+
+	return res, nil
+}
+
+// writeError hides the message of 500 replies from clients and hands the error to the error log
+func (sh *SearchHandler) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		if sh.errorLog != nil {
+			sh.errorLog(r, err)
+		}
+		msg = http.StatusText(status)
+	}
+
+	writeJSON(w, status, ErrorResponse{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
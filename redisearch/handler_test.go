@@ -0,0 +1,227 @@
+package redisearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// pipelineExecutor keeps the commands of every pipeline apart
+type pipelineExecutor struct {
+	Executor
+	pipelines [][][]interface{}
+}
+
+func (e *pipelineExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	e.pipelines = append(e.pipelines, cmds)
+
+	return e.Executor.Pipeline(ctx, cmds)
+}
+
+func TestSearchHandler(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+	for _, sug := range []string{"dream", "dreamer", "world"} {
+		if _, err := rsc.SugAdd("sug:drd", sug); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	byCat := NewFtAggregate("").AddLoad("cats")
+	byCat.AddGroupBy([]string{"@cats"}, NewReducer(ReducerCount, "count"))
+
+	endpoint := SearchEndpoint{
+		Binder: NewSearchBinder(SearchBinderConfig{
+			Index:        "idx:drd",
+			SearchFields: []string{"name"},
+			FacetFields:  []string{"cats"},
+			SortFields:   []string{"updated"},
+			DefaultSort:  "-updated",
+		}).AddIndexDefinition(seedIndexDefinition()),
+		HighlightFields: []string{"name"},
+		FacetCounts:     5,
+		SuggestKey:      "sug:drd",
+		Aggregates:      map[string]*FtAggregate{"by_cat": byCat},
+	}
+
+	var logged []error
+	handler := rsc.NewSearchHandler().AddEndpoint("drd", endpoint).AddErrorLog(func(r *http.Request, err error) {
+		logged = append(logged, err)
+	})
+
+	get := func(method, target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+		return w
+	}
+
+	t.Run("Search", func(t *testing.T) {
+		w := get(http.MethodGet, "/drd/search?q=dream&size=1&page=2")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+
+		var res SearchResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Total != 2 || res.Pages != 2 || res.Page != 2 || len(res.Docs) != 1 || res.Docs[0].ID != "drd:1" {
+			t.Errorf("search = %+v, want page 2 of 2 with drd:1", res)
+		}
+		if want := []TagCount{{"dream", 2}, {"test", 1}}; !reflect.DeepEqual(res.Facets["cats"], want) {
+			t.Errorf("facets = %v, want %v", res.Facets["cats"], want)
+		}
+	})
+
+	t.Run("Selected Facet", func(t *testing.T) {
+		w := get(http.MethodGet, "/drd/search?cats=test")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+
+		var res SearchResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Total != 1 || len(res.Docs) != 1 || res.Docs[0].ID != "drd:1" {
+			t.Errorf("search = %+v, want drd:1", res)
+		}
+
+		// the cats counts ignore the cats filter, so other values stay selectable
+		if want := []TagCount{{"dream", 2}, {"test", 1}, {"world", 1}}; !reflect.DeepEqual(res.Facets["cats"], want) {
+			t.Errorf("facets = %v, want %v", res.Facets["cats"], want)
+		}
+	})
+
+	t.Run("Facets In One Pipeline", func(t *testing.T) {
+		// without a schema every facet field is a TAG split on ","
+		facetEndpoint := endpoint
+		facetEndpoint.Binder = NewSearchBinder(SearchBinderConfig{Index: "idx:drd", FacetFields: []string{"cats", "tags"}})
+
+		pipes := &pipelineExecutor{Executor: NewScriptedExecutor().
+			AddReply([]interface{}{int64(1), "drd:1", []interface{}{"name", "Dream Test 1"}}).
+			AddReply([]interface{}{int64(2), []interface{}{"v", "dream", "n", "2"}, []interface{}{"v", "Test", "n", "1"}}).
+			AddReply([]interface{}{int64(1), []interface{}{"v", "night", "n", "1"}})}
+		facetHandler := NewRedisearchClientWithExecutor("test", pipes).NewSearchHandler().AddEndpoint("drd", facetEndpoint)
+
+		w := httptest.NewRecorder()
+		facetHandler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/drd/search?tags=night", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+
+		var res SearchResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		want := map[string][]TagCount{"cats": {{"dream", 2}, {"test", 1}}, "tags": {{"night", 1}}}
+		if !reflect.DeepEqual(res.Facets, want) {
+			t.Errorf("facets = %v, want %v", res.Facets, want)
+		}
+
+		// one FT.AGGREGATE per field, both in a single pipeline
+		if len(pipes.pipelines) != 1 || len(pipes.pipelines[0]) != 2 {
+			t.Fatalf("pipelines = %v, want one with two aggregates", pipes.pipelines)
+		}
+		if got := fmt.Sprint(pipes.pipelines[0][0][:3]); got != "[FT.AGGREGATE idx:drd @tags:{night}]" {
+			t.Errorf("cats facet = %s, want it filtered by tags only", got)
+		}
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		aggs := NewRecordingExecutor(rsc.Executor)
+		aggHandler := NewRedisearchClientWithExecutor("test", aggs).NewSearchHandler().AddEndpoint("drd", endpoint)
+
+		w := httptest.NewRecorder()
+		aggHandler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/drd/aggregate/by_cat?cats=dream", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+
+		// groups of the raw cats values of drd:1 and drd:2
+		var res AggregateResult
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if res.Total != 2 || len(res.Rows) != 2 {
+			t.Errorf("aggregate = %+v, want 2 rows", res)
+		}
+
+		got := strings.Trim(fmt.Sprint(aggs.Commands()[0][:4]), "[]")
+		if want := "FT.AGGREGATE idx:drd @cats:{dream} LOAD"; got != want {
+			t.Errorf("aggregate = %s, want %s", got, want)
+		}
+		if byCat.indexname != "" || byCat.query != "" {
+			t.Error("aggregate changed the configured pipeline")
+		}
+	})
+
+	t.Run("Suggest", func(t *testing.T) {
+		w := get(http.MethodGet, "/drd/suggest?prefix=dre&max=5")
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body)
+		}
+
+		var res SuggestResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatal(err)
+		}
+		if len(res.Suggestions) != 2 {
+			t.Errorf("suggestions = %v, want dream and dreamer", res.Suggestions)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantError  string
+	}{
+		{"Unknown Parameter", http.MethodGet, "/drd/search?tag=x", http.StatusBadRequest, "invalid parameter tag"},
+		{"Not Sortable", http.MethodGet, "/drd/search?sort=name", http.StatusBadRequest, "invalid parameter sort"},
+		{"Aggregate Page", http.MethodGet, "/drd/aggregate/by_cat?page=2", http.StatusBadRequest, "invalid parameter page"},
+		{"Suggest Without Prefix", http.MethodGet, "/drd/suggest", http.StatusBadRequest, "invalid parameter prefix"},
+		{"Suggest Max", http.MethodGet, "/drd/suggest?prefix=d&max=100", http.StatusBadRequest, "invalid parameter max"},
+		{"Unknown Endpoint", http.MethodGet, "/terms/search", http.StatusNotFound, "not found"},
+		{"Unknown Pipeline", http.MethodGet, "/drd/aggregate/by_name", http.StatusNotFound, "not found"},
+		{"Post", http.MethodPost, "/drd/search", http.StatusMethodNotAllowed, "method not allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get(tt.method, tt.target)
+
+			var res ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tt.wantStatus || !strings.Contains(res.Error, tt.wantError) {
+				t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.target, w.Code, res.Error, tt.wantStatus, tt.wantError)
+			}
+		})
+	}
+
+	t.Run("Internal Error", func(t *testing.T) {
+		missing := rsc.NewSearchHandler().AddEndpoint("missing", SearchEndpoint{
+			Binder: NewSearchBinder(SearchBinderConfig{Index: "idx:missing"}),
+		}).AddErrorLog(func(r *http.Request, err error) {
+			logged = append(logged, err)
+		})
+
+		w := httptest.NewRecorder()
+		missing.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing/search", nil))
+		if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "Unknown") {
+			t.Errorf("status = %d, body %s, want a bare 500", w.Code, w.Body)
+		}
+		if len(logged) != 1 || !isUnknownIndex(logged[0]) {
+			t.Errorf("logged = %v, want the unknown index error", logged)
+		}
+	})
+}
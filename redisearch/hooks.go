@@ -0,0 +1,172 @@
+package redisearch
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// CommandInfo describes one FT.* command sent through the client
+type CommandInfo struct {
+	Index    string // index, alias, suggestion or dictionary key; empty for FT._LIST and FT.CONFIG
+	Command  string // FT.SEARCH, FT.CURSOR READ ...
+	ArgCount int
+	Args     []interface{} // full command, hooks must not modify it
+	Duration time.Duration // set before AfterCommand
+	Results  int64         // total hits for searches and aggregates, reply length for arrays, -1 otherwise
+	Err      error         // set before AfterCommand, a nil reply is not an error
+}
+
+// Hook is called around every FT.* command. Hooks are called in the order they were
+// added before the command and in reverse order after it.
+type Hook interface {
+	BeforeCommand(ctx context.Context, info *CommandInfo) context.Context
+	AfterCommand(ctx context.Context, info *CommandInfo)
+}
+
+// AddHook installs a hook. Add hooks before the client is shared between goroutines.
+func (rsc *RedisearchClient) AddHook(hook Hook) *RedisearchClient {
+	rsc.hooks = append(rsc.hooks, hook)
+
+	return rsc
+}
+
+// doHooked runs the command with the before and after hooks
+func (rsc *RedisearchClient) doHooked(ctx context.Context, args []interface{}) *redis.Cmd {
+	info := newCommandInfo(args)
+
+	for _, h := range rsc.hooks {
+		ctx = h.BeforeCommand(ctx, info)
+	}
+
+	start := time.Now()
+	cmd := rsc.Executor.Do(ctx, args...)
+	info.Duration = time.Since(start)
+
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		info.Err = err
+	} else {
+		info.Results = resultCount(info.Command, cmd.Val())
+	}
+
+	for i := len(rsc.hooks) - 1; i >= 0; i-- {
+		rsc.hooks[i].AfterCommand(ctx, info)
+	}
+
+	return cmd
+}
+
+// pipelineHooked runs the hooks of every FT.* command in the pipeline. All of them get the pipeline duration.
+// Every command has its own hook context derived from ctx, the pipeline itself runs with ctx.
+func (rsc *RedisearchClient) pipelineHooked(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	infos := make([]*CommandInfo, len(cmds))
+	ctxs := make([]context.Context, len(cmds))
+	for i, args := range cmds {
+		if !isSearchCommand(args) {
+			continue
+		}
+
+		infos[i], ctxs[i] = newCommandInfo(args), ctx
+		for _, h := range rsc.hooks {
+			ctxs[i] = h.BeforeCommand(ctxs[i], infos[i])
+		}
+	}
+
+	start := time.Now()
+	replies, err := rsc.Executor.Pipeline(ctx, cmds)
+	duration := time.Since(start)
+
+	for i, info := range infos {
+		if info == nil {
+			continue
+		}
+
+		info.Duration = duration
+		switch {
+		case i >= len(replies):
+			info.Err = err
+		case replies[i].Err() != nil && replies[i].Err() != redis.Nil:
+			info.Err = replies[i].Err()
+		default:
+			info.Results = resultCount(info.Command, replies[i].Val())
+		}
+
+		for j := len(rsc.hooks) - 1; j >= 0; j-- {
+			rsc.hooks[j].AfterCommand(ctxs[i], info)
+		}
+	}
+
+	return replies, err
+}
+
+func isSearchCommand(args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	name, ok := args[0].(string)
+
+	return ok && len(name) > 3 && strings.EqualFold(name[:3], "FT.")
+}
+
+func newCommandInfo(args []interface{}) *CommandInfo {
+	info := &CommandInfo{
+		Command:  strings.ToUpper(replyString(args[0])),
+		ArgCount: len(args),
+		Args:     args,
+		Results:  -1,
+	}
+
+	pos := 1
+	switch info.Command {
+	case "FT._LIST", "FT.CONFIG":
+		pos = 0
+	case "FT.CURSOR":
+		// FT.CURSOR READ|DEL {index}
+		if len(args) > 1 {
+			info.Command += " " + strings.ToUpper(replyString(args[1]))
+		}
+		pos = 2
+	case "FT.ALIASADD", "FT.ALIASUPDATE":
+		// FT.ALIASADD {alias} {index}
+		pos = 2
+	}
+
+	if pos > 0 && pos < len(args) {
+		info.Index = replyString(args[pos])
+	}
+
+	return info
+}
+
+// resultCount returns the total of search replies or the length of array replies
+func resultCount(command string, val interface{}) int64 {
+	items, ok := val.([]interface{})
+	if !ok {
+		return -1
+	}
+
+	switch command {
+	case "FT.SEARCH", "FT.AGGREGATE":
+		if len(items) > 0 {
+			// FT.AGGREGATE ... WITHCURSOR replies [[total ...] cursor]
+			if inner, ok := items[0].([]interface{}); ok && command == "FT.AGGREGATE" {
+				return resultCount("FT.SEARCH", inner)
+			}
+			if total, err := replyInt64(items[0]); err == nil {
+				return total
+			}
+		}
+	case "FT.PROFILE", "FT.CURSOR READ":
+		// [[total ...] profile] and [[total ...] cursor]
+		if len(items) > 0 {
+			return resultCount("FT.SEARCH", items[0])
+		}
+	default:
+		return int64(len(items))
+	}
+
+	return -1
+}
@@ -0,0 +1,190 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingHook struct {
+	calls []string
+	infos []CommandInfo
+}
+
+func (h *recordingHook) BeforeCommand(ctx context.Context, info *CommandInfo) context.Context {
+	h.calls = append(h.calls, "before "+info.Command)
+	return ctx
+}
+
+func (h *recordingHook) AfterCommand(ctx context.Context, info *CommandInfo) {
+	h.calls = append(h.calls, "after "+info.Command)
+	h.infos = append(h.infos, *info)
+}
+
+func TestRedisearchClient_AddHook(t *testing.T) {
+	scripted := NewScriptedExecutor().
+		AddReply([]interface{}{int64(7), "drd:1", "drd:2"}).
+		AddError(errors.New("Unknown Index name")).
+		AddReply(int64(1)).
+		AddReply([]interface{}{"idx:drd", "idx:sug"})
+
+	hook := &recordingHook{}
+	rsc := NewRedisearchClientWithExecutor("test", scripted).AddHook(hook)
+	ctx := context.Background()
+
+	rsc.DoSearch(ctx, NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true))
+	rsc.DropIndex("idx:none", false)
+	rsc.HSet("drd:1", "uid", "1")
+	rsc.List()
+
+	wantCalls := []string{
+		"before FT.SEARCH", "after FT.SEARCH",
+		"before FT.DROPINDEX", "after FT.DROPINDEX",
+		"before FT._LIST", "after FT._LIST",
+	}
+	if !reflect.DeepEqual(hook.calls, wantCalls) {
+		t.Fatalf("hook calls = %v, want %v", hook.calls, wantCalls)
+	}
+
+	tests := []struct {
+		name        string
+		info        CommandInfo
+		wantIndex   string
+		wantArgs    int
+		wantResults int64
+		wantErr     bool
+	}{
+		{name: "Search", info: hook.infos[0], wantIndex: "idx:drd", wantArgs: 7, wantResults: 7},
+		{name: "Error", info: hook.infos[1], wantIndex: "idx:none", wantArgs: 2, wantResults: -1, wantErr: true},
+		{name: "List", info: hook.infos[2], wantIndex: "", wantArgs: 1, wantResults: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.info.Index != tt.wantIndex || tt.info.ArgCount != tt.wantArgs || tt.info.Results != tt.wantResults || (tt.info.Err != nil) != tt.wantErr {
+				t.Errorf("CommandInfo = %+v", tt.info)
+			}
+		})
+	}
+}
+
+type spanKey struct{}
+
+// spanHook starts a span per command and records the span every AfterCommand receives
+type spanHook struct {
+	parents []interface{}
+	ended   []string
+}
+
+func (h *spanHook) BeforeCommand(ctx context.Context, info *CommandInfo) context.Context {
+	h.parents = append(h.parents, ctx.Value(spanKey{}))
+	return context.WithValue(ctx, spanKey{}, info.Index)
+}
+
+func (h *spanHook) AfterCommand(ctx context.Context, info *CommandInfo) {
+	h.ended = append(h.ended, replyString(ctx.Value(spanKey{}))+" "+info.Index)
+}
+
+func TestRedisearchClient_pipelineHooked(t *testing.T) {
+	scripted := NewScriptedExecutor().
+		AddReply([]interface{}{int64(1), "drd:1"}).
+		AddReply(int64(1)).
+		AddReply([]interface{}{int64(0)})
+
+	hook := &spanHook{}
+	rsc := NewRedisearchClientWithExecutor("test", scripted).AddHook(hook)
+
+	_, err := rsc.pipeline(context.Background(), [][]interface{}{
+		NewFtSearch("idx:a").AddQuery("*").Serialize(),
+		{"HSET", "drd:1", "uid", "1"},
+		NewFtSearch("idx:b").AddQuery("*").Serialize(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// spans are siblings started from the pipeline context, each ended with its own context
+	if want := []interface{}{nil, nil}; !reflect.DeepEqual(hook.parents, want) {
+		t.Errorf("span parents = %v, want %v", hook.parents, want)
+	}
+	if want := []string{"idx:a idx:a", "idx:b idx:b"}; !reflect.DeepEqual(hook.ended, want) {
+		t.Errorf("ended spans = %v, want %v", hook.ended, want)
+	}
+}
+
+func Test_newCommandInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []interface{}
+		wantCommand string
+		wantIndex   string
+	}{
+		{name: "Search", args: []interface{}{"FT.SEARCH", "idx:drd", "*"}, wantCommand: "FT.SEARCH", wantIndex: "idx:drd"},
+		{name: "Cursor", args: []interface{}{"FT.CURSOR", "READ", "idx:drd", int64(12)}, wantCommand: "FT.CURSOR READ", wantIndex: "idx:drd"},
+		{name: "Alias", args: []interface{}{"FT.ALIASADD", "drd", "idx:drd"}, wantCommand: "FT.ALIASADD", wantIndex: "idx:drd"},
+		{name: "Config", args: []interface{}{"FT.CONFIG", "GET", "*"}, wantCommand: "FT.CONFIG", wantIndex: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newCommandInfo(tt.args)
+			if got.Command != tt.wantCommand || got.Index != tt.wantIndex {
+				t.Errorf("newCommandInfo() = %q %q, want %q %q", got.Command, got.Index, tt.wantCommand, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func Test_resultCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		val     interface{}
+		want    int64
+	}{
+		{name: "Search", command: "FT.SEARCH", val: []interface{}{int64(7), "drd:1"}, want: 7},
+		{name: "Aggregate", command: "FT.AGGREGATE", val: []interface{}{int64(3), []interface{}{"cats", "dream"}}, want: 3},
+		{name: "Aggregate With Cursor", command: "FT.AGGREGATE", val: []interface{}{[]interface{}{int64(5), []interface{}{"cats", "dream"}}, int64(12)}, want: 5},
+		{name: "Cursor Read", command: "FT.CURSOR READ", val: []interface{}{[]interface{}{int64(5)}, int64(0)}, want: 5},
+		{name: "List", command: "FT._LIST", val: []interface{}{"idx:drd", "idx:sug"}, want: 2},
+		{name: "Status", command: "FT.CREATE", val: "OK", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultCount(tt.command, tt.val); got != tt.want {
+				t.Errorf("resultCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatencyHistogram_ServeHTTP(t *testing.T) {
+	lh := NewLatencyHistogram(10*time.Millisecond, time.Millisecond)
+	lh.AfterCommand(context.Background(), &CommandInfo{Index: "idx:drd", Command: "FT.SEARCH", Duration: 500 * time.Microsecond, Results: 3})
+	lh.AfterCommand(context.Background(), &CommandInfo{Index: "idx:drd", Command: "FT.SEARCH", Duration: 5 * time.Millisecond, Results: 2})
+	lh.AfterCommand(context.Background(), &CommandInfo{Index: "idx:drd", Command: "FT.SEARCH", Duration: time.Second, Err: errors.New("timeout")})
+
+	rec := httptest.NewRecorder()
+	lh.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, line := range []string{
+		`redisearch_command_duration_seconds_bucket{index="idx:drd",command="FT.SEARCH",le="0.001"} 1`,
+		`redisearch_command_duration_seconds_bucket{index="idx:drd",command="FT.SEARCH",le="0.01"} 2`,
+		`redisearch_command_duration_seconds_bucket{index="idx:drd",command="FT.SEARCH",le="+Inf"} 3`,
+		`redisearch_command_duration_seconds_sum{index="idx:drd",command="FT.SEARCH"} 1.0055`,
+		`redisearch_command_duration_seconds_count{index="idx:drd",command="FT.SEARCH"} 3`,
+		`redisearch_command_errors_total{index="idx:drd",command="FT.SEARCH"} 1`,
+		`redisearch_command_results_total{index="idx:drd",command="FT.SEARCH"} 5`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("ServeHTTP() missing %q in\n%s", line, body)
+		}
+	}
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
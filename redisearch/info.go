@@ -0,0 +1,220 @@
+package redisearch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Schema attribute of an index, from FT.INFO or an FtCreate
+type IndexAttribute struct {
+	Identifier    string // hash field
+	Attribute     string // name used in queries, the identifier unless AS is set
+	Type          string // TEXT, NUMERIC, TAG, GEO
+	Sortable      bool
+	UNF           bool
+	NoStem        bool
+	NoIndex       bool
+	CaseSensitive bool
+	Weight        float64
+	Separator     string
+	Phonetic      string
+}
+
+// Typed FT.INFO reply
+type IndexInfo struct {
+	Name                 string
+	DataType             string
+	Prefixes             []string
+	Filter               string
+	Language             string
+	LanguageField        string
+	Score                float64
+	ScoreField           string
+	PayloadField         string
+	Options              []string // NOOFFSETS, NOFIELDS, NOFREQS, MAXTEXTFIELDS ...
+	StopWords            []string // custom stop words, nil with the default list
+	Attributes           []IndexAttribute
+	NumDocs              int64
+	Indexing             bool
+	PercentIndexed       float64
+	HashIndexingFailures int64
+}
+
+// Attribute returns the attribute named name (AS alias or identifier), nil when there is none
+func (info *IndexInfo) Attribute(name string) *IndexAttribute {
+	return findAttribute(info.Attributes, name)
+}
+
+func findAttribute(attrs []IndexAttribute, name string) *IndexAttribute {
+	for i := range attrs {
+		if attrs[i].Attribute == name {
+			return &attrs[i]
+		}
+	}
+
+	for i := range attrs {
+		if attrs[i].Identifier == name {
+			return &attrs[i]
+		}
+	}
+
+	return nil
+}
+
+// attributes returns the schema of the index builder in FT.INFO form
+func (ftc *FtCreate) attributes() []IndexAttribute {
+	attrs := make([]IndexAttribute, 0, len(ftc.schema))
+	for _, s := range ftc.schema {
+		attr := IndexAttribute{
+			Identifier:    s.identifier,
+			Attribute:     s.attribute,
+			Type:          s.fieldtype,
+			Sortable:      s.sortable,
+			UNF:           s.option.unf,
+			NoStem:        s.option.nostem,
+			NoIndex:       s.option.noindex,
+			CaseSensitive: s.option.casesensitive,
+			Weight:        float64(s.option.weight),
+			Separator:     s.option.separator,
+			Phonetic:      s.option.phonetic,
+		}
+		if attr.Attribute == "" {
+			attr.Attribute = attr.Identifier
+		}
+		attrs = append(attrs, attr)
+	}
+
+	return attrs
+}
+
+/*
+FT.INFO reply: flat key value pairs. Attributes are listed under "attributes" (RediSearch 2.2+) as
+
+	identifier {id} attribute {name} type {type} [WEIGHT {w}] [SEPARATOR {sep}] [SORTABLE] [NOSTEM] ...
+
+or under "fields" (older versions) as {name} type {type} ...
+*/
+func parseIndexInfo(reply interface{}) (*IndexInfo, error) {
+	items, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redisearch: unexpected info reply type %T", reply)
+	}
+
+	info := &IndexInfo{}
+	for i := 0; i+1 < len(items); i += 2 {
+		val := items[i+1]
+
+		switch replyString(items[i]) {
+		case "index_name":
+			info.Name = replyString(val)
+		case "index_options":
+			info.Options = replyStringSlice(val)
+		case "index_definition":
+			def, _ := val.([]interface{})
+			for j := 0; j+1 < len(def); j += 2 {
+				switch replyString(def[j]) {
+				case "key_type":
+					info.DataType = replyString(def[j+1])
+				case "prefixes":
+					for _, p := range replyStringSlice(def[j+1]) {
+						if p != "" {
+							info.Prefixes = append(info.Prefixes, p)
+						}
+					}
+				case "filter":
+					info.Filter = replyString(def[j+1])
+				case "default_language":
+					info.Language = replyString(def[j+1])
+				case "language_field":
+					info.LanguageField = replyString(def[j+1])
+				case "default_score":
+					info.Score, _ = strconv.ParseFloat(replyString(def[j+1]), 64)
+				case "score_field":
+					info.ScoreField = replyString(def[j+1])
+				case "payload_field":
+					info.PayloadField = replyString(def[j+1])
+				}
+			}
+		case "attributes":
+			attrs, _ := val.([]interface{})
+			for _, a := range attrs {
+				fields, _ := a.([]interface{})
+				info.Attributes = append(info.Attributes, parseIndexAttribute(fields))
+			}
+		case "fields":
+			attrs, _ := val.([]interface{})
+			for _, a := range attrs {
+				fields, _ := a.([]interface{})
+				if len(fields) == 0 {
+					continue
+				}
+				name := replyString(fields[0])
+				fields = append([]interface{}{"identifier", name, "attribute", name}, fields[1:]...)
+				info.Attributes = append(info.Attributes, parseIndexAttribute(fields))
+			}
+		case "stopwords_list":
+			info.StopWords = replyStringSlice(val)
+			if info.StopWords == nil {
+				info.StopWords = []string{}
+			}
+		case "num_docs":
+			info.NumDocs, _ = strconv.ParseInt(replyString(val), 10, 64)
+		case "indexing":
+			info.Indexing = replyString(val) != "0"
+		case "percent_indexed":
+			info.PercentIndexed, _ = strconv.ParseFloat(replyString(val), 64)
+		case "hash_indexing_failures":
+			info.HashIndexingFailures, _ = strconv.ParseInt(replyString(val), 10, 64)
+		}
+	}
+
+	return info, nil
+}
+
+func parseIndexAttribute(fields []interface{}) IndexAttribute {
+	var attr IndexAttribute
+	for i := 0; i < len(fields); i++ {
+		key := replyString(fields[i])
+
+		var val string
+		switch strings.ToLower(key) {
+		case "identifier", "attribute", "type", "weight", "separator", "phonetic":
+			if i+1 < len(fields) {
+				i++
+				val = replyString(fields[i])
+			}
+		}
+
+		switch strings.ToUpper(key) {
+		case "IDENTIFIER":
+			attr.Identifier = val
+		case "ATTRIBUTE":
+			attr.Attribute = val
+		case "TYPE":
+			attr.Type = strings.ToUpper(val)
+		case "WEIGHT":
+			attr.Weight, _ = strconv.ParseFloat(val, 64)
+		case "SEPARATOR":
+			attr.Separator = val
+		case "PHONETIC":
+			attr.Phonetic = val
+		case "SORTABLE":
+			attr.Sortable = true
+		case "UNF":
+			attr.UNF = true
+		case "NOSTEM":
+			attr.NoStem = true
+		case "NOINDEX":
+			attr.NoIndex = true
+		case "CASESENSITIVE":
+			attr.CaseSensitive = true
+		}
+	}
+
+	if attr.Attribute == "" {
+		attr.Attribute = attr.Identifier
+	}
+
+	return attr
+}
@@ -0,0 +1,278 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Default number of documents fetched per page
+const DefaultIteratorBatchSize = 100
+
+// ErrUnsortedCursorFallback is returned by iterators with a cursor fallback and no SORTBY,
+// the order of an unsorted search changes between the pages and the cursor
+var ErrUnsortedCursorFallback = errors.New("redisearch: cursor fallback needs a search with SORTBY")
+
+// SearchIterator pages through every document matching a search builder.
+//
+//	it := client.NewSearchIterator(ctx, fts, 500)
+//	defer it.Close()
+//	for it.Next() {
+//		doc := it.Doc()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+//
+// FT.SEARCH can not page past the MAXSEARCHRESULTS server option. With AddCursorFallback
+// the iterator switches to an FT.AGGREGATE cursor before reaching that offset. The cursor
+// reruns the search and skips the documents already read, so the search needs a SORTBY on
+// an attribute with unique values: documents of equal sort keys may be read twice or missed.
+// Cursor results have no score and only carry the AddReturnFields fields.
+type SearchIterator struct {
+	rsc        *RedisearchClient
+	ctx        context.Context
+	fts        FtSearch
+	batchSize  int64
+	maxResults int64
+
+	offset  int64 // absolute offset of the next page
+	total   int64
+	fetched bool
+	done    bool
+	docs    []Document
+	pos     int
+	doc     Document
+	err     error
+
+	cursor struct {
+		active bool
+		id     int64
+		skip   int64
+	}
+}
+
+// NewSearchIterator starts at the LIMIT offset of the builder and ignores its LIMIT num.
+// batchSize 0 uses DefaultIteratorBatchSize.
+func (rsc *RedisearchClient) NewSearchIterator(ctx context.Context, fts *FtSearch, batchSize int64) *SearchIterator {
+	if batchSize <= 0 {
+		batchSize = DefaultIteratorBatchSize
+	}
+
+	return &SearchIterator{
+		rsc:       rsc,
+		ctx:       ctx,
+		fts:       *fts,
+		batchSize: batchSize,
+		offset:    fts.limit.offset,
+	}
+}
+
+// AddCursorFallback switches to an aggregate cursor once the offset would pass maxResults.
+// Use the MAXSEARCHRESULTS server option (see GetConfig); 0 disables the fallback.
+// The search must have a SORTBY, Next fails with ErrUnsortedCursorFallback otherwise.
+func (it *SearchIterator) AddCursorFallback(maxResults int64) *SearchIterator {
+	it.maxResults = maxResults
+
+	return it
+}
+
+// Next advances to the next document. It returns false when all documents are read or an error occurred.
+func (it *SearchIterator) Next() bool {
+	for it.pos >= len(it.docs) {
+		if it.err != nil || it.done {
+			return false
+		}
+
+		it.docs, it.pos = nil, 0
+		if it.err = it.fetch(); it.err != nil {
+			return false
+		}
+	}
+
+	it.doc = it.docs[it.pos]
+	it.pos++
+
+	return true
+}
+
+// Doc returns the current document
+func (it *SearchIterator) Doc() Document {
+	return it.doc
+}
+
+// Err returns the first error of the iteration
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+// Total returns the total reported by FT.SEARCH, 0 before the first Next
+func (it *SearchIterator) Total() int64 {
+	return it.total
+}
+
+// Close deletes the aggregate cursor if the iteration stopped early
+func (it *SearchIterator) Close() error {
+	it.done = true
+
+	if it.cursor.active && it.cursor.id != 0 {
+		id := it.cursor.id
+		it.cursor.id = 0
+		return it.rsc.CursorDel(it.ctx, it.fts.indexname, id)
+	}
+
+	return nil
+}
+
+func (it *SearchIterator) fetch() error {
+	if it.cursor.active {
+		return it.readCursor()
+	}
+
+	if it.fetched && it.offset >= it.total {
+		it.done = true
+		return nil
+	}
+
+	num := it.batchSize
+	if it.maxResults > 0 {
+		if it.fts.sortby.attribute == "" {
+			return ErrUnsortedCursorFallback
+		}
+
+		if it.offset >= it.maxResults {
+			return it.startCursor()
+		}
+
+		if it.offset+num > it.maxResults {
+			num = it.maxResults - it.offset
+		}
+	}
+
+	page := it.fts
+	page.AddLimit(it.offset, num)
+
+	res, err := it.rsc.DoSearch(it.ctx, &page)
+	if err != nil {
+		return err
+	}
+
+	it.fetched = true
+	it.total = res.Total
+	it.docs = res.Docs
+	it.offset += int64(len(res.Docs))
+
+	if len(res.Docs) == 0 {
+		it.done = true
+	}
+
+	return nil
+}
+
+func (it *SearchIterator) startCursor() error {
+	fta, err := cursorFallback(&it.fts, it.batchSize)
+	if err != nil {
+		return err
+	}
+
+	res, err := it.rsc.Aggregate(it.ctx, fta)
+	if err != nil {
+		return err
+	}
+
+	it.cursor.active = true
+	it.cursor.skip = it.offset
+
+	return it.cursorPage(res)
+}
+
+func (it *SearchIterator) readCursor() error {
+	if it.cursor.id == 0 {
+		it.done = true
+		return nil
+	}
+
+	res, err := it.rsc.CursorRead(it.ctx, it.fts.indexname, it.cursor.id, int(it.batchSize))
+	if err != nil {
+		return err
+	}
+
+	return it.cursorPage(res)
+}
+
+func (it *SearchIterator) cursorPage(res *AggregateResult) error {
+	it.cursor.id = res.CursorID
+
+	for _, row := range res.Rows {
+		if it.cursor.skip > 0 {
+			it.cursor.skip--
+			continue
+		}
+
+		doc := Document{ID: row["__key"]}
+		delete(row, "__key")
+		if !it.fts.nocontent && len(row) > 0 {
+			doc.Fields = row
+		}
+
+		it.docs = append(it.docs, doc)
+	}
+
+	it.offset += int64(len(it.docs))
+
+	if it.cursor.id == 0 && len(it.docs) == 0 {
+		it.done = true
+	}
+
+	return nil
+}
+
+// cursorFallback converts the search builder to an aggregate that returns the same documents
+func cursorFallback(fts *FtSearch, batchSize int64) (*FtAggregate, error) {
+	if len(fts.inkeys) > 0 || len(fts.infields) > 0 {
+		return nil, errors.New("redisearch: INKEYS and INFIELDS searches can not fall back to a cursor")
+	}
+	if fts.sortby.attribute == "" {
+		return nil, ErrUnsortedCursorFallback
+	}
+
+	query := fts.query
+	if query == "" {
+		query = "*"
+	}
+
+	// FILTER and GEOFILTER become query clauses
+	var clauses []string
+	for _, f := range fts.filters {
+		clauses = append(clauses, "@"+f.field+":["+filterBound(f.min, f.exclusiveMin)+" "+filterBound(f.max, f.exclusiveMax)+"]")
+	}
+
+	if fts.geofilter.field != "" {
+		clauses = append(clauses, "@"+fts.geofilter.field+":["+
+			strconv.FormatFloat(fts.geofilter.lon, 'f', -1, 64)+" "+
+			strconv.FormatFloat(fts.geofilter.lat, 'f', -1, 64)+" "+
+			strconv.FormatFloat(fts.geofilter.radius, 'f', -1, 64)+" "+
+			string(fts.geofilter.unit)+"]")
+	}
+
+	if len(clauses) > 0 {
+		if query == "*" {
+			query = strings.Join(clauses, " ")
+		} else {
+			query = "(" + query + ") " + strings.Join(clauses, " ")
+		}
+	}
+
+	fta := NewFtAggregate(fts.indexname).AddQuery(query).AddVerbatim(fts.verbatim)
+
+	// LOAD * does not return the key, so only RETURN fields are kept after the fallback
+	if !fts.nocontent && len(fts.returnfields) > 0 {
+		fta.AddLoad(append([]string{"__key"}, fts.returnfields...)...)
+	} else {
+		fta.AddLoad("__key")
+	}
+
+	fta.AddSortBy(0, NewSortKey(fts.sortby.attribute, fts.sortby.asc))
+
+	return fta.AddWithCursor(true, int(batchSize), 0), nil
+}
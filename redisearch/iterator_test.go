@@ -0,0 +1,90 @@
+package redisearch
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func Test_cursorFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		fts     *FtSearch
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name: "Sorted Search With Filter",
+			fts: NewFtSearch("index_terms").
+				AddQuery("@cats:{dream}").
+				AddFilter("updated", 1640995200, math.Inf(1), true, false).
+				AddReturnFields("name", "updated").
+				AddSortBy("updated", false),
+			want: []interface{}{
+				"FT.AGGREGATE", "index_terms", "(@cats:{dream}) @updated:[(1640995200 +inf]",
+				"LOAD", 3, "@__key", "@name", "@updated",
+				"SORTBY", 2, "@updated", "DESC",
+				"WITHCURSOR", "COUNT", 500,
+			},
+		},
+		{
+			name: "No Content",
+			fts:  NewFtSearch("index_dreams").AddNoContent(true).AddSortBy("updated", true),
+			want: []interface{}{
+				"FT.AGGREGATE", "index_dreams", "*",
+				"LOAD", 1, "@__key",
+				"SORTBY", 2, "@updated", "ASC",
+				"WITHCURSOR", "COUNT", 500,
+			},
+		},
+		{
+			name:    "Unsorted",
+			fts:     NewFtSearch("index_dreams").AddQuery("dream"),
+			wantErr: true,
+		},
+		{
+			name:    "In Keys",
+			fts:     NewFtSearch("index_dreams").AddInKeys("drd:1"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cursorFallback(tt.fts, 500)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cursorFallback() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if args := got.Serialize(); !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("cursorFallback() = %v, want %v", args, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchIterator_UnsortedFallback(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	it := rsc.NewSearchIterator(context.Background(), NewFtSearch("idx:drd").AddQuery("*"), 2).AddCursorFallback(2)
+	defer it.Close()
+
+	if it.Next() || it.Err() != ErrUnsortedCursorFallback {
+		t.Errorf("Next() error = %v, want ErrUnsortedCursorFallback", it.Err())
+	}
+
+	// without the fallback an unsorted search pages as usual
+	it = rsc.NewSearchIterator(context.Background(), NewFtSearch("idx:drd").AddQuery("*"), 2)
+	defer it.Close()
+
+	var n int
+	for it.Next() {
+		n++
+	}
+	if it.Err() != nil || n != 3 {
+		t.Errorf("iterated %d documents, error %v, want 3", n, it.Err())
+	}
+}
@@ -0,0 +1,341 @@
+package redisearch
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Defaults of NewIndexLock
+const (
+	DefaultLockTTL   = 30 * time.Second
+	DefaultLockWait  = time.Minute
+	DefaultLockRetry = 250 * time.Millisecond
+)
+
+// Lock keys are {prefix}{index} plus {prefix}{index}:fence, the hash tag keeps both on one cluster slot
+var LockKeyPrefix = "redisearch:lock:"
+
+var (
+	// ErrLockNotAcquired is returned when another instance holds the lock longer than the wait
+	ErrLockNotAcquired = errors.New("redisearch: index lock held by another instance")
+
+	// ErrLockLost is returned once the lease expired or was taken over before it was released
+	ErrLockLost = errors.New("redisearch: index lock lost")
+)
+
+// SET NX PX and a fencing token from INCR, 0 when the lock is held
+const acquireLockScript = `
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0
+`
+
+const renewLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+const releaseLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+/*
+IndexLock is a Redis lease lock scoped to an index name, so only one instance changes its schema.
+
+	err := client.NewIndexLock("idx:drd").AddTTL(10*time.Second).Do(ctx, func(ctx context.Context, lease *redisearch.Lease) error {
+		// ctx is cancelled when the lease is lost
+		return migrate(ctx, lease.Fence)
+	})
+
+The lease is renewed every third of its TTL while it is held. Fence grows with every acquisition,
+pass it along with writes to reject those of a holder that lost the lease without noticing.
+*/
+type IndexLock struct {
+	rsc   *RedisearchClient
+	index string
+	key   string
+	ttl   time.Duration
+	wait  time.Duration
+	retry time.Duration
+}
+
+func (rsc *RedisearchClient) NewIndexLock(indexName string) *IndexLock {
+	return &IndexLock{
+		rsc:   rsc,
+		index: indexName,
+		key:   LockKeyPrefix + "{" + indexName + "}",
+		ttl:   DefaultLockTTL,
+		wait:  DefaultLockWait,
+		retry: DefaultLockRetry,
+	}
+}
+
+// AddTTL sets the lease duration, a holder that stops renewing loses the lock after it.
+// Redis expires keys in milliseconds, a ttl under one millisecond keeps DefaultLockTTL.
+func (l *IndexLock) AddTTL(ttl time.Duration) *IndexLock {
+	if ttl < time.Millisecond {
+		ttl = DefaultLockTTL
+	}
+	l.ttl = ttl
+
+	return l
+}
+
+// AddWait sets how long Acquire waits for the lock. 0 tries once, so other instances skip the work.
+func (l *IndexLock) AddWait(wait time.Duration) *IndexLock {
+	l.wait = wait
+
+	return l
+}
+
+// AddRetryInterval sets the pause between two attempts while waiting
+func (l *IndexLock) AddRetryInterval(retry time.Duration) *IndexLock {
+	l.retry = retry
+
+	return l
+}
+
+// Acquire takes the lock, waiting up to the configured wait. Release the lease when done.
+func (l *IndexLock) Acquire(ctx context.Context) (*Lease, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(l.wait)
+	for {
+		fence, err := l.rsc.do(ctx, "EVAL", acquireLockScript, 2, l.key, l.key+":fence", token, l.ttl.Milliseconds()).Int64()
+		if err != nil {
+			return nil, err
+		}
+		if fence > 0 {
+			return l.newLease(ctx, token, fence), nil
+		}
+
+		if time.Now().Add(l.retry).After(deadline) {
+			return nil, ErrLockNotAcquired
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.retry):
+		}
+	}
+}
+
+// Do runs fn while holding the lock. The context of fn is cancelled when the lease is lost.
+func (l *IndexLock) Do(ctx context.Context, fn func(ctx context.Context, lease *Lease) error) error {
+	lease, err := l.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	ferr := fn(lease.Context(), lease)
+	if lerr := lease.Err(); lerr != nil && ferr != nil {
+		ferr = fmt.Errorf("%w: %v", lerr, ferr)
+	}
+
+	rerr := lease.Release(ctx)
+	if ferr != nil {
+		return ferr
+	}
+
+	return rerr
+}
+
+// Lease is a held IndexLock
+type Lease struct {
+	Index string
+	Fence int64 // fencing token, higher than the one of every previous holder
+
+	lock   *IndexLock
+	token  string
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	err      error
+	released bool
+}
+
+func (l *IndexLock) newLease(ctx context.Context, token string, fence int64) *Lease {
+	ls := &Lease{
+		Index: l.index,
+		Fence: fence,
+		lock:  l,
+		token: token,
+		done:  make(chan struct{}),
+	}
+	ls.ctx, ls.cancel = context.WithCancel(contextWithLease(ctx, ls))
+
+	go ls.renew()
+
+	return ls
+}
+
+// Context is cancelled when the lease is lost or released
+func (ls *Lease) Context() context.Context {
+	return ls.ctx
+}
+
+// Err returns ErrLockLost once the lease is lost
+func (ls *Lease) Err() error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	return ls.err
+}
+
+// Check extends the lease and verifies it is still held, call it before a step that must not run twice
+func (ls *Lease) Check(ctx context.Context) error {
+	if err := ls.Err(); err != nil {
+		return err
+	}
+
+	ok, err := ls.extend(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		ls.lose()
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+// Release stops the renewal and deletes the lock if the lease still holds it. It returns ErrLockLost otherwise.
+func (ls *Lease) Release(ctx context.Context) error {
+	ls.mu.Lock()
+	released, lost := ls.released, ls.err
+	ls.released = true
+	ls.mu.Unlock()
+
+	ls.cancel()
+	<-ls.done
+
+	if released {
+		return nil
+	}
+	if lost != nil {
+		return lost
+	}
+
+	l := ls.lock
+	n, err := l.rsc.do(ctx, "EVAL", releaseLockScript, 1, l.key, ls.token).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+
+	return nil
+}
+
+func (ls *Lease) extend(ctx context.Context) (bool, error) {
+	l := ls.lock
+	n, err := l.rsc.do(ctx, "EVAL", renewLockScript, 1, l.key, ls.token, l.ttl.Milliseconds()).Int64()
+
+	return n == 1, err
+}
+
+func (ls *Lease) lose() {
+	ls.mu.Lock()
+	if ls.err == nil {
+		ls.err = ErrLockLost
+	}
+	ls.mu.Unlock()
+
+	ls.cancel()
+}
+
+// renew extends the lease every third of its TTL. Failed renewals are retried until the TTL is over.
+func (ls *Lease) renew() {
+	defer close(ls.done)
+
+	ticker := time.NewTicker(ls.lock.ttl / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-ls.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := ls.extend(ls.ctx)
+		switch {
+		case ls.ctx.Err() != nil:
+			return
+		case err == nil && ok:
+			renewed = time.Now()
+		case err == nil || time.Since(renewed) >= ls.lock.ttl:
+			ls.lose()
+			return
+		}
+	}
+}
+
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+type leaseKey struct{}
+
+func contextWithLease(ctx context.Context, ls *Lease) context.Context {
+	return context.WithValue(ctx, leaseKey{}, ls)
+}
+
+// checkLease verifies the lease ctx runs under, if any, before a schema change
+func checkLease(ctx context.Context) error {
+	ls, ok := ctx.Value(leaseKey{}).(*Lease)
+	if !ok {
+		return nil
+	}
+
+	return ls.Check(ctx)
+}
+
+// EnsureIndexLocked runs EnsureIndex while holding lock, a nil lock is NewIndexLock of the index name.
+// Instances that wait for the lock find the index up to date once the holder is done.
+// With AddWait(0) the instances that do not get the lock return a Skipped result and no error.
+func (rsc *RedisearchClient) EnsureIndexLocked(ctx context.Context, ftc *FtCreate, policy EnsurePolicy, lock *IndexLock) (*EnsureResult, error) {
+	if err := policy.check(); err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		lock = rsc.NewIndexLock(ftc.indexname)
+	}
+
+	var res *EnsureResult
+	err := lock.Do(ctx, func(ctx context.Context, lease *Lease) error {
+		var err error
+		res, err = rsc.EnsureIndex(ctx, ftc, policy)
+		return err
+	})
+	if err == ErrLockNotAcquired && lock.wait <= 0 {
+		return &EnsureResult{Index: ftc.indexname, Skipped: true}, nil
+	}
+
+	return res, err
+}
@@ -0,0 +1,250 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// lockExecutor runs the lock scripts on in-memory keys and forwards every other command
+type lockExecutor struct {
+	next    Executor
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	fences  map[string]int64
+}
+
+func newLockExecutor(next Executor) *lockExecutor {
+	return &lockExecutor{
+		next:    next,
+		values:  make(map[string]string),
+		expires: make(map[string]time.Time),
+		fences:  make(map[string]int64),
+	}
+}
+
+func (e *lockExecutor) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	if replyString(args[0]) != "EVAL" {
+		return e.next.Do(ctx, args...)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := replyString(args[3])
+	if exp, ok := e.expires[key]; ok && time.Now().After(exp) {
+		delete(e.values, key)
+	}
+	val, held := e.values[key]
+
+	var n int64
+	switch args[1] {
+	case acquireLockScript:
+		if !held {
+			e.set(key, replyString(args[5]), args[6].(int64))
+			e.fences[replyString(args[4])]++
+			n = e.fences[replyString(args[4])]
+		}
+	case renewLockScript:
+		if held && val == replyString(args[4]) {
+			e.set(key, val, args[5].(int64))
+			n = 1
+		}
+	case releaseLockScript:
+		if held && val == replyString(args[4]) {
+			delete(e.values, key)
+			n = 1
+		}
+	}
+
+	cmd := redis.NewCmd(ctx, args...)
+	cmd.SetVal(n)
+
+	return cmd
+}
+
+func (e *lockExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	return e.next.Pipeline(ctx, cmds)
+}
+
+func (e *lockExecutor) set(key, val string, ttl int64) {
+	e.values[key] = val
+	e.expires[key] = time.Now().Add(time.Duration(ttl) * time.Millisecond)
+}
+
+// steal hands the lock of index to another owner
+func (e *lockExecutor) steal(index string) {
+	e.mu.Lock()
+	e.values[LockKeyPrefix+"{"+index+"}"] = "thief"
+	e.mu.Unlock()
+}
+
+func TestIndexLock_AddTTL(t *testing.T) {
+	rsc := NewRedisearchClientWithExecutor("test", NewScriptedExecutor())
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{"Zero", 0, DefaultLockTTL},
+		{"Negative", -time.Second, DefaultLockTTL},
+		{"Under A Millisecond", 2 * time.Nanosecond, DefaultLockTTL},
+		{"Millisecond", time.Millisecond, time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rsc.NewIndexLock("idx:drd").AddTTL(tt.ttl).ttl; got != tt.want {
+				t.Errorf("AddTTL(%v) ttl = %v, want %v", tt.ttl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexLock(t *testing.T) {
+	ctx := context.Background()
+	rsc := newTestClient(t)
+	locks := newLockExecutor(rsc.Executor)
+	rsc.Executor = locks
+
+	t.Run("Skip And Fence", func(t *testing.T) {
+		lease, err := rsc.NewIndexLock("idx:a").Acquire(ctx)
+		if err != nil || lease.Fence != 1 {
+			t.Fatalf("Acquire() = %+v, %v, want fence 1", lease, err)
+		}
+
+		if _, err := rsc.NewIndexLock("idx:a").AddWait(0).Acquire(ctx); err != ErrLockNotAcquired {
+			t.Errorf("Acquire() of a held lock error = %v, want ErrLockNotAcquired", err)
+		}
+
+		if err := lease.Release(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		lease, err = rsc.NewIndexLock("idx:a").AddWait(0).Acquire(ctx)
+		if err != nil || lease.Fence != 2 {
+			t.Fatalf("Acquire() after release = %+v, %v, want fence 2", lease, err)
+		}
+		lease.Release(ctx)
+	})
+
+	t.Run("Renewal And Wait", func(t *testing.T) {
+		lease, err := rsc.NewIndexLock("idx:b").AddTTL(60 * time.Millisecond).Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// held past its TTL thanks to the renewal
+		time.Sleep(150 * time.Millisecond)
+		if _, err := rsc.NewIndexLock("idx:b").AddWait(0).Acquire(ctx); err != ErrLockNotAcquired {
+			t.Fatalf("Acquire() of a renewed lock error = %v, want ErrLockNotAcquired", err)
+		}
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			lease.Release(ctx)
+		}()
+
+		waiter, err := rsc.NewIndexLock("idx:b").AddWait(time.Second).AddRetryInterval(10 * time.Millisecond).Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire() with wait error = %v", err)
+		}
+		waiter.Release(ctx)
+	})
+
+	t.Run("Lost", func(t *testing.T) {
+		lease, err := rsc.NewIndexLock("idx:c").AddTTL(30 * time.Millisecond).Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		locks.steal("idx:c")
+
+		select {
+		case <-lease.Context().Done():
+		case <-time.After(time.Second):
+			t.Fatal("lease context not cancelled after the lock was taken over")
+		}
+		if err := lease.Release(ctx); err != ErrLockLost {
+			t.Errorf("Release() error = %v, want ErrLockLost", err)
+		}
+	})
+
+	t.Run("Ensure Index", func(t *testing.T) {
+		ftc := seedIndexDefinition()
+
+		var wg sync.WaitGroup
+		results := make([]*EnsureResult, 4)
+		errs := make([]error, 4)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				lock := rsc.NewIndexLock(ftc.IndexName()).AddRetryInterval(5 * time.Millisecond)
+				results[i], errs[i] = rsc.EnsureIndexLocked(ctx, ftc, EnsureRebuild, lock)
+			}(i)
+		}
+		wg.Wait()
+
+		var created int
+		for i, res := range results {
+			if errs[i] != nil {
+				t.Fatalf("EnsureIndexLocked() error = %v", errs[i])
+			}
+			if res.Created {
+				created++
+			}
+			if res.Index != "idx:drd_v1" {
+				t.Errorf("EnsureIndexLocked() index = %s, want idx:drd_v1", res.Index)
+			}
+		}
+		if created != 1 {
+			t.Errorf("EnsureIndexLocked() created the index %d times, want once", created)
+		}
+	})
+
+	t.Run("Ensure Index Skipped", func(t *testing.T) {
+		ftc := seedIndexDefinition()
+
+		lease, err := rsc.NewIndexLock(ftc.IndexName()).Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer lease.Release(ctx)
+
+		// losing the lock with no wait is not an error
+		res, err := rsc.EnsureIndexLocked(ctx, ftc, EnsureAlter, rsc.NewIndexLock(ftc.IndexName()).AddWait(0))
+		if err != nil || !res.Skipped || res.Created || res.Index != ftc.IndexName() {
+			t.Errorf("EnsureIndexLocked() = %+v, %v, want a skipped result", res, err)
+		}
+
+		// a wait that runs out still is
+		lock := rsc.NewIndexLock(ftc.IndexName()).AddWait(20 * time.Millisecond).AddRetryInterval(5 * time.Millisecond)
+		if _, err := rsc.EnsureIndexLocked(ctx, ftc, EnsureAlter, lock); err != ErrLockNotAcquired {
+			t.Errorf("EnsureIndexLocked() error = %v, want ErrLockNotAcquired", err)
+		}
+	})
+
+	t.Run("Lease Checked Before Schema Changes", func(t *testing.T) {
+		ftc := seedIndexDefinition()
+		ftc.AddSchema(FieldTypeText, "slug", "", false, ftc.AddSchemaTextOption(0, false, false, ""))
+
+		err := rsc.NewIndexLock("idx:drd").Do(ctx, func(ctx context.Context, lease *Lease) error {
+			locks.steal("idx:drd")
+			_, err := rsc.EnsureIndex(ctx, ftc, EnsureAlter)
+			return err
+		})
+		if !errors.Is(err, ErrLockLost) {
+			t.Errorf("Do() error = %v, want ErrLockLost", err)
+		}
+
+		if info, _ := rsc.Info(ctx, "idx:drd"); info.Attribute("slug") != nil {
+			t.Error("EnsureIndex() altered the index without the lock")
+		}
+	})
+}
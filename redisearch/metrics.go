@@ -0,0 +1,177 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default histogram buckets, same as the Prometheus client defaults
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+type latencyKey struct {
+	index   string
+	command string
+}
+
+type latencySeries struct {
+	buckets []uint64 // cumulative counts are computed on export
+	count   uint64
+	sum     time.Duration
+	errors  uint64
+	results int64
+}
+
+// LatencyHistogram is a Hook that keeps latency histograms per index and command.
+// It serves them in the Prometheus text format:
+//
+//	metrics := redisearch.NewLatencyHistogram()
+//	client.AddHook(metrics)
+//	http.Handle("/metrics", metrics)
+type LatencyHistogram struct {
+	buckets []time.Duration
+	mu      sync.Mutex
+	series  map[latencyKey]*latencySeries
+}
+
+// NewLatencyHistogram uses DefaultLatencyBuckets when no buckets are given
+func NewLatencyHistogram(buckets ...time.Duration) *LatencyHistogram {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+
+	sorted := append([]time.Duration(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &LatencyHistogram{
+		buckets: sorted,
+		series:  make(map[latencyKey]*latencySeries),
+	}
+}
+
+func (lh *LatencyHistogram) BeforeCommand(ctx context.Context, info *CommandInfo) context.Context {
+	return ctx
+}
+
+func (lh *LatencyHistogram) AfterCommand(ctx context.Context, info *CommandInfo) {
+	key := latencyKey{index: info.Index, command: info.Command}
+
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	s, ok := lh.series[key]
+	if !ok {
+		s = &latencySeries{buckets: make([]uint64, len(lh.buckets))}
+		lh.series[key] = s
+	}
+
+	for i, b := range lh.buckets {
+		if info.Duration <= b {
+			s.buckets[i]++
+			break
+		}
+	}
+
+	s.count++
+	s.sum += info.Duration
+
+	if info.Err != nil {
+		s.errors++
+	}
+
+	if info.Results > 0 {
+		s.results += info.Results
+	}
+}
+
+// Reset drops all recorded series
+func (lh *LatencyHistogram) Reset() {
+	lh.mu.Lock()
+	lh.series = make(map[latencyKey]*latencySeries)
+	lh.mu.Unlock()
+}
+
+// ServeHTTP writes all series in the Prometheus text exposition format
+func (lh *LatencyHistogram) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(lh.String()))
+}
+
+// String returns the Prometheus text format of all series, sorted by index and command
+func (lh *LatencyHistogram) String() string {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+
+	keys := make([]latencyKey, 0, len(lh.series))
+	for k := range lh.series {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].index != keys[j].index {
+			return keys[i].index < keys[j].index
+		}
+		return keys[i].command < keys[j].command
+	})
+
+	var sb strings.Builder
+
+	sb.WriteString("# HELP redisearch_command_duration_seconds Duration of RediSearch commands.\n")
+	sb.WriteString("# TYPE redisearch_command_duration_seconds histogram\n")
+	for _, k := range keys {
+		s := lh.series[k]
+		labels := metricLabels(k)
+
+		var cumulative uint64
+		for i, b := range lh.buckets {
+			cumulative += s.buckets[i]
+			fmt.Fprintf(&sb, "redisearch_command_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatSeconds(b), cumulative)
+		}
+		fmt.Fprintf(&sb, "redisearch_command_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count)
+		fmt.Fprintf(&sb, "redisearch_command_duration_seconds_sum{%s} %s\n", labels, formatSeconds(s.sum))
+		fmt.Fprintf(&sb, "redisearch_command_duration_seconds_count{%s} %d\n", labels, s.count)
+	}
+
+	sb.WriteString("# HELP redisearch_command_errors_total Failed RediSearch commands.\n")
+	sb.WriteString("# TYPE redisearch_command_errors_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "redisearch_command_errors_total{%s} %d\n", metricLabels(k), lh.series[k].errors)
+	}
+
+	sb.WriteString("# HELP redisearch_command_results_total Results returned by RediSearch commands.\n")
+	sb.WriteString("# TYPE redisearch_command_results_total counter\n")
+	for _, k := range keys {
+		fmt.Fprintf(&sb, "redisearch_command_results_total{%s} %d\n", metricLabels(k), lh.series[k].results)
+	}
+
+	return sb.String()
+}
+
+func metricLabels(k latencyKey) string {
+	return `index="` + escapeLabel(k.index) + `",command="` + escapeLabel(k.command) + `"`
+}
+
+// escapeLabel escapes backslash, double quote and line feed
+func escapeLabel(val string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(val)
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
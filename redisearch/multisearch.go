@@ -0,0 +1,212 @@
+package redisearch
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Score normalization applied per index before results are interleaved
+const (
+	NormalizeNone = iota // raw scores, only comparable when all indexes use the same scorer and similar data
+	NormalizeMax         // score divided by the best score of its index, so every index tops at 1
+)
+
+// Document of a MultiSearch result with the index it came from
+type MultiDocument struct {
+	Document
+	Index           string
+	NormalizedScore float64
+}
+
+// Merged MultiSearch reply. Total is the sum of the index totals, documents found
+// in several indexes are counted once per index.
+type MultiSearchResult struct {
+	Total int64
+	Docs  []MultiDocument
+}
+
+// MultiSearch runs searches on several indexes concurrently and interleaves their results.
+//
+//	res, err := client.NewMultiSearch().
+//		AddIndexes(redisearch.NewFtSearch("").AddQuery("dream"), "index_dreams", "index_terms").
+//		AddLimit(20, 10).
+//		Do(ctx)
+type MultiSearch struct {
+	rsc            *RedisearchClient
+	searches       []*FtSearch
+	normalization  int
+	less           func(a, b MultiDocument) bool
+	keepDuplicates bool
+	limit          struct {
+		offset int64
+		num    int64
+	}
+}
+
+func (rsc *RedisearchClient) NewMultiSearch() *MultiSearch {
+	ms := &MultiSearch{
+		rsc:           rsc,
+		normalization: NormalizeMax,
+	}
+	ms.limit.num = 10
+
+	return ms
+}
+
+// AddSearch adds a per index search variant. Its LIMIT is replaced by the global limit.
+func (ms *MultiSearch) AddSearch(fts *FtSearch) *MultiSearch {
+	ms.searches = append(ms.searches, fts)
+
+	return ms
+}
+
+// AddIndexes runs a copy of the same search on every index
+func (ms *MultiSearch) AddIndexes(fts *FtSearch, indexNames ...string) *MultiSearch {
+	for _, name := range indexNames {
+		clone := *fts
+		ms.searches = append(ms.searches, clone.AddIndexName(name))
+	}
+
+	return ms
+}
+
+// AddNormalization sets NormalizeNone or NormalizeMax (default)
+func (ms *MultiSearch) AddNormalization(normalization int) *MultiSearch {
+	ms.normalization = normalization
+
+	return ms
+}
+
+// AddComparator interleaves by less instead of the normalized score. Every search should be
+// sorted (AddSortBy) in the same order as less, otherwise the global limit can miss documents.
+func (ms *MultiSearch) AddComparator(less func(a, b MultiDocument) bool) *MultiSearch {
+	ms.less = less
+
+	return ms
+}
+
+// AddKeepDuplicates keeps a document once per index. By default only its best ranked copy is kept.
+func (ms *MultiSearch) AddKeepDuplicates(active bool) *MultiSearch {
+	ms.keepDuplicates = active
+
+	return ms
+}
+
+// AddLimit sets the window of the merged results
+func (ms *MultiSearch) AddLimit(offset, num int64) *MultiSearch {
+	ms.limit.offset = offset
+	ms.limit.num = num
+
+	return ms
+}
+
+// Do runs the searches and returns the requested window of the merged results.
+// Every index is asked for its first offset+num documents. An index never returns the same
+// document twice, so deduplication can not shrink the merged list below offset+num while
+// an index still has more documents.
+func (ms *MultiSearch) Do(ctx context.Context) (*MultiSearchResult, error) {
+	if len(ms.searches) == 0 || ms.limit.num <= 0 {
+		return &MultiSearchResult{}, nil
+	}
+
+	results, err := ms.run(ctx, ms.limit.offset+ms.limit.num)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, res := range results {
+		total += res.Total
+	}
+
+	return &MultiSearchResult{
+		Total: total,
+		Docs:  window(ms.merge(results), ms.limit.offset, ms.limit.num),
+	}, nil
+}
+
+// run sends every search with WITHSCORES and LIMIT 0 num concurrently
+func (ms *MultiSearch) run(ctx context.Context, num int64) ([]*SearchResult, error) {
+	results := make([]*SearchResult, len(ms.searches))
+	errs := make([]error, len(ms.searches))
+
+	var wg sync.WaitGroup
+	for i, fts := range ms.searches {
+		clone := *fts
+		clone.AddWithScores(true).AddLimit(0, num)
+
+		wg.Add(1)
+		go func(i int, fts *FtSearch) {
+			defer wg.Done()
+			results[i], errs[i] = ms.rsc.DoSearch(ctx, fts)
+		}(i, &clone)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// merge normalizes, sorts and dedupes the documents of all indexes
+func (ms *MultiSearch) merge(results []*SearchResult) []MultiDocument {
+	var docs []MultiDocument
+	for i, res := range results {
+		var best float64
+		for _, doc := range res.Docs {
+			if doc.Score > best {
+				best = doc.Score
+			}
+		}
+
+		for _, doc := range res.Docs {
+			md := MultiDocument{Document: doc, Index: ms.searches[i].indexname, NormalizedScore: doc.Score}
+			if ms.normalization == NormalizeMax && best > 0 {
+				md.NormalizedScore = doc.Score / best
+			}
+			docs = append(docs, md)
+		}
+	}
+
+	// stable sort keeps the order of each index and the search order on ties
+	sort.SliceStable(docs, func(i, j int) bool {
+		if ms.less != nil {
+			return ms.less(docs[i], docs[j])
+		}
+		return docs[i].NormalizedScore > docs[j].NormalizedScore
+	})
+
+	if ms.keepDuplicates {
+		return docs
+	}
+
+	seen := make(map[string]bool, len(docs))
+	unique := docs[:0]
+	for _, doc := range docs {
+		if seen[doc.ID] {
+			continue
+		}
+		seen[doc.ID] = true
+		unique = append(unique, doc)
+	}
+
+	return unique
+}
+
+func window(docs []MultiDocument, offset, num int64) []MultiDocument {
+	if offset >= int64(len(docs)) {
+		return nil
+	}
+
+	end := offset + num
+	if end > int64(len(docs)) {
+		end = int64(len(docs))
+	}
+
+	return docs[offset:end]
+}
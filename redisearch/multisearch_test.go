@@ -0,0 +1,100 @@
+package redisearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMultiSearch_Do(t *testing.T) {
+	rsc := newTestClient(t)
+
+	for _, def := range []struct {
+		name   string
+		prefix string
+	}{
+		{name: "idx:dreams", prefix: "dr:"},
+		{name: "idx:terms", prefix: "te:"},
+		{name: "idx:copy", prefix: "dr:"},
+	} {
+		ftc := NewFtCreate(def.name).AddDataType(HASH).AddPrefix(def.prefix)
+		ftc.AddSchema(FieldTypeText, "name", "", false, ftc.AddSchemaTextOption(1, false, false, "")).
+			AddSchema(FieldTypeNumeric, "updated", "", true, ftc.AddSchemaNumericOption(false))
+		if _, err := rsc.Create(def.name, ftc.Serialize()...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// fake server score: number of matching terms
+	for key, name := range map[string]string{
+		"dr:1": "dream dream dream dream",
+		"dr:2": "dream",
+		"te:1": "dream dream",
+		"te:2": "dream and more",
+	} {
+		if _, err := rsc.HSet(key, "name", name, "updated", len(key)+len(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	base := NewFtSearch("").AddQuery("dream").AddNoContent(true)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		ms        *MultiSearch
+		wantIDs   []string
+		wantTotal int64
+	}{
+		{
+			name:      "Normalized And Deduped",
+			ms:        rsc.NewMultiSearch().AddIndexes(base, "idx:dreams", "idx:terms", "idx:copy"),
+			wantIDs:   []string{"dr:1", "te:1", "te:2", "dr:2"},
+			wantTotal: 6,
+		},
+		{
+			name:    "Global Window",
+			ms:      rsc.NewMultiSearch().AddIndexes(base, "idx:dreams", "idx:terms", "idx:copy").AddLimit(1, 2),
+			wantIDs: []string{"te:1", "te:2"},
+		},
+		{
+			name:    "Raw Scores",
+			ms:      rsc.NewMultiSearch().AddIndexes(base, "idx:dreams", "idx:terms").AddNormalization(NormalizeNone),
+			wantIDs: []string{"dr:1", "te:1", "dr:2", "te:2"},
+		},
+		{
+			name:    "Keep Duplicates",
+			ms:      rsc.NewMultiSearch().AddIndexes(base, "idx:dreams", "idx:copy").AddKeepDuplicates(true).AddLimit(0, 3),
+			wantIDs: []string{"dr:1", "dr:1", "dr:2"},
+		},
+		{
+			name: "Comparator",
+			ms: rsc.NewMultiSearch().
+				AddSearch(NewFtSearch("idx:dreams").AddQuery("dream").AddNoContent(true).AddSortBy("updated", true)).
+				AddSearch(NewFtSearch("idx:terms").AddQuery("dream").AddNoContent(true).AddSortBy("updated", true)).
+				AddComparator(func(a, b MultiDocument) bool { return a.ID < b.ID }),
+			wantIDs: []string{"dr:1", "dr:2", "te:1", "te:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ms.Do(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ids []string
+			for _, doc := range got.Docs {
+				ids = append(ids, doc.ID)
+			}
+
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("Do() ids = %v, want %v", ids, tt.wantIDs)
+			}
+
+			if tt.wantTotal > 0 && got.Total != tt.wantTotal {
+				t.Errorf("Do() total = %d, want %d", got.Total, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package redisearch
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// NumericRange is the interval of FILTER {attribute} {min} {max} and @attribute:[{min} {max}].
+// Bounds are inclusive unless marked exclusive, math.Inf bounds are rendered as -inf and +inf.
+//
+//	NewNumericRange(9.99, 19.99)                             // [9.99 19.99]
+//	NewNumericRange(100, math.Inf(1)).Exclusive(true, false) // [(100 +inf]
+//	NewTimeRangeSince(365 * 24 * time.Hour)                  // updated within the last year
+type NumericRange struct {
+	Min          float64
+	Max          float64
+	ExclusiveMin bool
+	ExclusiveMax bool
+}
+
+func NewNumericRange(min, max float64) NumericRange {
+	return NumericRange{Min: min, Max: max}
+}
+
+// NewTimeRange covers from..to in unix seconds, a zero time leaves that side open
+func NewTimeRange(from, to time.Time) NumericRange {
+	r := NumericRange{Min: math.Inf(-1), Max: math.Inf(1)}
+	if !from.IsZero() {
+		r.Min = float64(from.Unix())
+	}
+	if !to.IsZero() {
+		r.Max = float64(to.Unix())
+	}
+
+	return r
+}
+
+// NewTimeRangeSince covers the last d until now and later, in unix seconds
+func NewTimeRangeSince(d time.Duration) NumericRange {
+	return NewTimeRange(time.Now().Add(-d), time.Time{})
+}
+
+// Exclusive marks the bounds as exclusive, infinite bounds stay as they are
+func (r NumericRange) Exclusive(min, max bool) NumericRange {
+	r.ExclusiveMin = min
+	r.ExclusiveMax = max
+
+	return r
+}
+
+// String returns the query syntax: [min max]
+func (r NumericRange) String() string {
+	return "[" + filterBound(r.Min, r.ExclusiveMin) + " " + filterBound(r.Max, r.ExclusiveMax) + "]"
+}
+
+// args returns the bounds of a FILTER argument
+func (r NumericRange) args() (string, string) {
+	return filterBound(r.Min, r.ExclusiveMin), filterBound(r.Max, r.ExclusiveMax)
+}
+
+// filterBound renders a ZRANGE style bound without losing decimals
+func filterBound(val float64, exclusive bool) string {
+	switch {
+	case math.IsInf(val, 1):
+		return "+inf"
+	case math.IsInf(val, -1):
+		return "-inf"
+	}
+
+	bound := strconv.FormatFloat(val, 'f', -1, 64)
+	if exclusive {
+		return "(" + bound
+	}
+
+	return bound
+}
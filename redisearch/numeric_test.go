@@ -0,0 +1,164 @@
+package redisearch
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNumericRange_String(t *testing.T) {
+	tests := []struct {
+		name string
+		r    NumericRange
+		want string
+	}{
+		{
+			name: "Decimals",
+			r:    NewNumericRange(9.99, 19.99),
+			want: "[9.99 19.99]",
+		},
+		{
+			name: "Exclusive Bounds",
+			r:    NewNumericRange(100, 200).Exclusive(true, true),
+			want: "[(100 (200]",
+		},
+		{
+			name: "Infinite Bounds",
+			r:    NewNumericRange(math.Inf(-1), math.Inf(1)).Exclusive(true, true),
+			want: "[-inf +inf]",
+		},
+		{
+			name: "Greater Than",
+			r:    NewNumericRange(0.5, math.Inf(1)).Exclusive(true, false),
+			want: "[(0.5 +inf]",
+		},
+		{
+			name: "Time Range",
+			r:    NewTimeRange(time.Unix(1640995200, 0), time.Unix(1672531199, 0)),
+			want: "[1640995200 1672531199]",
+		},
+		{
+			name: "Open Time Range",
+			r:    NewTimeRange(time.Time{}, time.Unix(1672531199, 0)),
+			want: "[-inf 1672531199]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTimeRangeSince(t *testing.T) {
+	before := time.Now().Add(-time.Hour).Unix()
+	r := NewTimeRangeSince(time.Hour)
+	after := time.Now().Add(-time.Hour).Unix()
+
+	if r.Min < float64(before) || r.Min > float64(after) || !math.IsInf(r.Max, 1) {
+		t.Errorf("NewTimeRangeSince(1h) = %+v, want [%d..%d +inf]", r, before, after)
+	}
+}
+
+func TestFtSearch_Serialize_Filters(t *testing.T) {
+	tests := []struct {
+		name string
+		fts  *FtSearch
+		want []interface{}
+	}{
+		{
+			name: "Float Filter",
+			fts:  NewFtSearch("idx:drd").AddQuery("*").AddNumericFilter("price", NewNumericRange(9.99, 19.5)),
+			want: []interface{}{"FT.SEARCH", "idx:drd", "*", "FILTER", "price", "9.99", "19.5", "LIMIT", 0, 10},
+		},
+		{
+			name: "Infinite Filter",
+			fts:  NewFtSearch("idx:drd").AddQuery("*").AddFilter("updated", math.Inf(-1), math.Inf(1), false, false),
+			want: []interface{}{"FT.SEARCH", "idx:drd", "*", "FILTER", "updated", "-inf", "+inf", "LIMIT", 0, 10},
+		},
+		{
+			name: "Exclusive Filter",
+			fts:  NewFtSearch("idx:drd").AddQuery("*").AddFilter("updated", 100, 200.25, true, true),
+			want: []interface{}{"FT.SEARCH", "idx:drd", "*", "FILTER", "updated", "(100", "(200.25", "LIMIT", 0, 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fts.Serialize(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFtQuery_NumericAndGeo(t *testing.T) {
+	tests := []struct {
+		name string
+		ftq  *FtQuery
+		want string
+	}{
+		{
+			name: "Infinite Min",
+			ftq:  NewFtQuery("").AddNumericFilterQuery(false, "updated", 0, 100, false, false, true, false),
+			want: "@updated:[-inf 100]",
+		},
+		{
+			name: "Infinite Max",
+			ftq:  NewFtQuery("").AddNumericFilterQuery(true, "updated", 100, 0, true, false, false, true),
+			want: "-@updated:[(100 +inf]",
+		},
+		{
+			name: "Float Range",
+			ftq:  NewFtQuery("").AddNumericRangeQuery(false, "price", NewNumericRange(9.99, 10.01)),
+			want: "@price:[9.99 10.01]",
+		},
+		{
+			name: "Geo Decimals",
+			ftq:  NewFtQuery("").AddGeoFilterQuery(false, "location", 28.97953, 41.015137, 2.5, KILOMETERS),
+			want: "@location:[28.97953 41.015137 2.5 km]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ftq.Serialize(); got != tt.want {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedisearchClient_DoSearch_FloatFilter(t *testing.T) {
+	rsc := newTestClient(t)
+
+	ftc := NewFtCreate("idx:prc").AddDataType(HASH).AddPrefix("prc:")
+	ftc.AddSchema(FieldTypeNumeric, "price", "", true, ftc.AddSchemaNumericOption(false))
+	if _, err := rsc.Create(ftc.indexname, ftc.Serialize()...); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, price := range []float64{9.5, 9.99, 10, 10.49} {
+		if _, err := rsc.HSet("prc:"+strconv.Itoa(i+1), "price", price); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// %.f would have sent FILTER price 10 10 and found only prc:3
+	res, err := rsc.DoSearch(context.Background(), NewFtSearch("idx:prc").AddQuery("*").AddNoContent(true).
+		AddNumericFilter("price", NewNumericRange(9.99, 10.49).Exclusive(false, true)).AddSortBy("price", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, doc := range res.Docs {
+		ids = append(ids, doc.ID)
+	}
+	if want := []string{"prc:2", "prc:3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("DoSearch() IDs = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,246 @@
+// Via: https://oss.redis.com/redisearch/Commands/#ftprofile
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+/*
+FT.PROFILE {index} {[SEARCH, AGGREGATE]} [LIMITED] QUERY {query} [query options ...]
+
+Performs a FT.SEARCH or FT.AGGREGATE command and collects performance information.
+LIMITED removes details of reader iterators and summarizes big unions.
+
+Reply: [results, profile]
+  1) Total profile time
+  2) Parsing time
+  3) Pipeline creation time
+  4) Iterators profile
+  5) Result processors profile
+*/
+
+// FT.PROFILE timing breakdown
+type Profile struct {
+	TotalTime            time.Duration
+	ParsingTime          time.Duration
+	PipelineCreationTime time.Duration
+	Iterators            *ProfileIterator
+	ResultProcessors     []ProfileProcessor
+}
+
+// Query iterator node with its own time and counters
+type ProfileIterator struct {
+	Type      string // INTERSECT, UNION, TEXT, TAG, NUMERIC, WILDCARD ...
+	QueryType string // UNION type: PREFIX, FUZZY, TAG, NUMERIC ...
+	Term      string
+	Time      time.Duration
+	Counter   int64
+	Size      int64
+	Extra     map[string]string // keys this client does not know yet
+	Children  []*ProfileIterator
+}
+
+// Single step of the result processor chain
+type ProfileProcessor struct {
+	Type    string // Index, Scorer, Sorter, Loader, Highlighter ...
+	Time    time.Duration
+	Counter int64
+}
+
+// ProfileSearch profiles the search builder and returns its typed results with the profile.
+// Like DoSearch, a search with an attached schema is validated first.
+func (rsc *RedisearchClient) ProfileSearch(ctx context.Context, fts *FtSearch, limited bool) (*SearchResult, *Profile, error) {
+	if err := fts.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	query := fts.query
+	if query == "" {
+		query = "*"
+	}
+
+	args := profileArgs(fts.indexname, "SEARCH", query, limited, fts.serializeOptions())
+
+	reply, err := rsc.do(ctx, args...).Slice()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(reply) != 2 {
+		return nil, nil, errors.New("redisearch: unexpected profile reply")
+	}
+
+	results, ok := reply[0].([]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("redisearch: unexpected search reply type %T", reply[0])
+	}
+
+	res, err := parseSearchResult(results, fts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile, err := parseProfile(reply[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res, profile, nil
+}
+
+// ProfileAggregate profiles the aggregate builder and returns its typed results with the profile
+func (rsc *RedisearchClient) ProfileAggregate(ctx context.Context, fta *FtAggregate, limited bool) (*AggregateResult, *Profile, error) {
+	args := profileArgs(fta.indexname, "AGGREGATE", fta.queryString(), limited, fta.serializeOptions())
+
+	reply, err := rsc.do(ctx, args...).Slice()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(reply) != 2 {
+		return nil, nil, errors.New("redisearch: unexpected profile reply")
+	}
+
+	res, err := parseAggregateResult(reply[0], fta.withcursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	profile, err := parseProfile(reply[1])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return res, profile, nil
+}
+
+func profileArgs(indexName string, cmd string, query string, limited bool, options []interface{}) []interface{} {
+	args := []interface{}{"FT.PROFILE", indexName, cmd}
+	if limited {
+		args = append(args, "LIMITED")
+	}
+	args = append(args, "QUERY", query)
+
+	return append(args, options...)
+}
+
+func parseProfile(reply interface{}) (*Profile, error) {
+	items, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redisearch: unexpected profile type %T", reply)
+	}
+
+	profile := &Profile{}
+	for _, item := range items {
+		entry, ok := item.([]interface{})
+		if !ok || len(entry) < 2 {
+			continue
+		}
+
+		switch replyString(entry[0]) {
+		case "Total profile time":
+			profile.TotalTime = replyMilliseconds(entry[1])
+		case "Parsing time":
+			profile.ParsingTime = replyMilliseconds(entry[1])
+		case "Pipeline creation time":
+			profile.PipelineCreationTime = replyMilliseconds(entry[1])
+		case "Iterators profile":
+			if it, ok := entry[1].([]interface{}); ok {
+				profile.Iterators = parseProfileIterator(it)
+			}
+		case "Result processors profile":
+			for _, p := range entry[1:] {
+				if fields, ok := p.([]interface{}); ok {
+					profile.ResultProcessors = append(profile.ResultProcessors, parseProfileProcessor(fields))
+				}
+			}
+		}
+	}
+
+	return profile, nil
+}
+
+// parseProfileIterator reads [key value ...] pairs. "Child iterators" is followed by
+// every child as a separate element, "Child iterator" by a single child.
+func parseProfileIterator(fields []interface{}) *ProfileIterator {
+	it := &ProfileIterator{}
+
+	for i := 0; i < len(fields); i++ {
+		key := replyString(fields[i])
+
+		if key == "Child iterators" || key == "Child iterator" {
+			for _, c := range fields[i+1:] {
+				switch child := c.(type) {
+				case []interface{}:
+					it.Children = append(it.Children, parseProfileIterator(child))
+				default:
+					// LIMITED mode: "The number of iterators in the union is 3"
+					if it.Extra == nil {
+						it.Extra = make(map[string]string)
+					}
+					it.Extra[key] = replyString(child)
+				}
+			}
+
+			return it
+		}
+
+		if i+1 >= len(fields) {
+			break
+		}
+		val := fields[i+1]
+		i++
+
+		switch key {
+		case "Type":
+			it.Type = replyString(val)
+		case "Query type":
+			it.QueryType = replyString(val)
+		case "Term":
+			it.Term = replyString(val)
+		case "Time":
+			it.Time = replyMilliseconds(val)
+		case "Counter":
+			it.Counter, _ = replyInt64(val)
+		case "Size":
+			it.Size, _ = replyInt64(val)
+		default:
+			if it.Extra == nil {
+				it.Extra = make(map[string]string)
+			}
+			it.Extra[key] = replyString(val)
+		}
+	}
+
+	return it
+}
+
+func parseProfileProcessor(fields []interface{}) ProfileProcessor {
+	var p ProfileProcessor
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		switch replyString(fields[i]) {
+		case "Type":
+			p.Type = replyString(fields[i+1])
+		case "Time":
+			p.Time = replyMilliseconds(fields[i+1])
+		case "Counter":
+			p.Counter, _ = replyInt64(fields[i+1])
+		}
+	}
+
+	return p
+}
+
+// Profile times are reported as milliseconds with fractions
+func replyMilliseconds(v interface{}) time.Duration {
+	ms, err := replyFloat64(v)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(ms * float64(time.Millisecond))
+}
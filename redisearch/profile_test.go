@@ -0,0 +1,100 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_parseProfile(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{"Total profile time", "0.5"},
+		[]interface{}{"Parsing time", "0.25"},
+		[]interface{}{"Pipeline creation time", "0.01"},
+		[]interface{}{"Iterators profile", []interface{}{
+			"Type", "INTERSECT", "Time", "0.1", "Counter", int64(1), "Child iterators",
+			[]interface{}{"Type", "TEXT", "Term", "dream", "Time", "0.02", "Counter", int64(3), "Size", int64(3)},
+			[]interface{}{"Type", "UNION", "Query type", "PREFIX - dre", "Time", "0.03", "Counter", int64(2), "Child iterators", "The number of iterators in the union is 4"},
+		}},
+		[]interface{}{"Result processors profile",
+			[]interface{}{"Type", "Index", "Time", "0.04", "Counter", int64(1)},
+			[]interface{}{"Type", "Sorter", "Time", "0.05", "Counter", int64(1)},
+		},
+	}
+
+	want := &Profile{
+		TotalTime:            500 * time.Microsecond,
+		ParsingTime:          250 * time.Microsecond,
+		PipelineCreationTime: 10 * time.Microsecond,
+		Iterators: &ProfileIterator{
+			Type:    "INTERSECT",
+			Time:    100 * time.Microsecond,
+			Counter: 1,
+			Children: []*ProfileIterator{
+				{Type: "TEXT", Term: "dream", Time: 20 * time.Microsecond, Counter: 3, Size: 3},
+				{
+					Type:      "UNION",
+					QueryType: "PREFIX - dre",
+					Time:      30 * time.Microsecond,
+					Counter:   2,
+					Extra:     map[string]string{"Child iterators": "The number of iterators in the union is 4"},
+				},
+			},
+		},
+		ResultProcessors: []ProfileProcessor{
+			{Type: "Index", Time: 40 * time.Microsecond, Counter: 1},
+			{Type: "Sorter", Time: 50 * time.Microsecond, Counter: 1},
+		},
+	}
+
+	got, err := parseProfile(reply)
+	if err != nil {
+		t.Fatalf("parseProfile() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProfile() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_parseSearchResult(t *testing.T) {
+	fts := NewFtSearch("index_dreams").AddQuery("dream").AddWithScores(true)
+
+	reply := []interface{}{
+		int64(2),
+		"drd:1", "2.5", []interface{}{"name", "Dream Test 1", "updated", "1650000000"},
+		"drd:2", "1", []interface{}{"name", "Dream Test 2"},
+	}
+
+	want := &SearchResult{
+		Total: 2,
+		Docs: []Document{
+			{ID: "drd:1", Score: 2.5, Fields: map[string]string{"name": "Dream Test 1", "updated": "1650000000"}},
+			{ID: "drd:2", Score: 1, Fields: map[string]string{"name": "Dream Test 2"}},
+		},
+	}
+
+	got, err := parseSearchResult(reply, fts)
+	if err != nil {
+		t.Fatalf("parseSearchResult() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSearchResult() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRedisearchClient_ProfileSearchValidate(t *testing.T) {
+	recorder := NewRecordingExecutor(nil)
+	rsc := NewRedisearchClientWithExecutor("test", recorder)
+
+	fts := NewFtSearch("idx:drd").AddQuery("@slug:dream").AddIndexDefinition(testIndexDefinition())
+
+	var verr *SearchValidationError
+	if _, _, err := rsc.ProfileSearch(context.Background(), fts, false); !errors.As(err, &verr) {
+		t.Errorf("ProfileSearch() error = %v, want a *SearchValidationError", err)
+	}
+	if sent := recorder.Commands(); len(sent) != 0 {
+		t.Errorf("ProfileSearch() sent %v, want nothing", sent)
+	}
+}
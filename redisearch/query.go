@@ -1,7 +1,8 @@
 package redisearch
 
 import (
-	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
@@ -118,32 +119,26 @@ func (ftq *FtQuery) AddPureNegativeQuery(query string) *FtQuery {
 // Numeric filters are inclusive. Exclusive min or max are expressed with ( prepended to the number, e.g. [(100 (200]
 // It is possible to negate a numeric filter by prepending a - sign to the filter, e.g. returning a result where price differs from 100 is expressed as: @title:foo -@price:[100 100]
 func (ftq *FtQuery) AddNumericFilterQuery(isNegative bool, field string, min, max int64, excludeMin, excludeMax bool, infMin, infMax bool) *FtQuery {
-	var nfq string
-
-	if isNegative {
-		nfq += "-"
+	r := NewNumericRange(float64(min), float64(max)).Exclusive(excludeMin, excludeMax)
+	if infMin {
+		r.Min = math.Inf(-1)
+	}
+	if infMax {
+		r.Max = math.Inf(1)
 	}
 
-	nfq += "@" + field + ":["
+	return ftq.AddNumericRangeQuery(isNegative, field, r)
+}
 
-	if excludeMin {
-		nfq += fmt.Sprintf("(%d", min)
-	} else if infMin {
-		nfq += "inf"
-	} else {
-		nfq += fmt.Sprintf("%d", min)
-	}
+// @field:[{min} {max}] with float bounds, see NumericRange
+func (ftq *FtQuery) AddNumericRangeQuery(isNegative bool, field string, r NumericRange) *FtQuery {
+	var nfq string
 
-	nfq += " "
-	if excludeMax {
-		nfq += fmt.Sprintf("(%d", max)
-	} else if infMax {
-		nfq += "inf"
-	} else {
-		nfq += fmt.Sprintf("%d", max)
+	if isNegative {
+		nfq += "-"
 	}
 
-	nfq += "]"
+	nfq += "@" + field + ":" + r.String()
 
 	ftq.query = append(ftq.query, nfq)
 
@@ -178,7 +173,11 @@ func (ftq *FtQuery) AddGeoFilterQuery(isNegative bool, field string, lon, lat, r
 		nfq += "-"
 	}
 
-	nfq += fmt.Sprintf("@%s:[%.f %.f %.f %s]", field, lon, lat, radius, unit)
+	nfq += "@" + field + ":[" +
+		strconv.FormatFloat(lon, 'f', -1, 64) + " " +
+		strconv.FormatFloat(lat, 'f', -1, 64) + " " +
+		strconv.FormatFloat(radius, 'f', -1, 64) + " " +
+		string(unit) + "]"
 
 	ftq.query = append(ftq.query, nfq)
 
@@ -0,0 +1,322 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Commands sent to the replicas by default. FT.AGGREGATE WITHCURSOR and FT.CURSOR stay on the
+// primary, a cursor only exists on the node that created it.
+var DefaultReadCommands = []string{
+	"FT.SEARCH",
+	"FT.AGGREGATE",
+	"FT.SUGGET",
+	"FT.SPELLCHECK",
+	"FT.INFO",
+	"FT.TAGVALS",
+}
+
+// Commands that never change data. They do not pin WithReadYourWrites contexts to the primary,
+// and stay on the primary unless AddReadCommands routes them to the replicas.
+var readOnlyCommands = map[string]bool{
+	"PING":          true,
+	"GET":           true,
+	"MGET":          true,
+	"EXISTS":        true,
+	"TYPE":          true,
+	"TTL":           true,
+	"PTTL":          true,
+	"SCAN":          true,
+	"HGET":          true,
+	"HMGET":         true,
+	"HGETALL":       true,
+	"HEXISTS":       true,
+	"HKEYS":         true,
+	"HVALS":         true,
+	"HLEN":          true,
+	"HSCAN":         true,
+	"JSON.GET":      true,
+	"JSON.MGET":     true,
+	"FT.SEARCH":     true,
+	"FT.AGGREGATE":  true,
+	"FT.CURSOR":     true,
+	"FT.EXPLAIN":    true,
+	"FT.EXPLAINCLI": true,
+	"FT.PROFILE":    true,
+	"FT.SPELLCHECK": true,
+	"FT.INFO":       true,
+	"FT._LIST":      true,
+	"FT.TAGVALS":    true,
+	"FT.SUGGET":     true,
+	"FT.SUGLEN":     true,
+	"FT.SYNDUMP":    true,
+	"FT.DICTDUMP":   true,
+}
+
+type readPinKey struct{}
+
+// readPin records the last write of a context, in unix nanoseconds
+type readPin struct {
+	wrote int64
+}
+
+// WithReadYourWrites pins the reads of ctx to the primary once ctx was used for a write,
+// so a search right after an HSET sees the document although the replicas lag behind.
+// The pin lasts for the lifetime of ctx, or for the pin duration of the ReadWriteExecutor.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(readPinKey{}).(*readPin); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, readPinKey{}, &readPin{})
+}
+
+// WithContext returns a copy of the client whose commands without a ctx argument (HSet, Del ...) use ctx
+func (rsc *RedisearchClient) WithContext(ctx context.Context) *RedisearchClient {
+	clone := *rsc
+	clone.Ctx = ctx
+
+	return &clone
+}
+
+type replica struct {
+	executor  Executor
+	downUntil int64 // unix nanoseconds
+}
+
+// ReadWriteExecutor sends read-only FT commands to replicas, round robin, and everything else to the primary.
+// A replica that fails with a network error or LOADING is skipped for the down time, reads fall
+// back to the primary when every replica is down. Only writes pin WithReadYourWrites contexts.
+//
+//	executor := redisearch.NewReadWriteExecutor(
+//		redisearch.NewGoRedisExecutor(primary),
+//		redisearch.NewGoRedisExecutor(replica1),
+//		redisearch.NewGoRedisExecutor(replica2),
+//	)
+//	client := redisearch.NewRedisearchClientWithExecutor("dreams", executor)
+//
+//	ctx := redisearch.WithReadYourWrites(r.Context())
+//	client.WithContext(ctx).HSet("drd:1", "name", "Dream")
+//	client.DoSearch(ctx, search) // served by the primary
+type ReadWriteExecutor struct {
+	primary  Executor
+	replicas []*replica
+	next     uint64
+
+	mu       sync.RWMutex
+	reads    map[string]bool
+	fallback bool
+	downTime time.Duration
+	pinTime  time.Duration
+}
+
+func NewReadWriteExecutor(primary Executor, replicas ...Executor) *ReadWriteExecutor {
+	e := &ReadWriteExecutor{
+		primary:  primary,
+		reads:    make(map[string]bool),
+		fallback: true,
+		downTime: 5 * time.Second,
+	}
+
+	for _, r := range replicas {
+		e.replicas = append(e.replicas, &replica{executor: r})
+	}
+
+	return e.AddReadCommands(DefaultReadCommands...)
+}
+
+// AddReadCommands routes more commands to the replicas, e.g. FT.SUGLEN or HGETALL
+func (e *ReadWriteExecutor) AddReadCommands(commands ...string) *ReadWriteExecutor {
+	e.mu.Lock()
+	for _, c := range commands {
+		e.reads[strings.ToUpper(c)] = true
+	}
+	e.mu.Unlock()
+
+	return e
+}
+
+// AddFallback sends reads to the primary when every replica is down (default true).
+// Without it the error of the last replica is returned.
+func (e *ReadWriteExecutor) AddFallback(active bool) *ReadWriteExecutor {
+	e.fallback = active
+
+	return e
+}
+
+// AddDownTime sets how long a failed replica is skipped (default 5s)
+func (e *ReadWriteExecutor) AddDownTime(d time.Duration) *ReadWriteExecutor {
+	e.downTime = d
+
+	return e
+}
+
+// AddPinDuration limits the read-your-writes pin to d after the last write, 0 pins for the lifetime of the context
+func (e *ReadWriteExecutor) AddPinDuration(d time.Duration) *ReadWriteExecutor {
+	e.pinTime = d
+
+	return e
+}
+
+func (e *ReadWriteExecutor) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	if !e.isRead(args) {
+		if isWrite(args) {
+			e.pin(ctx)
+		}
+		return e.primary.Do(ctx, args...)
+	}
+
+	if e.pinned(ctx) {
+		return e.primary.Do(ctx, args...)
+	}
+
+	var cmd *redis.Cmd
+	e.read(ctx, func(executor Executor) error {
+		cmd = executor.Do(ctx, args...)
+		return cmd.Err()
+	})
+
+	return cmd
+}
+
+// Pipeline sends the commands to a replica only when all of them are reads
+func (e *ReadWriteExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	reads, writes := true, false
+	for _, args := range cmds {
+		if !e.isRead(args) {
+			reads = false
+		}
+		if isWrite(args) {
+			writes = true
+		}
+	}
+
+	if writes {
+		e.pin(ctx)
+	}
+	if !reads {
+		return e.primary.Pipeline(ctx, cmds)
+	}
+
+	if e.pinned(ctx) {
+		return e.primary.Pipeline(ctx, cmds)
+	}
+
+	var res []*redis.Cmd
+	err := e.read(ctx, func(executor Executor) error {
+		var err error
+		res, err = executor.Pipeline(ctx, cmds)
+		return err
+	})
+
+	return res, err
+}
+
+// read tries the healthy replicas in round robin order, then the primary
+func (e *ReadWriteExecutor) read(ctx context.Context, run func(executor Executor) error) error {
+	if len(e.replicas) == 0 {
+		return run(e.primary)
+	}
+
+	start := atomic.AddUint64(&e.next, 1)
+	now := time.Now().UnixNano()
+
+	var err error
+	tried := false
+	for i := range e.replicas {
+		r := e.replicas[(start+uint64(i))%uint64(len(e.replicas))]
+		if atomic.LoadInt64(&r.downUntil) > now {
+			continue
+		}
+
+		tried = true
+		if err = run(r.executor); !isNodeDown(err) {
+			return err
+		}
+		atomic.StoreInt64(&r.downUntil, now+int64(e.downTime))
+	}
+
+	if e.fallback {
+		return run(e.primary)
+	}
+
+	// every replica is down, keep trying them rather than failing without a round trip
+	if !tried {
+		r := e.replicas[start%uint64(len(e.replicas))]
+		if err = run(r.executor); !isNodeDown(err) {
+			atomic.StoreInt64(&r.downUntil, 0)
+		}
+	}
+
+	return err
+}
+
+func (e *ReadWriteExecutor) isRead(args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	name := strings.ToUpper(replyString(args[0]))
+	if name == "FT.AGGREGATE" {
+		for _, arg := range args[1:] {
+			if strings.EqualFold(replyString(arg), "WITHCURSOR") {
+				return false
+			}
+		}
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.reads[name]
+}
+
+// isWrite reports commands that may change data, everything but the read-only commands
+func isWrite(args []interface{}) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	return !readOnlyCommands[strings.ToUpper(replyString(args[0]))]
+}
+
+func (e *ReadWriteExecutor) pin(ctx context.Context) {
+	if pin, ok := ctx.Value(readPinKey{}).(*readPin); ok {
+		atomic.StoreInt64(&pin.wrote, time.Now().UnixNano())
+	}
+}
+
+func (e *ReadWriteExecutor) pinned(ctx context.Context) bool {
+	pin, ok := ctx.Value(readPinKey{}).(*readPin)
+	if !ok {
+		return false
+	}
+
+	wrote := atomic.LoadInt64(&pin.wrote)
+	if wrote == 0 {
+		return false
+	}
+
+	return e.pinTime == 0 || time.Now().UnixNano()-wrote < int64(e.pinTime)
+}
+
+// isNodeDown reports network failures and nodes still loading their dataset. Other Redis errors,
+// redis.Nil and canceled contexts come from a working node.
+func isNodeDown(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var rerr redis.Error
+	if errors.As(err, &rerr) {
+		return strings.HasPrefix(rerr.Error(), "LOADING ")
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,183 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// downExecutor fails every command like an unreachable node
+type downExecutor struct {
+	calls int
+}
+
+func (e *downExecutor) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	e.calls++
+
+	cmd := redis.NewCmd(ctx, args...)
+	cmd.SetErr(errors.New("dial tcp 127.0.0.1:6380: connect: connection refused"))
+
+	return cmd
+}
+
+func (e *downExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	e.calls++
+
+	return nil, errors.New("dial tcp 127.0.0.1:6380: connect: connection refused")
+}
+
+// loadingError is the reply of a replica still loading its dataset
+type loadingError struct{}
+
+func (loadingError) Error() string { return "LOADING Redis is loading the dataset in memory" }
+func (loadingError) RedisError()   {}
+
+func TestReadWriteExecutor_Routing(t *testing.T) {
+	primary := NewRecordingExecutor(nil)
+	replica1 := NewRecordingExecutor(nil)
+	replica2 := NewRecordingExecutor(nil)
+
+	e := NewReadWriteExecutor(primary, replica1, replica2)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		args []interface{}
+		read bool
+	}{
+		{"Search", NewFtSearch("idx:drd").AddQuery("dream").Serialize(), true},
+		{"Aggregate", []interface{}{"FT.AGGREGATE", "idx:drd", "*"}, true},
+		{"Aggregate With Cursor", []interface{}{"FT.AGGREGATE", "idx:drd", "*", "WITHCURSOR"}, false},
+		{"Cursor Read", []interface{}{"FT.CURSOR", "READ", "idx:drd", 1}, false},
+		{"Suggestion Get", []interface{}{"ft.sugget", "sug:drd", "dre"}, true},
+		{"Spell Check", []interface{}{"FT.SPELLCHECK", "idx:drd", "draem"}, true},
+		{"Info", []interface{}{"FT.INFO", "idx:drd"}, true},
+		{"Tag Values", []interface{}{"FT.TAGVALS", "idx:drd", "cats"}, true},
+		{"Hash Set", []interface{}{"HSET", "drd:1", "name", "Dream"}, false},
+		{"Delete", []interface{}{"DEL", "drd:1"}, false},
+		{"Create", []interface{}{"FT.CREATE", "idx:drd", "SCHEMA", "name", "TEXT"}, false},
+		{"Alter", []interface{}{"FT.ALTER", "idx:drd", "SCHEMA", "ADD", "slug", "TEXT"}, false},
+		{"Suggestion Add", []interface{}{"FT.SUGADD", "sug:drd", "dream", 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary.Reset()
+			replica1.Reset()
+			replica2.Reset()
+
+			e.Do(ctx, tt.args...)
+
+			replicas := len(replica1.Commands()) + len(replica2.Commands())
+			if tt.read && (replicas != 1 || len(primary.Commands()) != 0) {
+				t.Errorf("read went to the primary %d times, replicas %d times", len(primary.Commands()), replicas)
+			}
+			if !tt.read && (replicas != 0 || len(primary.Commands()) != 1) {
+				t.Errorf("write went to the primary %d times, replicas %d times", len(primary.Commands()), replicas)
+			}
+		})
+	}
+
+	t.Run("Round Robin", func(t *testing.T) {
+		replica1.Reset()
+		replica2.Reset()
+
+		for i := 0; i < 4; i++ {
+			e.Do(ctx, "FT.INFO", "idx:drd")
+		}
+
+		if len(replica1.Commands()) != 2 || len(replica2.Commands()) != 2 {
+			t.Errorf("replica1 got %d reads, replica2 %d, want 2 each", len(replica1.Commands()), len(replica2.Commands()))
+		}
+	})
+
+	t.Run("Mixed Pipeline", func(t *testing.T) {
+		primary.Reset()
+
+		e.Pipeline(ctx, [][]interface{}{{"FT.INFO", "idx:drd"}, {"HSET", "drd:1", "name", "Dream"}})
+		if len(primary.Commands()) != 2 {
+			t.Errorf("primary got %d commands, want 2", len(primary.Commands()))
+		}
+	})
+}
+
+func TestReadWriteExecutor_Fallback(t *testing.T) {
+	primary := NewRecordingExecutor(nil)
+	down := &downExecutor{}
+
+	e := NewReadWriteExecutor(primary, down).AddDownTime(time.Minute)
+	ctx := context.Background()
+
+	if err := e.Do(ctx, "FT.INFO", "idx:drd").Err(); err != nil {
+		t.Fatal(err)
+	}
+	e.Do(ctx, "FT.INFO", "idx:drd")
+
+	if down.calls != 1 {
+		t.Errorf("down replica got %d reads, want 1 before it is skipped", down.calls)
+	}
+	if len(primary.Commands()) != 2 {
+		t.Errorf("primary got %d reads, want 2", len(primary.Commands()))
+	}
+
+	// a replica loading its dataset is skipped like a down one
+	loading := NewRecordingExecutor(NewScriptedExecutor().AddError(loadingError{}))
+	primary.Reset()
+
+	e = NewReadWriteExecutor(primary, loading).AddDownTime(time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := e.Do(ctx, "FT.INFO", "idx:drd").Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(loading.Commands()) != 1 || len(primary.Commands()) != 2 {
+		t.Errorf("loading replica got %d reads, primary %d, want 1 and 2", len(loading.Commands()), len(primary.Commands()))
+	}
+
+	// without fallback the replica error is returned
+	strict := NewReadWriteExecutor(primary, &downExecutor{}).AddFallback(false)
+	if err := strict.Do(ctx, "FT.INFO", "idx:drd").Err(); err == nil {
+		t.Error("Do() error = nil, want the replica error")
+	}
+}
+
+func TestReadWriteExecutor_ReadYourWrites(t *testing.T) {
+	primary := NewRecordingExecutor(nil)
+	replica := NewRecordingExecutor(nil)
+
+	rsc := NewRedisearchClientWithExecutor("test", NewReadWriteExecutor(primary, replica))
+	search := NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true)
+
+	ctx := WithReadYourWrites(context.Background())
+
+	// nothing written yet, the replica serves the read
+	rsc.DoSearch(ctx, search)
+	if len(replica.Commands()) != 1 {
+		t.Fatalf("replica got %d reads, want 1", len(replica.Commands()))
+	}
+
+	// plain reads on the primary do not pin
+	rsc.WithContext(ctx).HGetAll("drd:1")
+	rsc.WithContext(ctx).HealthCheckedUniversalClient()
+	rsc.Explain(ctx, "idx:drd", "dream", 0)
+	rsc.DoSearch(ctx, search)
+	if len(replica.Commands()) != 2 {
+		t.Fatalf("replica got %d reads, want 2 after plain reads", len(replica.Commands()))
+	}
+
+	primary.Reset()
+	rsc.WithContext(ctx).HSet("drd:1", "name", "Dream")
+	rsc.DoSearch(ctx, search)
+
+	if got := primary.Commands(); len(got) != 2 || got[1][0] != "FT.SEARCH" {
+		t.Errorf("primary commands = %v, want HSET then FT.SEARCH", got)
+	}
+
+	// other contexts still read from the replica
+	rsc.DoSearch(context.Background(), search)
+	if len(replica.Commands()) != 3 {
+		t.Errorf("replica got %d reads, want 3", len(replica.Commands()))
+	}
+}
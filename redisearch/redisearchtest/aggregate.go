@@ -0,0 +1,298 @@
+package redisearchtest
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// row of an aggregation, array values hold the parts of split()
+type aggregateRow map[string][]string
+
+// split(@attr, "sep") is the only APPLY expression the fake server evaluates
+var splitExpr = regexp.MustCompile(`^split\(\s*@(\w+)\s*,\s*"((?:[^"\\]|\\.)*)"\s*\)$`)
+
+/*
+FT.AGGREGATE {index} {query} [LOAD {num} @{attribute} ...] [APPLY split(@{attribute}, "{sep}") AS {alias}] ...
+
+	[GROUPBY {num} @{property} ... [REDUCE COUNT 0 AS {name}] ...] [SORTBY {num} @{property} [ASC|DESC] ... [MAX {num}]]
+	[LIMIT {offset} {num}]
+
+Other APPLY expressions, reducers, FILTER and WITHCURSOR are not supported.
+*/
+func cmdFtAggregate(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return errArgs("ft.aggregate")
+	}
+
+	ix, err := s.resolve(args[0])
+	if err != nil {
+		return errors.New(args[0] + ": no such index")
+	}
+
+	root, err := parseQuery(args[1])
+	if err != nil {
+		return err
+	}
+
+	var rows []aggregateRow
+	for _, key := range s.documents(ix) {
+		if ok, _ := root.match(ix, s.hashes[key]); ok {
+			rows = append(rows, aggregateRow{"__key": {key}})
+		}
+	}
+
+	// count reads the {num} {value} ... lists
+	count := func(i int) ([]string, int, error) {
+		if i+1 >= len(args) {
+			return nil, 0, errors.New("Bad arguments for " + args[i])
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 0 || i+2+n > len(args) {
+			return nil, 0, errors.New("Bad arguments for " + args[i])
+		}
+		return args[i+2 : i+2+n], i + 2 + n, nil
+	}
+
+	total := -1
+	for i := 2; i < len(args); {
+		switch strings.ToUpper(args[i]) {
+		case "LOAD":
+			props, next, err := count(i)
+			if err != nil {
+				return err
+			}
+			for _, row := range rows {
+				doc := s.hashes[row["__key"][0]]
+				for _, p := range props {
+					p = strings.TrimPrefix(p, "@")
+					if f := ix.field(p); f != nil {
+						if val, ok := doc[f.identifier]; ok {
+							row[p] = []string{val}
+						}
+					} else if val, ok := doc[p]; ok {
+						row[p] = []string{val}
+					}
+				}
+			}
+			i = next
+
+		case "APPLY":
+			if i+3 >= len(args) || !strings.EqualFold(args[i+2], "AS") {
+				return errors.New("Bad arguments for APPLY")
+			}
+			m := splitExpr.FindStringSubmatch(args[i+1])
+			if m == nil {
+				return errors.New("ERR fake server only supports APPLY split(@attr, \"sep\")")
+			}
+			sep, err := strconv.Unquote(`"` + m[2] + `"`)
+			if err != nil {
+				return errors.New("Bad arguments for APPLY")
+			}
+			for _, row := range rows {
+				vals, ok := row[m[1]]
+				if !ok || len(vals) == 0 {
+					continue
+				}
+				var parts []string
+				for _, p := range strings.Split(vals[0], sep) {
+					if p = strings.TrimSpace(p); p != "" {
+						parts = append(parts, p)
+					}
+				}
+				row[args[i+3]] = parts
+			}
+			i += 4
+
+		case "GROUPBY":
+			props, next, err := count(i)
+			if err != nil {
+				return err
+			}
+			for j := range props {
+				props[j] = strings.TrimPrefix(props[j], "@")
+			}
+
+			var counts []string
+			for next < len(args) && strings.EqualFold(args[next], "REDUCE") {
+				if next+4 >= len(args) || !strings.EqualFold(args[next+1], "COUNT") || args[next+2] != "0" || !strings.EqualFold(args[next+3], "AS") {
+					return errors.New("ERR fake server only supports REDUCE COUNT 0 AS {name}")
+				}
+				counts = append(counts, args[next+4])
+				next += 5
+			}
+
+			rows = groupRows(rows, props, counts)
+			total = len(rows)
+			i = next
+
+		case "SORTBY":
+			keys, next, err := count(i)
+			if err != nil {
+				return err
+			}
+			max := 0
+			if next+1 < len(args) && strings.EqualFold(args[next], "MAX") {
+				if max, err = strconv.Atoi(args[next+1]); err != nil {
+					return errors.New("Bad arguments for MAX")
+				}
+				next += 2
+			}
+			sortRows(rows, keys)
+			if max > 0 && len(rows) > max {
+				rows = rows[:max]
+			}
+			i = next
+
+		case "LIMIT":
+			if i+2 >= len(args) {
+				return errors.New("Bad arguments for LIMIT")
+			}
+			offset, err1 := strconv.Atoi(args[i+1])
+			num, err2 := strconv.Atoi(args[i+2])
+			if err1 != nil || err2 != nil || offset < 0 || num < 0 {
+				return errors.New("Bad arguments for LIMIT")
+			}
+			if offset > len(rows) {
+				offset = len(rows)
+			}
+			if offset+num < len(rows) {
+				rows = rows[offset : offset+num]
+			} else {
+				rows = rows[offset:]
+			}
+			i += 3
+
+		case "DIALECT":
+			i += 2
+
+		default:
+			return errors.New("ERR fake server does not support " + args[i] + " in FT.AGGREGATE")
+		}
+	}
+
+	if total < 0 {
+		total = len(rows)
+	}
+
+	reply := []interface{}{int64(total)}
+	for _, row := range rows {
+		keys := make([]string, 0, len(row))
+		for k := range row {
+			if k != "__key" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		fields := make([]interface{}, 0, 2*len(keys))
+		for _, k := range keys {
+			var val interface{}
+			switch len(row[k]) {
+			case 0:
+			case 1:
+				val = row[k][0]
+			default:
+				list := make([]interface{}, len(row[k]))
+				for j, v := range row[k] {
+					list[j] = v
+				}
+				val = list
+			}
+			fields = append(fields, k, val)
+		}
+		reply = append(reply, fields)
+	}
+
+	return reply
+}
+
+// groupRows groups by every combination of the property values, array values add a group per element
+func groupRows(rows []aggregateRow, props []string, counts []string) []aggregateRow {
+	index := make(map[string]aggregateRow)
+	var groups []aggregateRow
+
+	for _, row := range rows {
+		combos := [][]string{nil}
+		for _, p := range props {
+			vals := row[p]
+			if len(vals) == 0 {
+				vals = []string{""}
+			}
+			var next [][]string
+			for _, c := range combos {
+				for _, v := range vals {
+					next = append(next, append(append([]string(nil), c...), v))
+				}
+			}
+			combos = next
+		}
+
+		for _, c := range combos {
+			id := strings.Join(c, "\x00")
+			group, ok := index[id]
+			if !ok {
+				group = aggregateRow{}
+				for j, p := range props {
+					if c[j] != "" {
+						group[p] = []string{c[j]}
+					}
+				}
+				for _, name := range counts {
+					group[name] = []string{"0"}
+				}
+				index[id] = group
+				groups = append(groups, group)
+			}
+			for _, name := range counts {
+				n, _ := strconv.Atoi(group[name][0])
+				group[name] = []string{strconv.Itoa(n + 1)}
+			}
+		}
+	}
+
+	return groups
+}
+
+// sortRows reads {property} [ASC|DESC] pairs, numbers compare numerically
+func sortRows(rows []aggregateRow, keys []string) {
+	type sortKey struct {
+		prop string
+		asc  bool
+	}
+
+	var sks []sortKey
+	for j := 0; j < len(keys); j++ {
+		sk := sortKey{prop: strings.TrimPrefix(keys[j], "@"), asc: true}
+		if j+1 < len(keys) && (strings.EqualFold(keys[j+1], "ASC") || strings.EqualFold(keys[j+1], "DESC")) {
+			sk.asc = strings.EqualFold(keys[j+1], "ASC")
+			j++
+		}
+		sks = append(sks, sk)
+	}
+
+	first := func(row aggregateRow, prop string) string {
+		if len(row[prop]) == 0 {
+			return ""
+		}
+		return row[prop][0]
+	}
+
+	sort.SliceStable(rows, func(a, b int) bool {
+		for _, sk := range sks {
+			x, y := first(rows[a], sk.prop), first(rows[b], sk.prop)
+			if x == y {
+				continue
+			}
+			fx, errX := strconv.ParseFloat(x, 64)
+			fy, errY := strconv.ParseFloat(y, 64)
+			if errX == nil && errY == nil {
+				return (fx < fy) == sk.asc
+			}
+			return (x < y) == sk.asc
+		}
+		return false
+	})
+}
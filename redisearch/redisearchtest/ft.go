@@ -0,0 +1,1121 @@
+package redisearchtest
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolve returns the index for a name or an alias
+func (s *Server) resolve(name string) (*index, error) {
+	if real, ok := s.aliases[name]; ok {
+		name = real
+	}
+
+	ix, ok := s.indexes[name]
+	if !ok {
+		return nil, errors.New(name + ": no such index")
+	}
+
+	return ix, nil
+}
+
+// documents returns the keys indexed by ix in key order
+func (s *Server) documents(ix *index) []string {
+	var keys []string
+	for key := range s.hashes {
+		if ix.covers(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+/*
+FT.CREATE {index} [ON HASH] [PREFIX {count} {prefix} ...] [FILTER {filter}] [LANGUAGE {lang}]
+[SCORE {score}] [NOOFFSETS] [NOHL] [NOFIELDS] [NOFREQS] [SKIPINITIALSCAN] [STOPWORDS {num} {stopword} ...]
+SCHEMA ...
+*/
+func cmdFtCreate(s *Server, args []string) interface{} {
+	if len(args) < 3 {
+		return errArgs("ft.create")
+	}
+
+	name := args[0]
+	if _, ok := s.indexes[name]; ok {
+		return errors.New("Index already exists")
+	}
+
+	ix := &index{name: name, datatype: "HASH", langfield: "__language", score: "1", scorefield: "__score", payloadfield: "__payload"}
+
+	i := 1
+	for i < len(args) && !strings.EqualFold(args[i], "SCHEMA") {
+		opt := strings.ToUpper(args[i])
+		switch opt {
+		case "ON":
+			if i+1 >= len(args) {
+				return errArgs("ft.create")
+			}
+			ix.datatype = strings.ToUpper(args[i+1])
+			if ix.datatype != "HASH" {
+				return errors.New("ERR fake server only supports ON HASH")
+			}
+			i += 2
+
+		case "PREFIX", "STOPWORDS":
+			if i+1 >= len(args) {
+				return errArgs("ft.create")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 || i+2+n > len(args) {
+				return errors.New("Bad arguments for " + opt)
+			}
+			if opt == "PREFIX" {
+				ix.prefixes = append([]string(nil), args[i+2:i+2+n]...)
+			} else {
+				ix.stopwords = append([]string(nil), args[i+2:i+2+n]...)
+			}
+			i += 2 + n
+
+		case "FILTER", "LANGUAGE", "LANGUAGE_FIELD", "SCORE", "SCORE_FIELD", "PAYLOAD_FIELD", "TEMPORARY":
+			if i+1 >= len(args) {
+				return errArgs("ft.create")
+			}
+			switch opt {
+			case "FILTER":
+				ix.filter = args[i+1]
+			case "LANGUAGE":
+				ix.language = args[i+1]
+			case "LANGUAGE_FIELD":
+				ix.langfield = args[i+1]
+			case "SCORE":
+				ix.score = args[i+1]
+			case "SCORE_FIELD":
+				ix.scorefield = args[i+1]
+			case "PAYLOAD_FIELD":
+				ix.payloadfield = args[i+1]
+			}
+			i += 2
+
+		case "MAXTEXTFIELDS", "NOOFFSETS", "NOHL", "NOFIELDS", "NOFREQS", "SKIPINITIALSCAN":
+			ix.options = append(ix.options, opt)
+			i++
+
+		default:
+			return errors.New("Unknown argument `" + args[i] + "`")
+		}
+	}
+
+	if i >= len(args) {
+		return errors.New("No schema found")
+	}
+
+	fields, err := parseSchema(args[i+1:])
+	if err != nil {
+		return err
+	}
+	ix.fields = fields
+
+	s.indexes[name] = ix
+
+	return status("OK")
+}
+
+/*
+FT.ALTER {index} [SKIPINITIALSCAN] SCHEMA ADD {attribute} {options} ...
+*/
+func cmdFtAlter(s *Server, args []string) interface{} {
+	if len(args) < 4 {
+		return errArgs("ft.alter")
+	}
+
+	ix, err := s.resolve(args[0])
+	if err != nil {
+		return errors.New("Unknown index name")
+	}
+
+	i := 1
+	if strings.EqualFold(args[i], "SKIPINITIALSCAN") {
+		i++
+	}
+
+	if i+1 >= len(args) || !strings.EqualFold(args[i], "SCHEMA") || !strings.EqualFold(args[i+1], "ADD") {
+		return errors.New("ERR Unknown action passed to ALTER SCHEMA")
+	}
+
+	fields, err := parseSchema(args[i+2:])
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if ix.field(f.name()) != nil {
+			return errors.New("Duplicate field in schema - " + f.name())
+		}
+	}
+	ix.fields = append(ix.fields, fields...)
+
+	return status("OK")
+}
+
+// parseSchema reads {identifier} [AS {attribute}] {type} [options] ...
+func parseSchema(args []string) ([]*field, error) {
+	var fields []*field
+
+	for i := 0; i < len(args); {
+		f := &field{identifier: args[i], weight: 1}
+		i++
+
+		if i+1 < len(args) && strings.EqualFold(args[i], "AS") {
+			f.attribute = args[i+1]
+			i += 2
+		}
+
+		if i >= len(args) {
+			return nil, errors.New("Field `" + f.identifier + "` does not have a type")
+		}
+
+		f.typ = strings.ToUpper(args[i])
+		switch f.typ {
+		case "TEXT", "NUMERIC", "TAG", "GEO":
+		default:
+			return nil, errors.New("Invalid field type for field `" + f.identifier + "`")
+		}
+		if f.typ == "TAG" {
+			f.separator = ","
+		}
+		i++
+
+	options:
+		for i < len(args) {
+			switch strings.ToUpper(args[i]) {
+			case "SORTABLE":
+				f.sortable = true
+			case "UNF":
+				f.unf = true
+			case "NOINDEX":
+				f.noindex = true
+			case "NOSTEM":
+				f.nostem = true
+			case "CASESENSITIVE":
+				f.casesensitive = true
+			case "WEIGHT", "PHONETIC", "SEPARATOR":
+				if i+1 >= len(args) {
+					return nil, errors.New("Missing value for " + args[i])
+				}
+				switch strings.ToUpper(args[i]) {
+				case "WEIGHT":
+					w, err := strconv.ParseFloat(args[i+1], 64)
+					if err != nil {
+						return nil, errors.New("Could not parse field spec: bad weight")
+					}
+					f.weight = w
+				case "PHONETIC":
+					f.phonetic = args[i+1]
+				case "SEPARATOR":
+					f.separator = args[i+1]
+				}
+				i++
+			default:
+				break options
+			}
+			i++
+		}
+
+		fields = append(fields, f)
+	}
+
+	if len(fields) == 0 {
+		return nil, errors.New("No fields in schema")
+	}
+
+	return fields, nil
+}
+
+/*
+FT.DROPINDEX {index} [DD]
+*/
+func cmdFtDropIndex(s *Server, args []string) interface{} {
+	if len(args) < 1 {
+		return errArgs("ft.dropindex")
+	}
+
+	ix, ok := s.indexes[args[0]]
+	if !ok {
+		return errors.New("Unknown Index name")
+	}
+
+	if len(args) > 1 && strings.EqualFold(args[1], "DD") {
+		for _, key := range s.documents(ix) {
+			delete(s.hashes, key)
+		}
+	}
+
+	delete(s.indexes, args[0])
+	for alias, name := range s.aliases {
+		if name == args[0] {
+			delete(s.aliases, alias)
+		}
+	}
+
+	return status("OK")
+}
+
+func cmdFtList(s *Server, args []string) interface{} {
+	names := make([]string, 0, len(s.indexes))
+	for name := range s.indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+/*
+FT.INFO {index}
+Returns the definition, the attributes and the document count. Other statistics are zero.
+*/
+func cmdFtInfo(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("ft.info")
+	}
+
+	ix, err := s.resolve(args[0])
+	if err != nil {
+		return errors.New("Unknown Index name")
+	}
+
+	options := make([]interface{}, 0, len(ix.options))
+	for _, o := range ix.options {
+		options = append(options, o)
+	}
+
+	prefixes := make([]interface{}, 0, len(ix.prefixes))
+	for _, p := range ix.prefixes {
+		prefixes = append(prefixes, p)
+	}
+	if len(prefixes) == 0 {
+		prefixes = append(prefixes, "")
+	}
+
+	definition := []interface{}{"key_type", ix.datatype, "prefixes", prefixes}
+	if ix.filter != "" {
+		definition = append(definition, "filter", ix.filter)
+	}
+	if ix.language != "" {
+		definition = append(definition, "default_language", ix.language)
+	}
+	definition = append(definition, "language_field", ix.langfield, "default_score", ix.score,
+		"score_field", ix.scorefield, "payload_field", ix.payloadfield)
+
+	attributes := make([]interface{}, 0, len(ix.fields))
+	for _, f := range ix.fields {
+		attr := []interface{}{"identifier", f.identifier, "attribute", f.name(), "type", f.typ}
+		switch f.typ {
+		case "TEXT":
+			attr = append(attr, "WEIGHT", strconv.FormatFloat(f.weight, 'f', -1, 64))
+			if f.phonetic != "" {
+				attr = append(attr, "PHONETIC", f.phonetic)
+			}
+		case "TAG":
+			attr = append(attr, "SEPARATOR", f.separator)
+			if f.casesensitive {
+				attr = append(attr, "CASESENSITIVE")
+			}
+		}
+		if f.sortable {
+			attr = append(attr, "SORTABLE")
+		}
+		if f.unf {
+			attr = append(attr, "UNF")
+		}
+		if f.nostem {
+			attr = append(attr, "NOSTEM")
+		}
+		if f.noindex {
+			attr = append(attr, "NOINDEX")
+		}
+		attributes = append(attributes, attr)
+	}
+
+	docs := strconv.Itoa(len(s.documents(ix)))
+
+	reply := []interface{}{
+		"index_name", ix.name,
+		"index_options", options,
+		"index_definition", definition,
+		"attributes", attributes,
+		"num_docs", docs,
+		"max_doc_id", docs,
+		"num_terms", "0",
+		"num_records", "0",
+		"indexing", "0",
+		"percent_indexed", "1",
+		"hash_indexing_failures", "0",
+	}
+
+	// like RediSearch, the list is only reported for custom stop words
+	if ix.stopwords != nil {
+		stopwords := make([]interface{}, 0, len(ix.stopwords))
+		for _, sw := range ix.stopwords {
+			stopwords = append(stopwords, sw)
+		}
+		reply = append(reply, "stopwords_list", stopwords)
+	}
+
+	return reply
+}
+
+/*
+FT.TAGVALS {index} {attribute}
+*/
+func cmdFtTagVals(s *Server, args []string) interface{} {
+	if len(args) != 2 {
+		return errArgs("ft.tagvals")
+	}
+
+	ix, err := s.resolve(args[0])
+	if err != nil {
+		return errors.New("Unknown Index name")
+	}
+
+	f := ix.field(args[1])
+	if f == nil || f.typ != "TAG" {
+		return errors.New("No such field")
+	}
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, key := range s.documents(ix) {
+		for _, t := range splitTags(s.hashes[key][f.identifier], f) {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+func cmdFtAliasAdd(s *Server, args []string) interface{} {
+	if len(args) != 2 {
+		return errArgs("ft.aliasadd")
+	}
+
+	if _, ok := s.aliases[args[0]]; ok {
+		return errors.New("Alias already exists")
+	}
+
+	if _, ok := s.indexes[args[1]]; !ok {
+		return errors.New("Unknown index name (or name is an alias itself)")
+	}
+
+	s.aliases[args[0]] = args[1]
+
+	return status("OK")
+}
+
+func cmdFtAliasUpdate(s *Server, args []string) interface{} {
+	if len(args) != 2 {
+		return errArgs("ft.aliasupdate")
+	}
+
+	if _, ok := s.indexes[args[1]]; !ok {
+		return errors.New("Unknown index name (or name is an alias itself)")
+	}
+
+	s.aliases[args[0]] = args[1]
+
+	return status("OK")
+}
+
+func cmdFtAliasDel(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("ft.aliasdel")
+	}
+
+	if _, ok := s.aliases[args[0]]; !ok {
+		return errors.New("Alias does not exist")
+	}
+
+	delete(s.aliases, args[0])
+
+	return status("OK")
+}
+
+// search options collected from FT.SEARCH arguments
+type searchOptions struct {
+	nocontent    bool
+	withscores   bool
+	withpayloads bool
+	withsortkeys bool
+	filters      []numericNode
+	inkeys       map[string]bool
+	infields     []string
+	returnfields []string
+	returnall    bool
+	sortby       string
+	asc          bool
+	offset       int
+	num          int
+}
+
+/*
+FT.SEARCH {index} {query} [NOCONTENT] [VERBATIM] [NOSTOPWORDS] [WITHSCORES] [WITHPAYLOADS] [WITHSORTKEYS]
+
+	[FILTER {numeric_attribute} {min} {max}] ... [INKEYS {num} {key} ...] [INFIELDS {num} {attribute} ...]
+	[RETURN {num} {identifier} ...] [SORTBY {attribute} [ASC|DESC]] [LIMIT offset num]
+
+SUMMARIZE, HIGHLIGHT, SLOP, INORDER, LANGUAGE, EXPANDER, SCORER, PAYLOAD and DIALECT are accepted and ignored.
+GEOFILTER is not supported.
+*/
+func cmdFtSearch(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return errArgs("ft.search")
+	}
+
+	ix, err := s.resolve(args[0])
+	if err != nil {
+		return errors.New(args[0] + ": no such index")
+	}
+
+	opts, err := parseSearchOptions(ix, args[2:])
+	if err != nil {
+		return err
+	}
+
+	root, err := parseQuery(args[1])
+	if err != nil {
+		return err
+	}
+
+	if len(opts.infields) > 0 {
+		root = scopeFields(root, opts.infields)
+	}
+
+	type hit struct {
+		key   string
+		score float64
+	}
+
+	var hits []hit
+	for _, key := range s.documents(ix) {
+		if opts.inkeys != nil && !opts.inkeys[key] {
+			continue
+		}
+
+		doc := s.hashes[key]
+
+		ok, score := root.match(ix, doc)
+		if !ok {
+			continue
+		}
+
+		for _, f := range opts.filters {
+			if ok, _ = f.match(ix, doc); !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		hits = append(hits, hit{key: key, score: score})
+	}
+
+	var sortField *field
+	if opts.sortby != "" {
+		sortField = ix.field(opts.sortby)
+		if sortField == nil || !sortField.sortable {
+			return errors.New("Property `" + opts.sortby + "` not loaded nor in schema")
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if sortField != nil {
+			c := compareValues(sortField, s.hashes[hits[i].key][sortField.identifier], s.hashes[hits[j].key][sortField.identifier])
+			if c != 0 {
+				if opts.asc {
+					return c < 0
+				}
+				return c > 0
+			}
+			return hits[i].key < hits[j].key
+		}
+
+		if hits[i].score != hits[j].score {
+			return hits[i].score > hits[j].score
+		}
+		return hits[i].key < hits[j].key
+	})
+
+	reply := []interface{}{int64(len(hits))}
+
+	for i := opts.offset; i < len(hits) && i < opts.offset+opts.num; i++ {
+		h := hits[i]
+		doc := s.hashes[h.key]
+
+		reply = append(reply, h.key)
+
+		if opts.withscores {
+			reply = append(reply, strconv.FormatFloat(h.score, 'f', -1, 64))
+		}
+
+		if opts.withpayloads {
+			reply = append(reply, nil)
+		}
+
+		if opts.withsortkeys {
+			reply = append(reply, sortKey(sortField, doc))
+		}
+
+		if !opts.nocontent {
+			reply = append(reply, docFields(ix, doc, opts))
+		}
+	}
+
+	return reply
+}
+
+func parseSearchOptions(ix *index, args []string) (*searchOptions, error) {
+	opts := &searchOptions{asc: true, num: 10, returnall: true}
+
+	// count reads the {num} {value} ... lists
+	count := func(i int) ([]string, int, error) {
+		if i+1 >= len(args) {
+			return nil, 0, errors.New("Bad arguments for " + args[i])
+		}
+		n, err := strconv.Atoi(args[i+1])
+		if err != nil || n < 0 || i+2+n > len(args) {
+			return nil, 0, errors.New("Bad arguments for " + args[i])
+		}
+		return args[i+2 : i+2+n], i + 2 + n, nil
+	}
+
+	for i := 0; i < len(args); {
+		switch strings.ToUpper(args[i]) {
+		case "NOCONTENT":
+			opts.nocontent = true
+			i++
+		case "WITHSCORES":
+			opts.withscores = true
+			i++
+		case "WITHPAYLOADS":
+			opts.withpayloads = true
+			i++
+		case "WITHSORTKEYS":
+			opts.withsortkeys = true
+			i++
+		case "VERBATIM", "NOSTOPWORDS", "INORDER", "EXPLAINSCORE":
+			i++
+		case "SLOP", "LANGUAGE", "EXPANDER", "SCORER", "PAYLOAD", "DIALECT", "TIMEOUT":
+			i += 2
+
+		case "FILTER":
+			if i+3 >= len(args) {
+				return nil, errors.New("Bad arguments for FILTER")
+			}
+			min, exclMin, err := parseBound(args[i+2])
+			if err != nil {
+				return nil, err
+			}
+			max, exclMax, err := parseBound(args[i+3])
+			if err != nil {
+				return nil, err
+			}
+			f := ix.field(args[i+1])
+			if f == nil || f.typ != "NUMERIC" {
+				return nil, errors.New("Unknown field `" + args[i+1] + "`")
+			}
+			opts.filters = append(opts.filters, numericNode{field: args[i+1], min: min, max: max, exclMin: exclMin, exclMax: exclMax})
+			i += 4
+
+		case "GEOFILTER":
+			return nil, errors.New("ERR fake server does not support GEOFILTER")
+
+		case "INKEYS":
+			keys, next, err := count(i)
+			if err != nil {
+				return nil, err
+			}
+			opts.inkeys = make(map[string]bool, len(keys))
+			for _, k := range keys {
+				opts.inkeys[k] = true
+			}
+			i = next
+
+		case "INFIELDS":
+			fields, next, err := count(i)
+			if err != nil {
+				return nil, err
+			}
+			opts.infields = fields
+			i = next
+
+		case "RETURN":
+			fields, next, err := count(i)
+			if err != nil {
+				return nil, err
+			}
+			opts.returnall = false
+			opts.returnfields = fields
+			if len(fields) == 0 {
+				opts.nocontent = true
+			}
+			i = next
+
+		case "SUMMARIZE", "HIGHLIGHT":
+			i++
+			for i < len(args) {
+				switch strings.ToUpper(args[i]) {
+				case "FIELDS":
+					_, next, err := count(i)
+					if err != nil {
+						return nil, err
+					}
+					i = next
+					continue
+				case "FRAGS", "LEN", "SEPARATOR":
+					i += 2
+					continue
+				case "TAGS":
+					i += 3
+					continue
+				}
+				break
+			}
+
+		case "SORTBY":
+			if i+1 >= len(args) {
+				return nil, errors.New("Bad arguments for SORTBY")
+			}
+			opts.sortby = strings.TrimPrefix(args[i+1], "@")
+			i += 2
+			if i < len(args) {
+				switch strings.ToUpper(args[i]) {
+				case "ASC":
+					i++
+				case "DESC":
+					opts.asc = false
+					i++
+				}
+			}
+
+		case "LIMIT":
+			if i+2 >= len(args) {
+				return nil, errors.New("Bad arguments for LIMIT")
+			}
+			offset, err1 := strconv.Atoi(args[i+1])
+			num, err2 := strconv.Atoi(args[i+2])
+			if err1 != nil || err2 != nil || offset < 0 || num < 0 {
+				return nil, errors.New("Bad arguments for LIMIT")
+			}
+			opts.offset, opts.num = offset, num
+			i += 3
+
+		default:
+			return nil, errors.New("Unknown argument `" + args[i] + "`")
+		}
+	}
+
+	return opts, nil
+}
+
+// scopeFields limits unscoped text terms to the INFIELDS attributes
+func scopeFields(n node, fields []string) node {
+	switch v := n.(type) {
+	case termNode:
+		if v.fields == nil {
+			v.fields = fields
+		}
+		return v
+	case phraseNode:
+		if v.fields == nil {
+			v.fields = fields
+		}
+		return v
+	case notNode:
+		return notNode{child: scopeFields(v.child, fields)}
+	case optionalNode:
+		return optionalNode{child: scopeFields(v.child, fields)}
+	case andNode:
+		children := make([]node, len(v.children))
+		for i, c := range v.children {
+			children[i] = scopeFields(c, fields)
+		}
+		return andNode{children: children}
+	case orNode:
+		children := make([]node, len(v.children))
+		for i, c := range v.children {
+			children[i] = scopeFields(c, fields)
+		}
+		return orNode{children: children}
+	}
+
+	return n
+}
+
+// compareValues compares numeric fields as numbers, others case insensitive. Missing values sort last.
+func compareValues(f *field, a, b string) int {
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	if f.typ == "NUMERIC" {
+		x, _ := strconv.ParseFloat(a, 64)
+		y, _ := strconv.ParseFloat(b, 64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// sortKey renders WITHSORTKEYS values: #number, $string or none
+func sortKey(f *field, doc map[string]string) interface{} {
+	if f == nil {
+		return nil
+	}
+
+	val, ok := doc[f.identifier]
+	if !ok {
+		return "none"
+	}
+
+	if f.typ == "NUMERIC" {
+		n, err := strconv.ParseFloat(val, 64)
+		if err != nil || math.IsNaN(n) {
+			return "none"
+		}
+		return "#" + strconv.FormatFloat(n, 'f', -1, 64)
+	}
+
+	return "$" + strings.ToLower(val)
+}
+
+// docFields returns all hash fields or the RETURN fields, renamed to their attribute
+func docFields(ix *index, doc map[string]string, opts *searchOptions) []interface{} {
+	var res []interface{}
+
+	if opts.returnall {
+		names := make([]string, 0, len(doc))
+		for name := range doc {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			res = append(res, name, doc[name])
+		}
+
+		return res
+	}
+
+	for _, name := range opts.returnfields {
+		identifier := name
+		if f := ix.field(name); f != nil {
+			identifier = f.identifier
+		}
+
+		if val, ok := doc[identifier]; ok {
+			res = append(res, name, val)
+		}
+	}
+
+	return res
+}
+
+/*
+FT.SUGADD {key} {string} {score} [INCR] [PAYLOAD {payload}]
+*/
+func cmdFtSugAdd(s *Server, args []string) interface{} {
+	if len(args) < 3 {
+		return errArgs("ft.sugadd")
+	}
+
+	score, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return errors.New("ERR invalid score")
+	}
+
+	var incr bool
+	var payload string
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "INCR":
+			incr = true
+		case "PAYLOAD":
+			if i+1 >= len(args) {
+				return errArgs("ft.sugadd")
+			}
+			payload = args[i+1]
+			i++
+		default:
+			return errors.New("Unknown argument `" + args[i] + "`")
+		}
+	}
+
+	dict, ok := s.sugs[args[0]]
+	if !ok {
+		dict = make(map[string]*suggestion)
+		s.sugs[args[0]] = dict
+	}
+
+	if sug, ok := dict[args[1]]; ok && incr {
+		sug.score += score
+		if payload != "" {
+			sug.payload = payload
+		}
+	} else {
+		dict[args[1]] = &suggestion{score: score, payload: payload}
+	}
+
+	return int64(len(dict))
+}
+
+/*
+FT.SUGGET {key} {prefix} [FUZZY] [WITHSCORES] [WITHPAYLOADS] [MAX num]
+FUZZY is accepted and matched as a case insensitive prefix.
+*/
+func cmdFtSugGet(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return errArgs("ft.sugget")
+	}
+
+	var withScores, withPayloads bool
+	max := 5
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "FUZZY":
+		case "WITHSCORES":
+			withScores = true
+		case "WITHPAYLOADS":
+			withPayloads = true
+		case "MAX":
+			if i+1 >= len(args) {
+				return errArgs("ft.sugget")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				return errors.New("ERR invalid MAX")
+			}
+			max = n
+			i++
+		default:
+			return errors.New("Unknown argument `" + args[i] + "`")
+		}
+	}
+
+	prefix := strings.ToLower(args[1])
+
+	var matches []string
+	for str := range s.sugs[args[0]] {
+		if strings.HasPrefix(strings.ToLower(str), prefix) {
+			matches = append(matches, str)
+		}
+	}
+
+	dict := s.sugs[args[0]]
+	sort.Slice(matches, func(i, j int) bool {
+		if dict[matches[i]].score != dict[matches[j]].score {
+			return dict[matches[i]].score > dict[matches[j]].score
+		}
+		return matches[i] < matches[j]
+	})
+
+	if len(matches) > max {
+		matches = matches[:max]
+	}
+
+	var reply []interface{}
+	for _, m := range matches {
+		reply = append(reply, m)
+		if withScores {
+			reply = append(reply, strconv.FormatFloat(dict[m].score, 'f', -1, 64))
+		}
+		if withPayloads {
+			if dict[m].payload == "" {
+				reply = append(reply, nil)
+			} else {
+				reply = append(reply, dict[m].payload)
+			}
+		}
+	}
+
+	if reply == nil {
+		return []interface{}{}
+	}
+
+	return reply
+}
+
+func cmdFtSugDel(s *Server, args []string) interface{} {
+	if len(args) != 2 {
+		return errArgs("ft.sugdel")
+	}
+
+	if _, ok := s.sugs[args[0]][args[1]]; !ok {
+		return int64(0)
+	}
+	delete(s.sugs[args[0]], args[1])
+
+	return int64(1)
+}
+
+func cmdFtSugLen(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("ft.suglen")
+	}
+
+	return int64(len(s.sugs[args[0]]))
+}
+
+func cmdFtDictAdd(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return errArgs("ft.dictadd")
+	}
+
+	dict, ok := s.dicts[args[0]]
+	if !ok {
+		dict = make(map[string]bool)
+		s.dicts[args[0]] = dict
+	}
+
+	var added int64
+	for _, t := range args[1:] {
+		if !dict[t] {
+			dict[t] = true
+			added++
+		}
+	}
+
+	return added
+}
+
+func cmdFtDictDel(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return errArgs("ft.dictdel")
+	}
+
+	var deleted int64
+	for _, t := range args[1:] {
+		if s.dicts[args[0]][t] {
+			delete(s.dicts[args[0]], t)
+			deleted++
+		}
+	}
+
+	if len(s.dicts[args[0]]) == 0 {
+		delete(s.dicts, args[0])
+	}
+
+	return deleted
+}
+
+func cmdFtDictDump(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("ft.dictdump")
+	}
+
+	terms := make([]string, 0, len(s.dicts[args[0]]))
+	for t := range s.dicts[args[0]] {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+
+	return terms
+}
+
+/*
+FT.SYNUPDATE {index} {group_id} [SKIPINITIALSCAN] {term} ...
+*/
+func cmdFtSynUpdate(s *Server, args []string) interface{} {
+	if len(args) < 3 {
+		return errArgs("ft.synupdate")
+	}
+
+	ix, err := s.resolve(args[0])
+	if err != nil {
+		return errors.New("Unknown Index name")
+	}
+
+	terms := args[2:]
+	if strings.EqualFold(terms[0], "SKIPINITIALSCAN") {
+		terms = terms[1:]
+	}
+	if len(terms) == 0 {
+		return errArgs("ft.synupdate")
+	}
+
+	if ix.synonyms == nil {
+		ix.synonyms = make(map[string][]string)
+	}
+
+	group := args[1]
+	for _, t := range terms {
+		t = strings.ToLower(t)
+
+		known := false
+		for _, g := range ix.synonyms[group] {
+			known = known || g == t
+		}
+		if !known {
+			ix.synonyms[group] = append(ix.synonyms[group], t)
+		}
+	}
+
+	return status("OK")
+}
+
+/*
+FT.SYNDUMP {index}
+Reply: term, [group_id ...], ...
+*/
+func cmdFtSynDump(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("ft.syndump")
+	}
+
+	ix, err := s.resolve(args[0])
+	if err != nil {
+		return errors.New("Unknown Index name")
+	}
+
+	groups := make(map[string][]string)
+	for group, terms := range ix.synonyms {
+		for _, t := range terms {
+			groups[t] = append(groups[t], group)
+		}
+	}
+
+	terms := make([]string, 0, len(groups))
+	for t := range groups {
+		terms = append(terms, t)
+	}
+	sort.Strings(terms)
+
+	reply := make([]interface{}, 0, 2*len(terms))
+	for _, t := range terms {
+		ids := groups[t]
+		sort.Strings(ids)
+		reply = append(reply, t, ids)
+	}
+
+	return reply
+}
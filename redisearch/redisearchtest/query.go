@@ -0,0 +1,558 @@
+package redisearchtest
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+Query syntax subset understood by the fake server:
+
+	hello world            intersection
+	hello|world            union (binds tighter than intersection)
+	-hello                 negation
+	~hello                 optional term
+	(hello|halo) world     grouping
+	"hello world"          exact phrase
+	hel*                   prefix
+	%hello%                fuzzy, matched exactly
+	@name|slug:hello       field modifier
+	@cats:{dream | "a b"}  tags, prefix tags with dre*
+	@updated:[(100 +inf]   numeric range
+	*                      all documents
+
+Terms are lowercased and split on non alphanumeric characters. There is no stemming,
+no stopword removal and no synonym expansion.
+*/
+
+// node is a parsed query clause. match returns whether the document matches and its score.
+type node interface {
+	match(ix *index, doc map[string]string) (bool, float64)
+}
+
+type allNode struct{}
+
+type notNode struct {
+	child node
+}
+
+type optionalNode struct {
+	child node
+}
+
+type andNode struct {
+	children []node
+}
+
+type orNode struct {
+	children []node
+}
+
+type termNode struct {
+	fields []string // nil for all TEXT fields
+	word   string
+	prefix bool
+}
+
+type phraseNode struct {
+	fields []string
+	words  []string
+}
+
+type tagNode struct {
+	field string
+	tags  []string
+}
+
+type numericNode struct {
+	field            string
+	min, max         float64
+	exclMin, exclMax bool
+}
+
+func (allNode) match(ix *index, doc map[string]string) (bool, float64) {
+	return true, 1
+}
+
+func (n notNode) match(ix *index, doc map[string]string) (bool, float64) {
+	ok, _ := n.child.match(ix, doc)
+	return !ok, 0
+}
+
+func (n optionalNode) match(ix *index, doc map[string]string) (bool, float64) {
+	_, score := n.child.match(ix, doc)
+	return true, score
+}
+
+func (n andNode) match(ix *index, doc map[string]string) (bool, float64) {
+	var total float64
+	for _, c := range n.children {
+		ok, score := c.match(ix, doc)
+		if !ok {
+			return false, 0
+		}
+		total += score
+	}
+
+	return true, total
+}
+
+func (n orNode) match(ix *index, doc map[string]string) (bool, float64) {
+	var matched bool
+	var total float64
+	for _, c := range n.children {
+		if ok, score := c.match(ix, doc); ok {
+			matched = true
+			total += score
+		}
+	}
+
+	return matched, total
+}
+
+func (n termNode) match(ix *index, doc map[string]string) (bool, float64) {
+	var score float64
+	for _, f := range ix.textFields(n.fields) {
+		for _, token := range tokenize(doc[f.identifier]) {
+			if token == n.word || (n.prefix && strings.HasPrefix(token, n.word)) {
+				score += f.weight
+			}
+		}
+	}
+
+	return score > 0, score
+}
+
+func (n phraseNode) match(ix *index, doc map[string]string) (bool, float64) {
+	if len(n.words) == 0 {
+		return false, 0
+	}
+
+	var score float64
+	for _, f := range ix.textFields(n.fields) {
+		tokens := tokenize(doc[f.identifier])
+		for i := 0; i+len(n.words) <= len(tokens); i++ {
+			found := true
+			for j, w := range n.words {
+				if tokens[i+j] != w {
+					found = false
+					break
+				}
+			}
+			if found {
+				score += f.weight * float64(len(n.words))
+			}
+		}
+	}
+
+	return score > 0, score
+}
+
+func (n tagNode) match(ix *index, doc map[string]string) (bool, float64) {
+	f := ix.field(n.field)
+	if f == nil || f.typ != "TAG" {
+		return false, 0
+	}
+
+	for _, val := range splitTags(doc[f.identifier], f) {
+		for _, tag := range n.tags {
+			if !f.casesensitive {
+				tag = strings.ToLower(tag)
+			}
+
+			if strings.HasSuffix(tag, "*") {
+				if strings.HasPrefix(val, strings.TrimSuffix(tag, "*")) {
+					return true, 1
+				}
+			} else if val == tag {
+				return true, 1
+			}
+		}
+	}
+
+	return false, 0
+}
+
+func (n numericNode) match(ix *index, doc map[string]string) (bool, float64) {
+	f := ix.field(n.field)
+	if f == nil || f.typ != "NUMERIC" {
+		return false, 0
+	}
+
+	val, err := strconv.ParseFloat(doc[f.identifier], 64)
+	if err != nil {
+		return false, 0
+	}
+
+	return inRange(val, n.min, n.max, n.exclMin, n.exclMax), 0
+}
+
+func inRange(val, min, max float64, exclMin, exclMax bool) bool {
+	if val < min || (exclMin && val == min) {
+		return false
+	}
+
+	if val > max || (exclMax && val == max) {
+		return false
+	}
+
+	return true
+}
+
+// tokenize lowercases and splits text on everything but letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func splitTags(val string, f *field) []string {
+	sep := f.separator
+	if sep == "" {
+		sep = ","
+	}
+
+	var tags []string
+	for _, t := range strings.Split(val, sep) {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !f.casesensitive {
+			t = strings.ToLower(t)
+		}
+		tags = append(tags, t)
+	}
+
+	return tags
+}
+
+// parseQuery parses the query string into a node tree
+func parseQuery(query string) (node, error) {
+	p := &parser{input: []rune(query)}
+
+	n, err := p.parseIntersect(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, errors.New("Syntax error at offset " + strconv.Itoa(p.pos) + " near " + string(p.input[p.pos:]))
+	}
+
+	if n == nil {
+		return nil, errors.New("Syntax error: empty query")
+	}
+
+	return n, nil
+}
+
+type parser struct {
+	input []rune
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *parser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *parser) parseIntersect(scope []string) (node, error) {
+	var children []node
+
+	for {
+		p.skipSpaces()
+		if p.eof() || p.peek() == ')' {
+			break
+		}
+
+		n, err := p.parseUnion(scope)
+		if err != nil {
+			return nil, err
+		}
+		if n != nil {
+			children = append(children, n)
+		}
+	}
+
+	switch len(children) {
+	case 0:
+		return nil, nil
+	case 1:
+		return children[0], nil
+	}
+
+	return andNode{children: children}, nil
+}
+
+func (p *parser) parseUnion(scope []string) (node, error) {
+	first, err := p.parseUnary(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	children := []node{first}
+	for {
+		p.skipSpaces()
+		if p.peek() != '|' {
+			break
+		}
+		p.pos++
+
+		n, err := p.parseUnary(scope)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+
+	return orNode{children: children}, nil
+}
+
+func (p *parser) parseUnary(scope []string) (node, error) {
+	p.skipSpaces()
+
+	switch p.peek() {
+	case '-':
+		p.pos++
+		n, err := p.parseUnary(scope)
+		if err != nil {
+			return nil, err
+		}
+		return notNode{child: n}, nil
+
+	case '~':
+		p.pos++
+		n, err := p.parseUnary(scope)
+		if err != nil {
+			return nil, err
+		}
+		return optionalNode{child: n}, nil
+	}
+
+	return p.parseAtom(scope)
+}
+
+func (p *parser) parseAtom(scope []string) (node, error) {
+	p.skipSpaces()
+
+	switch p.peek() {
+	case 0:
+		return nil, errors.New("Syntax error: unexpected end of query")
+
+	case '(':
+		p.pos++
+		n, err := p.parseIntersect(scope)
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.peek() != ')' {
+			return nil, errors.New("Syntax error: missing )")
+		}
+		p.pos++
+		if n == nil {
+			return nil, errors.New("Syntax error: empty group")
+		}
+		return n, nil
+
+	case '@':
+		return p.parseField()
+
+	case '"':
+		p.pos++
+		start := p.pos
+		for !p.eof() && p.peek() != '"' {
+			p.pos++
+		}
+		if p.eof() {
+			return nil, errors.New("Syntax error: missing closing quote")
+		}
+		phrase := string(p.input[start:p.pos])
+		p.pos++
+		return phraseNode{fields: scope, words: tokenize(phrase)}, nil
+
+	case '*':
+		p.pos++
+		return allNode{}, nil
+	}
+
+	word := p.readWord()
+	if word == "" {
+		return nil, errors.New("Syntax error at offset " + strconv.Itoa(p.pos))
+	}
+
+	prefix := strings.HasSuffix(word, "*")
+	word = strings.Trim(word, "%*")
+
+	tokens := tokenize(word)
+	switch len(tokens) {
+	case 0:
+		return nil, errors.New("Syntax error: invalid term " + word)
+	case 1:
+		return termNode{fields: scope, word: tokens[0], prefix: prefix}, nil
+	}
+
+	// "hello-world" style terms are matched as a phrase
+	return phraseNode{fields: scope, words: tokens}, nil
+}
+
+// readWord reads a term until a space or a query operator. Backslash escapes the next character.
+func (p *parser) readWord() string {
+	var sb strings.Builder
+
+	for !p.eof() {
+		r := p.peek()
+		if r == '\\' && p.pos+1 < len(p.input) {
+			sb.WriteRune(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		}
+
+		if unicode.IsSpace(r) || strings.ContainsRune("()|{}[]@\"~", r) {
+			break
+		}
+
+		sb.WriteRune(r)
+		p.pos++
+	}
+
+	return sb.String()
+}
+
+// parseField reads @field|field:clause
+func (p *parser) parseField() (node, error) {
+	p.pos++ // @
+
+	start := p.pos
+	for !p.eof() && p.peek() != ':' {
+		p.pos++
+	}
+	if p.eof() {
+		return nil, errors.New("Syntax error: missing : after field")
+	}
+	fields := strings.Split(string(p.input[start:p.pos]), "|")
+	p.pos++ // :
+
+	p.skipSpaces()
+	switch p.peek() {
+	case '{':
+		if len(fields) != 1 {
+			return nil, errors.New("Syntax error: tag filter on multiple fields")
+		}
+		return p.parseTags(fields[0])
+
+	case '[':
+		if len(fields) != 1 {
+			return nil, errors.New("Syntax error: numeric filter on multiple fields")
+		}
+		return p.parseNumeric(fields[0])
+	}
+
+	return p.parseUnary(fields)
+}
+
+func (p *parser) parseTags(field string) (node, error) {
+	p.pos++ // {
+
+	var tags []string
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return nil, errors.New("Syntax error: missing }")
+		}
+
+		r := p.peek()
+		switch {
+		case r == '\\' && p.pos+1 < len(p.input):
+			sb.WriteRune(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		case r == '|' || r == '}':
+			tag := strings.Trim(strings.TrimSpace(sb.String()), "\"")
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+			sb.Reset()
+			p.pos++
+			if r == '}' {
+				return tagNode{field: field, tags: tags}, nil
+			}
+			continue
+		}
+
+		sb.WriteRune(r)
+		p.pos++
+	}
+}
+
+func (p *parser) parseNumeric(field string) (node, error) {
+	p.pos++ // [
+
+	start := p.pos
+	for !p.eof() && p.peek() != ']' {
+		p.pos++
+	}
+	if p.eof() {
+		return nil, errors.New("Syntax error: missing ]")
+	}
+	parts := strings.Fields(string(p.input[start:p.pos]))
+	p.pos++ // ]
+
+	if len(parts) != 2 {
+		return nil, errors.New("Syntax error: numeric range needs min and max")
+	}
+
+	min, exclMin, err := parseBound(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	max, exclMax, err := parseBound(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return numericNode{field: field, min: min, max: max, exclMin: exclMin, exclMax: exclMax}, nil
+}
+
+// parseBound reads a ZRANGE style bound: 10, (10, -inf, inf, +inf
+func parseBound(s string) (float64, bool, error) {
+	exclusive := strings.HasPrefix(s, "(")
+	s = strings.TrimPrefix(s, "(")
+
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		return math.Inf(1), exclusive, nil
+	case "-inf":
+		return math.Inf(-1), exclusive, nil
+	}
+
+	val, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, errors.New("Bad lower range: " + s)
+	}
+
+	return val, exclusive, nil
+}
@@ -0,0 +1,123 @@
+package redisearchtest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// status is written as a RESP simple string (+OK)
+type status string
+
+// readCommand reads one RESP array of bulk strings. Inline commands are split on spaces.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) == 0 {
+		return nil, nil
+	}
+
+	if line[0] != '*' {
+		return splitInline(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid multibulk length %q", line)
+	}
+
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(line) == 0 || line[0] != '$' {
+			return nil, fmt.Errorf("expected bulk string, got %q", line)
+		}
+
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid bulk length %q", line)
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("invalid line ending")
+	}
+
+	return line[:len(line)-2], nil
+}
+
+func splitInline(line string) []string {
+	var args []string
+	start := -1
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == ' ' {
+			if start >= 0 {
+				args = append(args, line[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+
+	return args
+}
+
+// writeReply writes a Go value as RESP2:
+// status, error, int/int64, string, float64, nil and []interface{}
+func writeReply(w *bufio.Writer, v interface{}) {
+	switch val := v.(type) {
+	case nil:
+		w.WriteString("$-1\r\n")
+	case status:
+		w.WriteString("+" + string(val) + "\r\n")
+	case error:
+		w.WriteString("-" + val.Error() + "\r\n")
+	case int:
+		w.WriteString(":" + strconv.Itoa(val) + "\r\n")
+	case int64:
+		w.WriteString(":" + strconv.FormatInt(val, 10) + "\r\n")
+	case float64:
+		writeReply(w, strconv.FormatFloat(val, 'f', -1, 64))
+	case string:
+		w.WriteString("$" + strconv.Itoa(len(val)) + "\r\n" + val + "\r\n")
+	case []string:
+		w.WriteString("*" + strconv.Itoa(len(val)) + "\r\n")
+		for _, item := range val {
+			writeReply(w, item)
+		}
+	case []interface{}:
+		w.WriteString("*" + strconv.Itoa(len(val)) + "\r\n")
+		for _, item := range val {
+			writeReply(w, item)
+		}
+	default:
+		writeReply(w, fmt.Errorf("ERR unsupported reply type %T", v))
+	}
+}
@@ -0,0 +1,556 @@
+// Package redisearchtest provides an in-memory RediSearch server for unit tests.
+//
+// The server speaks RESP2 on a local port, so any go-redis client can connect to it:
+//
+//	srv, err := redisearchtest.NewServer()
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer srv.Close()
+//
+//	client := redisearch.NewRedisearchClient("test", []string{srv.Addr()}, []int{10}, []int{0}, []int{0})
+//
+// It emulates hashes (HSET, HGETALL, DEL, SCAN ...), MULTI/EXEC transactions and a subset of RediSearch: FT.CREATE, FT.ALTER,
+// FT.DROPINDEX, FT.INFO, FT._LIST, FT.SEARCH, FT.TAGVALS, aliases, suggestions, dictionaries
+// and synonym groups (stored, not expanded in queries). FT.AGGREGATE only counts values:
+// LOAD, APPLY split(), GROUPBY with REDUCE COUNT, SORTBY and LIMIT.
+// See query.go for the supported query syntax.
+package redisearchtest
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type field struct {
+	identifier    string
+	attribute     string
+	typ           string // TEXT, NUMERIC, TAG, GEO
+	sortable      bool
+	unf           bool
+	noindex       bool
+	nostem        bool
+	casesensitive bool
+	weight        float64
+	phonetic      string
+	separator     string
+}
+
+// name returns the attribute used in queries
+func (f *field) name() string {
+	if f.attribute != "" {
+		return f.attribute
+	}
+
+	return f.identifier
+}
+
+type index struct {
+	name         string
+	datatype     string
+	prefixes     []string
+	filter       string
+	language     string
+	langfield    string
+	score        string
+	scorefield   string
+	payloadfield string
+	options      []string // NOOFFSETS, NOFIELDS ...
+	stopwords    []string // nil with the default stop words
+	fields       []*field
+	synonyms     map[string][]string // group id: terms, only stored for FT.SYNDUMP
+}
+
+func (ix *index) field(name string) *field {
+	for _, f := range ix.fields {
+		if f.name() == name {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// textFields returns the named TEXT fields, all indexed TEXT fields when names is nil
+func (ix *index) textFields(names []string) []*field {
+	var fields []*field
+	for _, f := range ix.fields {
+		if f.typ != "TEXT" || f.noindex {
+			continue
+		}
+
+		if names == nil {
+			fields = append(fields, f)
+			continue
+		}
+
+		for _, n := range names {
+			if f.name() == n {
+				fields = append(fields, f)
+				break
+			}
+		}
+	}
+
+	return fields
+}
+
+// covers reports whether the key is indexed by prefix
+func (ix *index) covers(key string) bool {
+	if len(ix.prefixes) == 0 {
+		return true
+	}
+
+	for _, p := range ix.prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type suggestion struct {
+	score   float64
+	payload string
+}
+
+// Server is an in-memory RediSearch server listening on a local port
+type Server struct {
+	ln      net.Listener
+	mu      sync.Mutex
+	hashes  map[string]map[string]string
+	indexes map[string]*index
+	aliases map[string]string
+	sugs    map[string]map[string]*suggestion
+	dicts   map[string]map[string]bool
+	conns   map[net.Conn]bool
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+// NewServer starts a server on a random local port
+func NewServer() (*Server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		ln:      ln,
+		hashes:  make(map[string]map[string]string),
+		indexes: make(map[string]*index),
+		aliases: make(map[string]string),
+		sugs:    make(map[string]map[string]*suggestion),
+		dicts:   make(map[string]map[string]bool),
+		conns:   make(map[net.Conn]bool),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr returns host:port of the server
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Close stops the server and closes all client connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	s.wg.Wait()
+
+	return err
+}
+
+// FlushAll removes all keys, indexes, suggestions and dictionaries
+func (s *Server) FlushAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.hashes = make(map[string]map[string]string)
+	s.indexes = make(map[string]*index)
+	s.aliases = make(map[string]string)
+	s.sugs = make(map[string]map[string]*suggestion)
+	s.dicts = make(map[string]map[string]bool)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+
+	// commands queued after MULTI, nil outside a transaction
+	var queued [][]string
+
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		var reply interface{}
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "MULTI" && queued != nil:
+			reply = errors.New("ERR MULTI calls can not be nested")
+		case cmd == "MULTI":
+			queued = [][]string{}
+			reply = status("OK")
+		case cmd == "EXEC" && queued == nil:
+			reply = errors.New("ERR EXEC without MULTI")
+		case cmd == "EXEC":
+			reply = s.execAll(queued)
+			queued = nil
+		case cmd == "DISCARD" && queued == nil:
+			reply = errors.New("ERR DISCARD without MULTI")
+		case cmd == "DISCARD":
+			queued = nil
+			reply = status("OK")
+		case queued != nil:
+			queued = append(queued, args)
+			reply = status("QUEUED")
+		default:
+			reply = s.exec(args)
+		}
+
+		writeReply(w, reply)
+
+		// flush once the pipeline is drained
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+type handler func(s *Server, args []string) interface{}
+
+var handlers = map[string]handler{
+	"PING":           cmdPing,
+	"ECHO":           cmdEcho,
+	"SELECT":         cmdOK,
+	"CLIENT":         cmdOK,
+	"FLUSHALL":       cmdFlushAll,
+	"FLUSHDB":        cmdFlushAll,
+	"HSET":           cmdHSet,
+	"HMSET":          cmdHMSet,
+	"HGET":           cmdHGet,
+	"HMGET":          cmdHMGet,
+	"HGETALL":        cmdHGetAll,
+	"HDEL":           cmdHDel,
+	"DEL":            cmdDel,
+	"EXISTS":         cmdExists,
+	"KEYS":           cmdKeys,
+	"SCAN":           cmdScan,
+	"FT.CREATE":      cmdFtCreate,
+	"FT.ALTER":       cmdFtAlter,
+	"FT.DROPINDEX":   cmdFtDropIndex,
+	"FT._LIST":       cmdFtList,
+	"FT.INFO":        cmdFtInfo,
+	"FT.SEARCH":      cmdFtSearch,
+	"FT.AGGREGATE":   cmdFtAggregate,
+	"FT.TAGVALS":     cmdFtTagVals,
+	"FT.ALIASADD":    cmdFtAliasAdd,
+	"FT.ALIASUPDATE": cmdFtAliasUpdate,
+	"FT.ALIASDEL":    cmdFtAliasDel,
+	"FT.SUGADD":      cmdFtSugAdd,
+	"FT.SUGGET":      cmdFtSugGet,
+	"FT.SUGDEL":      cmdFtSugDel,
+	"FT.SUGLEN":      cmdFtSugLen,
+	"FT.DICTADD":     cmdFtDictAdd,
+	"FT.DICTDEL":     cmdFtDictDel,
+	"FT.DICTDUMP":    cmdFtDictDump,
+	"FT.SYNUPDATE":   cmdFtSynUpdate,
+	"FT.SYNDUMP":     cmdFtSynDump,
+}
+
+func (s *Server) exec(args []string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.run(args)
+}
+
+// execAll runs the commands of a transaction without letting other connections in between
+func (s *Server) execAll(cmds [][]string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replies := make([]interface{}, len(cmds))
+	for i, args := range cmds {
+		replies[i] = s.run(args)
+	}
+
+	return replies
+}
+
+func (s *Server) run(args []string) interface{} {
+	h, ok := handlers[strings.ToUpper(args[0])]
+	if !ok {
+		return errors.New("ERR unknown command '" + args[0] + "'")
+	}
+
+	return h(s, args[1:])
+}
+
+func errArgs(cmd string) error {
+	return errors.New("ERR wrong number of arguments for '" + strings.ToLower(cmd) + "' command")
+}
+
+func cmdPing(s *Server, args []string) interface{} {
+	if len(args) > 0 {
+		return args[0]
+	}
+
+	return status("PONG")
+}
+
+func cmdEcho(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("echo")
+	}
+
+	return args[0]
+}
+
+func cmdOK(s *Server, args []string) interface{} {
+	return status("OK")
+}
+
+func cmdFlushAll(s *Server, args []string) interface{} {
+	s.hashes = make(map[string]map[string]string)
+	s.indexes = make(map[string]*index)
+	s.aliases = make(map[string]string)
+	s.sugs = make(map[string]map[string]*suggestion)
+	s.dicts = make(map[string]map[string]bool)
+
+	return status("OK")
+}
+
+func cmdHSet(s *Server, args []string) interface{} {
+	if len(args) < 3 || len(args)%2 != 1 {
+		return errArgs("hset")
+	}
+
+	h, ok := s.hashes[args[0]]
+	if !ok {
+		h = make(map[string]string)
+		s.hashes[args[0]] = h
+	}
+
+	var added int64
+	for i := 1; i < len(args); i += 2 {
+		if _, ok := h[args[i]]; !ok {
+			added++
+		}
+		h[args[i]] = args[i+1]
+	}
+
+	return added
+}
+
+func cmdHMSet(s *Server, args []string) interface{} {
+	if reply := cmdHSet(s, args); isError(reply) {
+		return reply
+	}
+
+	return status("OK")
+}
+
+func cmdHGet(s *Server, args []string) interface{} {
+	if len(args) != 2 {
+		return errArgs("hget")
+	}
+
+	val, ok := s.hashes[args[0]][args[1]]
+	if !ok {
+		return nil
+	}
+
+	return val
+}
+
+func cmdHMGet(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return errArgs("hmget")
+	}
+
+	res := make([]interface{}, 0, len(args)-1)
+	for _, f := range args[1:] {
+		if val, ok := s.hashes[args[0]][f]; ok {
+			res = append(res, val)
+		} else {
+			res = append(res, nil)
+		}
+	}
+
+	return res
+}
+
+func cmdHGetAll(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("hgetall")
+	}
+
+	h := s.hashes[args[0]]
+	fields := make([]string, 0, len(h))
+	for f := range h {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	res := make([]interface{}, 0, len(h)*2)
+	for _, f := range fields {
+		res = append(res, f, h[f])
+	}
+
+	return res
+}
+
+func cmdHDel(s *Server, args []string) interface{} {
+	if len(args) < 2 {
+		return errArgs("hdel")
+	}
+
+	h, ok := s.hashes[args[0]]
+	if !ok {
+		return int64(0)
+	}
+
+	var deleted int64
+	for _, f := range args[1:] {
+		if _, ok := h[f]; ok {
+			delete(h, f)
+			deleted++
+		}
+	}
+
+	if len(h) == 0 {
+		delete(s.hashes, args[0])
+	}
+
+	return deleted
+}
+
+func cmdDel(s *Server, args []string) interface{} {
+	if len(args) == 0 {
+		return errArgs("del")
+	}
+
+	var deleted int64
+	for _, key := range args {
+		if _, ok := s.hashes[key]; ok {
+			delete(s.hashes, key)
+			deleted++
+		}
+	}
+
+	return deleted
+}
+
+func cmdExists(s *Server, args []string) interface{} {
+	if len(args) == 0 {
+		return errArgs("exists")
+	}
+
+	var n int64
+	for _, key := range args {
+		if _, ok := s.hashes[key]; ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+// KEYS supports prefix patterns like drd:*
+func cmdKeys(s *Server, args []string) interface{} {
+	if len(args) != 1 {
+		return errArgs("keys")
+	}
+
+	prefix := strings.TrimSuffix(args[0], "*")
+	exact := !strings.HasSuffix(args[0], "*")
+
+	var keys []string
+	for key := range s.hashes {
+		if (exact && key == args[0]) || (!exact && strings.HasPrefix(key, prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// cmdScan returns every matching key in one page, so the next cursor is always 0
+func cmdScan(s *Server, args []string) interface{} {
+	if len(args) == 0 || len(args)%2 != 1 {
+		return errArgs("scan")
+	}
+
+	pattern := "*"
+	for i := 1; i < len(args); i += 2 {
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT", "TYPE":
+		default:
+			return errors.New("ERR syntax error")
+		}
+	}
+
+	return []interface{}{"0", cmdKeys(s, []string{pattern})}
+}
+
+func isError(v interface{}) bool {
+	_, ok := v.(error)
+	return ok
+}
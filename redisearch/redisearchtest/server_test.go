@@ -0,0 +1,225 @@
+package redisearchtest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestClient(t *testing.T) (*Server, *redis.Client) {
+	t.Helper()
+
+	srv, err := NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+
+	t.Cleanup(func() {
+		client.Close()
+		srv.Close()
+	})
+
+	return srv, client
+}
+
+func seed(t *testing.T, client *redis.Client) {
+	t.Helper()
+
+	ctx := context.Background()
+	cmds := [][]interface{}{
+		{"FT.CREATE", "idx:drd", "ON", "HASH", "PREFIX", 1, "drd:", "SCHEMA",
+			"name", "TEXT", "SORTABLE", "slug", "TEXT", "NOSTEM", "cats", "TAG", "SEPARATOR", ",", "updated", "NUMERIC", "SORTABLE"},
+		{"HSET", "drd:1", "name", "Dream Test 1", "slug", "dream-test-1", "cats", "dream,test", "updated", "100"},
+		{"HSET", "drd:2", "name", "Dream Hello", "slug", "dream-hello", "cats", "dream", "updated", "200"},
+		{"HSET", "drd:3", "name", "Hello World", "slug", "hello-world", "cats", "world", "updated", "300"},
+		{"HSET", "other:1", "name", "Dream Outside", "updated", "400"},
+	}
+
+	for _, cmd := range cmds {
+		if err := client.Do(ctx, cmd...).Err(); err != nil {
+			t.Fatalf("%v: %v", cmd, err)
+		}
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	_, client := newTestClient(t)
+	seed(t, client)
+
+	tests := []struct {
+		name    string
+		args    []interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "Term",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "hello", "NOCONTENT"},
+			want: []interface{}{int64(2), "drd:2", "drd:3"},
+		},
+		{
+			name: "Intersection",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "dream hello", "NOCONTENT"},
+			want: []interface{}{int64(1), "drd:2"},
+		},
+		{
+			name: "Union And Negation",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "(test|world) -hello", "NOCONTENT"},
+			want: []interface{}{int64(1), "drd:1"},
+		},
+		{
+			name: "Prefix",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "wor*", "NOCONTENT"},
+			want: []interface{}{int64(1), "drd:3"},
+		},
+		{
+			name: "Field Modifier",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "@slug:test", "NOCONTENT"},
+			want: []interface{}{int64(1), "drd:1"},
+		},
+		{
+			name: "Tags",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "@cats:{test | world}", "NOCONTENT", "SORTBY", "updated", "DESC"},
+			want: []interface{}{int64(2), "drd:3", "drd:1"},
+		},
+		{
+			name: "Numeric Range",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "@updated:[(100 +inf]", "NOCONTENT", "SORTBY", "updated"},
+			want: []interface{}{int64(2), "drd:2", "drd:3"},
+		},
+		{
+			name: "Filter",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "*", "NOCONTENT", "FILTER", "updated", "150", "300", "SORTBY", "updated", "ASC"},
+			want: []interface{}{int64(2), "drd:2", "drd:3"},
+		},
+		{
+			name: "Sort And Limit",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "*", "NOCONTENT", "SORTBY", "updated", "DESC", "LIMIT", "1", "1"},
+			want: []interface{}{int64(3), "drd:2"},
+		},
+		{
+			name: "Return Fields With Sort Keys",
+			args: []interface{}{"FT.SEARCH", "idx:drd", "@name:world", "WITHSORTKEYS", "RETURN", "1", "name", "SORTBY", "name"},
+			want: []interface{}{int64(1), "drd:3", "$hello world", []interface{}{"name", "Hello World"}},
+		},
+		{
+			name:    "Unsortable Field",
+			args:    []interface{}{"FT.SEARCH", "idx:drd", "*", "SORTBY", "slug"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown Index",
+			args:    []interface{}{"FT.SEARCH", "idx:none", "*"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Do(context.Background(), tt.args...).Result()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FT.SEARCH error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FT.SEARCH = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_Suggestions(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, cmd := range [][]interface{}{
+		{"FT.SUGADD", "sug", "hello", "1"},
+		{"FT.SUGADD", "sug", "help", "2"},
+		{"FT.SUGADD", "sug", "hello", "2", "INCR"},
+		{"FT.SUGADD", "sug", "world", "5"},
+	} {
+		if err := client.Do(ctx, cmd...).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := client.Do(ctx, "FT.SUGGET", "sug", "HEL", "WITHSCORES").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []interface{}{"hello", "3", "help", "2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FT.SUGGET = %v, want %v", got, want)
+	}
+
+	if n, _ := client.Do(ctx, "FT.SUGLEN", "sug").Int64(); n != 3 {
+		t.Errorf("FT.SUGLEN = %d, want 3", n)
+	}
+}
+
+func TestServer_InfoAndDrop(t *testing.T) {
+	_, client := newTestClient(t)
+	seed(t, client)
+	ctx := context.Background()
+
+	if err := client.Do(ctx, "FT.ALIASADD", "drd", "idx:drd").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := client.Do(ctx, "FT.INFO", "drd").Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(info); i += 2 {
+		fields[info[i].(string)] = info[i+1]
+	}
+
+	if fields["index_name"] != "idx:drd" || fields["num_docs"] != "3" {
+		t.Errorf("FT.INFO = %v", info)
+	}
+
+	if attrs := fields["attributes"].([]interface{}); len(attrs) != 4 {
+		t.Errorf("FT.INFO attributes = %v, want 4", attrs)
+	}
+
+	if err := client.Do(ctx, "FT.DROPINDEX", "idx:drd", "DD").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := client.Keys(ctx, "*").Result()
+	if !reflect.DeepEqual(keys, []string{"other:1"}) {
+		t.Errorf("KEYS after DROPINDEX DD = %v, want [other:1]", keys)
+	}
+
+	if err := client.Do(ctx, "FT.INFO", "drd").Err(); err == nil {
+		t.Error("FT.INFO on dropped alias should fail")
+	}
+}
+
+func Test_parseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "All", query: "*"},
+		{name: "Nested", query: "(@name:dream|@slug:hello) -@cats:{world} ~@updated:[1 (10]"},
+		{name: "Phrase", query: `"dream test"`},
+		{name: "Escaped Tag", query: `@cats:{dream\ test}`},
+		{name: "Empty", query: "  ", wantErr: true},
+		{name: "Unclosed Group", query: "(dream", wantErr: true},
+		{name: "Bad Range", query: "@updated:[1]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseQuery(tt.query); (err != nil) != tt.wantErr {
+				t.Errorf("parseQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
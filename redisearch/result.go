@@ -0,0 +1,205 @@
+package redisearch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Single FT.SEARCH result
+type Document struct {
+	ID      string
+	Score   float64
+	Payload string
+	SortKey string
+	Fields  map[string]string
+}
+
+// Typed FT.SEARCH reply
+type SearchResult struct {
+	Total int64
+	Docs  []Document
+}
+
+// Typed FT.AGGREGATE and FT.CURSOR READ reply
+type AggregateResult struct {
+	Total    int64
+	Rows     []map[string]string
+	CursorID int64 // 0 when the cursor is exhausted or not requested
+}
+
+/*
+FT.SEARCH reply: total, then for every document:
+
+	id [score] [payload] [sortkey] [[field value ...]]
+*/
+func parseSearchResult(reply []interface{}, fts *FtSearch) (*SearchResult, error) {
+	if len(reply) == 0 {
+		return nil, errors.New("redisearch: empty search reply")
+	}
+
+	total, err := replyInt64(reply[0])
+	if err != nil {
+		return nil, err
+	}
+
+	withContent := !fts.nocontent && !(fts.returnfields != nil && len(fts.returnfields) == 0)
+
+	res := &SearchResult{Total: total}
+	for i := 1; i < len(reply); {
+		var doc Document
+
+		doc.ID = replyString(reply[i])
+		i++
+
+		if fts.withscores && i < len(reply) {
+			if doc.Score, err = replyFloat64(reply[i]); err != nil {
+				return nil, err
+			}
+			i++
+		}
+
+		if fts.withpayloads && i < len(reply) {
+			doc.Payload = replyString(reply[i])
+			i++
+		}
+
+		if fts.withsortkeys && i < len(reply) {
+			doc.SortKey = replyString(reply[i])
+			i++
+		}
+
+		if withContent && i < len(reply) {
+			fields, ok := reply[i].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("redisearch: unexpected document fields type %T", reply[i])
+			}
+			doc.Fields = replyStringMap(fields)
+			i++
+		}
+
+		res.Docs = append(res.Docs, doc)
+	}
+
+	return res, nil
+}
+
+/*
+FT.AGGREGATE reply: total, then one [property value ...] row per result.
+WITHCURSOR wraps it: [[total, rows ...], cursor_id]
+*/
+func parseAggregateResult(reply interface{}, withCursor bool) (*AggregateResult, error) {
+	res := &AggregateResult{}
+
+	rows, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redisearch: unexpected aggregate reply type %T", reply)
+	}
+
+	if withCursor {
+		if len(rows) != 2 {
+			return nil, errors.New("redisearch: unexpected cursor reply")
+		}
+
+		cursorID, err := replyInt64(rows[1])
+		if err != nil {
+			return nil, err
+		}
+		res.CursorID = cursorID
+
+		if rows, ok = rows[0].([]interface{}); !ok {
+			return nil, fmt.Errorf("redisearch: unexpected aggregate reply type %T", rows[0])
+		}
+	}
+
+	if len(rows) == 0 {
+		return res, nil
+	}
+
+	total, err := replyInt64(rows[0])
+	if err != nil {
+		return nil, err
+	}
+	res.Total = total
+
+	for _, r := range rows[1:] {
+		fields, ok := r.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("redisearch: unexpected aggregate row type %T", r)
+		}
+		res.Rows = append(res.Rows, replyStringMap(fields))
+	}
+
+	return res, nil
+}
+
+// reply helpers: go-redis returns RESP2 values as string, int64, []interface{} or nil
+
+func replyString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(v)
+}
+
+func replyInt64(v interface{}) (int64, error) {
+	switch val := v.(type) {
+	case int64:
+		return val, nil
+	case string:
+		return strconv.ParseInt(val, 10, 64)
+	case error:
+		return 0, val
+	}
+
+	return 0, fmt.Errorf("redisearch: unexpected integer reply type %T", v)
+}
+
+func replyFloat64(v interface{}) (float64, error) {
+	switch val := v.(type) {
+	case int64:
+		return float64(val), nil
+	case float64:
+		return val, nil
+	case string:
+		return strconv.ParseFloat(val, 64)
+	case error:
+		return 0, val
+	}
+
+	return 0, fmt.Errorf("redisearch: unexpected float reply type %T", v)
+}
+
+func replyStringSlice(v interface{}) []string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		res = append(res, replyString(item))
+	}
+
+	return res
+}
+
+// replyStringMap converts a flat [key value key value ...] array
+func replyStringMap(items []interface{}) map[string]string {
+	res := make(map[string]string, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		res[replyString(items[i])] = replyString(items[i+1])
+	}
+
+	return res
+}
@@ -0,0 +1,399 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// withExecutor returns a client for a single shard that shares the hooks of rsc
+func (rsc *RedisearchClient) withExecutor(executor Executor) *RedisearchClient {
+	return &RedisearchClient{
+		Name:     rsc.Name,
+		Executor: executor,
+		Ctx:      rsc.Ctx,
+		hooks:    rsc.hooks,
+	}
+}
+
+// forEachShard runs fn concurrently on every shard and returns the first error
+func forEachShard(shards []*RedisearchClient, fn func(i int, shard *RedisearchClient) error) error {
+	errs := make([]error, len(shards))
+
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard *RedisearchClient) {
+			defer wg.Done()
+			errs[i] = fn(i, shard)
+		}(i, shard)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// doOnShards sends the same command to every shard
+func doOnShards(ctx context.Context, shards []*RedisearchClient, args ...interface{}) error {
+	return forEachShard(shards, func(i int, shard *RedisearchClient) error {
+		return shard.do(ctx, args...).Err()
+	})
+}
+
+// scatterSearch asks every shard for its first offset+num documents and merges them by score,
+// or by sort key when the search has SORTBY. Total is the sum of the shard totals.
+// A count-only search (LIMIT 0 0) is sent as is and returns the Total alone.
+func scatterSearch(ctx context.Context, shards []*RedisearchClient, fts *FtSearch) (*SearchResult, error) {
+	offset, num := fts.limit.offset, fts.limit.num
+	if num <= 0 {
+		if fts.limit.set {
+			return scatterCount(ctx, shards, fts)
+		}
+		offset, num = 0, 10
+	}
+
+	clone := *fts
+	clone.AddLimit(0, offset+num)
+	sorted := clone.sortby.attribute != ""
+	if sorted {
+		clone.AddWithSortKeys(true)
+	} else {
+		clone.AddWithScores(true)
+	}
+
+	results := make([]*SearchResult, len(shards))
+	err := forEachShard(shards, func(i int, shard *RedisearchClient) error {
+		res, err := shard.DoSearch(ctx, &clone)
+		results[i] = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &SearchResult{}
+	for _, res := range results {
+		merged.Total += res.Total
+		merged.Docs = append(merged.Docs, res.Docs...)
+	}
+
+	sort.SliceStable(merged.Docs, func(i, j int) bool {
+		if sorted {
+			return lessSortKey(merged.Docs[i].SortKey, merged.Docs[j].SortKey, clone.sortby.asc)
+		}
+		return merged.Docs[i].Score > merged.Docs[j].Score
+	})
+
+	if offset >= int64(len(merged.Docs)) {
+		merged.Docs = nil
+	} else {
+		end := offset + num
+		if end > int64(len(merged.Docs)) {
+			end = int64(len(merged.Docs))
+		}
+		merged.Docs = merged.Docs[offset:end]
+	}
+
+	// strip what the caller did not ask for
+	for i := range merged.Docs {
+		if !fts.withscores {
+			merged.Docs[i].Score = 0
+		}
+		if !fts.withsortkeys {
+			merged.Docs[i].SortKey = ""
+		}
+	}
+
+	return merged, nil
+}
+
+// scatterCount sums the totals of a LIMIT 0 0 search
+func scatterCount(ctx context.Context, shards []*RedisearchClient, fts *FtSearch) (*SearchResult, error) {
+	totals := make([]int64, len(shards))
+	err := forEachShard(shards, func(i int, shard *RedisearchClient) error {
+		res, err := shard.DoSearch(ctx, fts)
+		if err != nil {
+			return err
+		}
+		totals[i] = res.Total
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &SearchResult{}
+	for _, total := range totals {
+		res.Total += total
+	}
+
+	return res, nil
+}
+
+// lessSortKey compares WITHSORTKEYS values: #number, $string or none. Missing values sort last.
+func lessSortKey(a, b string, asc bool) bool {
+	aNone, bNone := a == "" || a == "none", b == "" || b == "none"
+	if aNone || bNone {
+		return !aNone && bNone
+	}
+
+	if strings.HasPrefix(a, "#") && strings.HasPrefix(b, "#") {
+		x, errX := strconv.ParseFloat(a[1:], 64)
+		y, errY := strconv.ParseFloat(b[1:], 64)
+		if errX == nil && errY == nil {
+			if asc {
+				return x < y
+			}
+			return x > y
+		}
+	}
+
+	a, b = strings.TrimLeft(a, "#$"), strings.TrimLeft(b, "#$")
+	if asc {
+		return a < b
+	}
+
+	return a > b
+}
+
+// Reducers whose shard results can be combined
+var mergeableReducers = map[string]bool{
+	ReducerCount: true,
+	ReducerSum:   true,
+	ReducerMin:   true,
+	ReducerMax:   true,
+}
+
+/*
+scatterAggregate merges the aggregations of every shard. Supported pipelines:
+
+	[APPLY|FILTER ...] GROUPBY ... REDUCE COUNT|SUM|MIN|MAX ... AS {alias} [SORTBY ...] [LIMIT ...]
+	[APPLY|FILTER ...] [SORTBY ...] [LIMIT ...]
+
+Steps before GROUPBY run on the shards, SORTBY and LIMIT after it run on the merged rows.
+*/
+func scatterAggregate(ctx context.Context, shards []*RedisearchClient, fta *FtAggregate) (*AggregateResult, error) {
+	if fta.withcursor {
+		return nil, errors.New("redisearch: WITHCURSOR can not be merged across shards")
+	}
+
+	group := -1
+	for i, s := range fta.steps {
+		if s.step == aggregateStepGroupBy {
+			if group >= 0 {
+				return nil, errors.New("redisearch: only one GROUPBY can be merged across shards")
+			}
+			group = i
+		}
+	}
+
+	// the tail runs locally: everything after GROUPBY, or the trailing SORTBY and LIMIT steps
+	tail := len(fta.steps)
+	if group >= 0 {
+		tail = group + 1
+	} else {
+		for tail > 0 && (fta.steps[tail-1].step == aggregateStepSortBy || fta.steps[tail-1].step == aggregateStepLimit) {
+			tail--
+		}
+	}
+
+	for _, s := range fta.steps[tail:] {
+		if s.step != aggregateStepSortBy && s.step != aggregateStepLimit {
+			return nil, fmt.Errorf("redisearch: %s after GROUPBY can not be merged across shards", s.step)
+		}
+	}
+
+	if group >= 0 {
+		for _, r := range fta.steps[group].reducers {
+			if !mergeableReducers[strings.ToUpper(r.function)] {
+				return nil, fmt.Errorf("redisearch: reducer %s can not be merged across shards", r.function)
+			}
+			if r.alias == "" {
+				return nil, fmt.Errorf("redisearch: reducer %s needs an alias to be merged across shards", r.function)
+			}
+		}
+	}
+
+	clone := *fta
+	clone.steps = append([]FtAggregateStep(nil), fta.steps[:tail]...)
+
+	// without GROUPBY every shard only needs the sorted rows of the first window,
+	// the offset of the caller is applied once on the merged rows
+	if group < 0 {
+		if limit := windowEnd(fta.steps[tail:]); limit > 0 {
+			for _, s := range fta.steps[tail:] {
+				if s.step == aggregateStepLimit {
+					break
+				}
+				clone.steps = append(clone.steps, s)
+			}
+			clone.steps = append(clone.steps, FtAggregateStep{step: aggregateStepLimit, offset: 0, num: limit})
+		}
+	}
+
+	results := make([]*AggregateResult, len(shards))
+	err := forEachShard(shards, func(i int, shard *RedisearchClient) error {
+		res, err := shard.Aggregate(ctx, &clone)
+		results[i] = res
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []map[string]string
+	var total int64
+	if group >= 0 {
+		rows = mergeGroups(results, fta.steps[group])
+		total = int64(len(rows))
+	} else {
+		for _, res := range results {
+			total += res.Total
+			rows = append(rows, res.Rows...)
+		}
+	}
+
+	for _, s := range fta.steps[tail:] {
+		switch s.step {
+		case aggregateStepSortBy:
+			sortRows(rows, s.sortby)
+			if s.sortmax > 0 && len(rows) > s.sortmax {
+				rows = rows[:s.sortmax]
+			}
+		case aggregateStepLimit:
+			rows = limitRows(rows, s.offset, s.num)
+		}
+	}
+
+	return &AggregateResult{Total: total, Rows: rows}, nil
+}
+
+// windowEnd returns the number of rows the SORTBY and LIMIT steps can reach, 0 when unbounded
+func windowEnd(steps []FtAggregateStep) int64 {
+	for _, s := range steps {
+		if s.step == aggregateStepLimit {
+			return s.offset + s.num
+		}
+	}
+
+	return 0
+}
+
+// mergeGroups combines rows with the same group values
+func mergeGroups(results []*AggregateResult, step FtAggregateStep) []map[string]string {
+	keys := make([]string, len(step.groupby))
+	for i, p := range step.groupby {
+		keys[i] = strings.TrimPrefix(p, "@")
+	}
+
+	index := make(map[string]map[string]string)
+	var rows []map[string]string
+
+	for _, res := range results {
+		for _, row := range res.Rows {
+			var sb strings.Builder
+			for _, k := range keys {
+				sb.WriteString(row[k])
+				sb.WriteByte(0)
+			}
+			id := sb.String()
+
+			merged, ok := index[id]
+			if !ok {
+				merged = make(map[string]string, len(row))
+				for k, v := range row {
+					merged[k] = v
+				}
+				index[id] = merged
+				rows = append(rows, merged)
+				continue
+			}
+
+			for _, r := range step.reducers {
+				merged[r.alias] = mergeReduced(strings.ToUpper(r.function), merged[r.alias], row[r.alias])
+			}
+		}
+	}
+
+	return rows
+}
+
+func mergeReduced(function string, a, b string) string {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	switch {
+	case errX != nil:
+		return b
+	case errY != nil:
+		return a
+	}
+
+	var v float64
+	switch function {
+	case ReducerMin:
+		v = x
+		if y < x {
+			v = y
+		}
+	case ReducerMax:
+		v = x
+		if y > x {
+			v = y
+		}
+	default: // COUNT, SUM
+		v = x + y
+	}
+
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// sortRows compares numbers numerically and everything else as strings
+func sortRows(rows []map[string]string, keys []FtSortKey) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			p := strings.TrimPrefix(k.property, "@")
+			a, b := rows[i][p], rows[j][p]
+			if a == b {
+				continue
+			}
+
+			x, errX := strconv.ParseFloat(a, 64)
+			y, errY := strconv.ParseFloat(b, 64)
+			if errX == nil && errY == nil {
+				if k.asc {
+					return x < y
+				}
+				return x > y
+			}
+
+			if k.asc {
+				return a < b
+			}
+			return a > b
+		}
+		return false
+	})
+}
+
+func limitRows(rows []map[string]string, offset, num int64) []map[string]string {
+	if offset >= int64(len(rows)) {
+		return nil
+	}
+
+	end := offset + num
+	if end > int64(len(rows)) {
+		end = int64(len(rows))
+	}
+
+	return rows[offset:end]
+}
@@ -0,0 +1,262 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// newTestShards creates idx:drd on two fake servers and splits the dream documents between them
+func newTestShards(t *testing.T) []*RedisearchClient {
+	t.Helper()
+
+	shards := []*RedisearchClient{newTestClient(t), newTestClient(t)}
+
+	ftc := NewFtCreate("idx:drd").
+		AddDataType(HASH).
+		AddPrefix("drd:")
+	ftc.AddSchema(FieldTypeText, "name", "", true, ftc.AddSchemaTextOption(1, false, false, "")).
+		AddSchema(FieldTypeNumeric, "updated", "", true, ftc.AddSchemaNumericOption(false))
+
+	if err := doOnShards(context.Background(), shards, ftc.Serialize()...); err != nil {
+		t.Fatal(err)
+	}
+
+	docs := []struct {
+		shard   int
+		key     string
+		name    string
+		updated string
+	}{
+		{0, "drd:1", "Dream Test 1", "100"},
+		{1, "drd:2", "Dream Test 2", "200"},
+		{0, "drd:3", "Dream Test 3", "300"},
+		{1, "drd:4", "Dream Test 4", "400"},
+		{1, "drd:5", "Hello World", "500"},
+	}
+	for _, doc := range docs {
+		if _, err := shards[doc.shard].HSet(doc.key, "name", doc.name, "updated", doc.updated); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return shards
+}
+
+func Test_scatterSearch(t *testing.T) {
+	shards := newTestShards(t)
+
+	tests := []struct {
+		name      string
+		fts       *FtSearch
+		wantTotal int64
+		wantIDs   []string
+	}{
+		{
+			name:      "Sort Descending First Page",
+			fts:       NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true).AddSortBy("updated", false).AddLimit(0, 2),
+			wantTotal: 4,
+			wantIDs:   []string{"drd:4", "drd:3"},
+		},
+		{
+			name:      "Sort Ascending Second Page",
+			fts:       NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true).AddSortBy("updated", true).AddLimit(2, 2),
+			wantTotal: 4,
+			wantIDs:   []string{"drd:3", "drd:4"},
+		},
+		{
+			name:      "Offset Past The End",
+			fts:       NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true).AddSortBy("updated", true).AddLimit(10, 2),
+			wantTotal: 4,
+			wantIDs:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := scatterSearch(context.Background(), shards, tt.fts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Total != tt.wantTotal {
+				t.Errorf("Total = %d, want %d", res.Total, tt.wantTotal)
+			}
+
+			var ids []string
+			for _, doc := range res.Docs {
+				ids = append(ids, doc.ID)
+				if doc.SortKey != "" {
+					t.Errorf("SortKey of %s = %q, sort keys were not requested", doc.ID, doc.SortKey)
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func Test_scatterSearch_Count(t *testing.T) {
+	a := NewRecordingExecutor(NewScriptedExecutor().AddReply([]interface{}{int64(2)}))
+	b := NewScriptedExecutor().AddReply([]interface{}{int64(3)})
+
+	shards := []*RedisearchClient{
+		NewRedisearchClientWithExecutor("a", a),
+		NewRedisearchClientWithExecutor("b", b),
+	}
+
+	res, err := scatterSearch(context.Background(), shards, NewFtSearch("idx:drd").AddQuery("dream").AddLimit(0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 5 || res.Docs != nil {
+		t.Errorf("scatterSearch() = %d %+v, want 5 without documents", res.Total, res.Docs)
+	}
+
+	// the shards only count too
+	sent := a.Commands()[0]
+	wantSent := []interface{}{"FT.SEARCH", "idx:drd", "dream", "LIMIT", int64(0), int64(0)}
+	if fmt.Sprint(sent) != fmt.Sprint(wantSent) {
+		t.Errorf("shard command = %v, want %v", sent, wantSent)
+	}
+}
+
+func Test_scatterSearch_Score(t *testing.T) {
+	a := NewScriptedExecutor().AddReply([]interface{}{int64(2), "drd:1", "3", "drd:2", "1"})
+	b := NewScriptedExecutor().AddReply([]interface{}{int64(2), "drd:3", "2", "drd:4", "0.5"})
+
+	shards := []*RedisearchClient{
+		NewRedisearchClientWithExecutor("a", a),
+		NewRedisearchClientWithExecutor("b", b),
+	}
+
+	res, err := scatterSearch(context.Background(), shards, NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true).AddWithScores(true).AddLimit(1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Document{{ID: "drd:3", Score: 2}, {ID: "drd:2", Score: 1}}
+	if res.Total != 4 || !reflect.DeepEqual(res.Docs, want) {
+		t.Errorf("scatterSearch() = %d %+v, want 4 %+v", res.Total, res.Docs, want)
+	}
+}
+
+func Test_scatterAggregate(t *testing.T) {
+	fta := NewFtAggregate("idx:drd").AddQuery("*")
+	fta.AddGroupBy([]string{"@cats"},
+		NewReducer(ReducerCount, "count"),
+		NewReducer(ReducerSum, "total", "@updated"),
+		NewReducer(ReducerMin, "first", "@updated"),
+		NewReducer(ReducerMax, "last", "@updated"),
+	).AddSortBy(0, NewSortKey("@count", false)).AddLimit(0, 2)
+
+	a := NewRecordingExecutor(NewScriptedExecutor().AddReply([]interface{}{
+		int64(2),
+		[]interface{}{"cats", "dream", "count", "2", "total", "300", "first", "100", "last", "200"},
+		[]interface{}{"cats", "world", "count", "1", "total", "300", "first", "300", "last", "300"},
+	}))
+	b := NewScriptedExecutor().AddReply([]interface{}{
+		int64(2),
+		[]interface{}{"cats", "dream", "count", "1", "total", "400", "first", "400", "last", "400"},
+		[]interface{}{"cats", "test", "count", "1", "total", "50", "first", "50", "last", "50"},
+	})
+
+	shards := []*RedisearchClient{
+		NewRedisearchClientWithExecutor("a", a),
+		NewRedisearchClientWithExecutor("b", b),
+	}
+
+	res, err := scatterAggregate(context.Background(), shards, fta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]string{
+		{"cats": "dream", "count": "3", "total": "700", "first": "100", "last": "400"},
+		{"cats": "world", "count": "1", "total": "300", "first": "300", "last": "300"},
+	}
+	if res.Total != 3 || !reflect.DeepEqual(res.Rows, want) {
+		t.Errorf("scatterAggregate() = %d %v, want 3 %v", res.Total, res.Rows, want)
+	}
+
+	// SORTBY and LIMIT run on the merged groups, not on the shards
+	sent := a.Commands()[0]
+	for _, arg := range sent {
+		if arg == aggregateStepSortBy || arg == aggregateStepLimit {
+			t.Errorf("shard command %v should stop at GROUPBY", sent)
+		}
+	}
+}
+
+func Test_scatterAggregate_Offset(t *testing.T) {
+	fta := NewFtAggregate("idx:drd").AddQuery("*").AddLoad("updated").
+		AddSortBy(0, NewSortKey("@updated", false)).AddLimit(1, 2)
+
+	a := NewRecordingExecutor(NewScriptedExecutor().AddReply([]interface{}{
+		int64(3),
+		[]interface{}{"updated", "500"},
+		[]interface{}{"updated", "300"},
+		[]interface{}{"updated", "100"},
+	}))
+	b := NewScriptedExecutor().AddReply([]interface{}{
+		int64(2),
+		[]interface{}{"updated", "400"},
+		[]interface{}{"updated", "200"},
+	})
+
+	shards := []*RedisearchClient{
+		NewRedisearchClientWithExecutor("a", a),
+		NewRedisearchClientWithExecutor("b", b),
+	}
+
+	res, err := scatterAggregate(context.Background(), shards, fta)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]string{{"updated": "400"}, {"updated": "300"}}
+	if res.Total != 5 || !reflect.DeepEqual(res.Rows, want) {
+		t.Errorf("scatterAggregate() = %d %v, want 5 %v", res.Total, res.Rows, want)
+	}
+
+	// shards sort and return the first offset+num rows, the offset is skipped once after the merge
+	sent := a.Commands()[0]
+	wantSent := []interface{}{
+		"FT.AGGREGATE", "idx:drd", "*", "LOAD", 1, "@updated",
+		"SORTBY", 2, "@updated", "DESC", "LIMIT", int64(0), int64(3),
+	}
+	if fmt.Sprint(sent) != fmt.Sprint(wantSent) {
+		t.Errorf("shard command = %v, want %v", sent, wantSent)
+	}
+}
+
+func Test_scatterAggregate_Unsupported(t *testing.T) {
+	shards := []*RedisearchClient{NewRedisearchClientWithExecutor("a", NewScriptedExecutor())}
+
+	avg := NewFtAggregate("idx:drd").AddQuery("*")
+	avg.AddGroupBy([]string{"@cats"}, NewReducer(ReducerAvg, "avg", "@updated"))
+
+	noAlias := NewFtAggregate("idx:drd").AddQuery("*")
+	noAlias.AddGroupBy([]string{"@cats"}, NewReducer(ReducerCount, ""))
+
+	apply := NewFtAggregate("idx:drd").AddQuery("*")
+	apply.AddGroupBy([]string{"@cats"}, NewReducer(ReducerCount, "count")).AddApply("@count*2", "double")
+
+	tests := []struct {
+		name string
+		fta  *FtAggregate
+	}{
+		{"Average Reducer", avg},
+		{"Reducer Without Alias", noAlias},
+		{"Apply After GroupBy", apply},
+		{"Cursor", NewFtAggregate("idx:drd").AddQuery("*").AddWithCursor(true, 10, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := scatterAggregate(context.Background(), shards, tt.fta); err == nil {
+				t.Error("scatterAggregate() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -1,11 +1,6 @@
 // Via: https://oss.redis.com/redisearch/Commands/#ftsearch
 package redisearch
 
-import (
-	"fmt"
-	"math"
-)
-
 /*
 FT.SEARCH {index} {query} [NOCONTENT] [VERBATIM] [NOSTOPWORDS] [WITHSCORES] [WITHPAYLOADS] [WITHSORTKEYS]
   [FILTER {numeric_attribute} {min} {max}] ...
@@ -79,6 +74,10 @@ type FtFilter struct {
 	exclusiveMax bool
 }
 
+func (f FtFilter) numericRange() NumericRange {
+	return NumericRange{Min: f.min, Max: f.max, ExclusiveMin: f.exclusiveMin, ExclusiveMax: f.exclusiveMax}
+}
+
 // Query Builder
 type FtSearch struct {
 	indexname    string
@@ -127,7 +126,9 @@ type FtSearch struct {
 	limit struct {
 		offset int64
 		num    int64
+		set    bool // LIMIT 0 0 only counts the documents
 	}
+	schema []IndexAttribute // checked by Validate
 }
 
 func NewFtSearch(indexName string) *FtSearch {
@@ -196,6 +197,11 @@ func (fts *FtSearch) AddFilter(field string, min, max float64, excludeMin, exclu
 	return fts
 }
 
+// AddNumericFilter adds FILTER {attribute} {min} {max}
+func (fts *FtSearch) AddNumericFilter(field string, r NumericRange) *FtSearch {
+	return fts.AddFilter(field, r.Min, r.Max, r.ExclusiveMin, r.ExclusiveMax)
+}
+
 func (fts *FtSearch) AddGeoFilter(field string, lon, lat, radius float64, unit Unit) *FtSearch {
 	fts.geofilter.field = field
 	fts.geofilter.lon = lon
@@ -288,9 +294,11 @@ func (fts *FtSearch) AddSortBy(attr string, asc bool) *FtSearch {
 	return fts
 }
 
+// AddLimit sets the page, AddLimit(0, 0) only counts the matching documents
 func (fts *FtSearch) AddLimit(offset, num int64) *FtSearch {
 	fts.limit.offset = offset
 	fts.limit.num = num
+	fts.limit.set = true
 
 	return fts
 }
@@ -307,6 +315,14 @@ func (fts *FtSearch) Serialize() []interface{} {
 		queryCode = append(queryCode, fts.query)
 	}
 
+	return append(queryCode, fts.serializeOptions()...)
+}
+
+// serializeOptions returns everything after the query string
+func (fts *FtSearch) serializeOptions() []interface{} {
+
+	var queryCode []interface{}
+
 	// NOTE: sadece kaç adet sonuç var bilmek istiyorsak limit parametresini 0 0 olarak yollamamız yeterli.
 	if fts.nocontent {
 		queryCode = append(queryCode, "NOCONTENT")
@@ -335,23 +351,8 @@ func (fts *FtSearch) Serialize() []interface{} {
 	if fts.filters != nil && len(fts.filters) > 0 {
 
 		for _, f := range fts.filters {
-			queryCode = append(queryCode, "FILTER", f.field)
-
-			if f.exclusiveMin {
-				queryCode = append(queryCode, fmt.Sprintf("(%.f", f.min))
-			} else if math.IsInf(f.min, 1) {
-				queryCode = append(queryCode, "+inf")
-			} else {
-				queryCode = append(queryCode, fmt.Sprintf("%.f", f.min))
-			}
-
-			if f.exclusiveMax {
-				queryCode = append(queryCode, fmt.Sprintf("(%.f", f.max))
-			} else if math.IsInf(f.max, -1) {
-				queryCode = append(queryCode, "-inf")
-			} else {
-				queryCode = append(queryCode, fmt.Sprintf("%.f", f.max))
-			}
+			min, max := f.numericRange().args()
+			queryCode = append(queryCode, "FILTER", f.field, min, max)
 		}
 	}
 
@@ -374,7 +375,7 @@ func (fts *FtSearch) Serialize() []interface{} {
 	}
 
 	if fts.summarize.fields != nil && len(fts.summarize.fields) > 0 {
-		queryCode = append(queryCode, "SUMMIRIZE")
+		queryCode = append(queryCode, "SUMMARIZE")
 
 		queryCode = append(queryCode, "FIELDS", len(fts.summarize.fields))
 		for _, sf := range fts.summarize.fields {
@@ -433,7 +434,7 @@ func (fts *FtSearch) Serialize() []interface{} {
 	}
 
 	//if !fts.nocontent {
-	if fts.limit.num > 0 {
+	if fts.limit.num > 0 || fts.limit.set {
 		queryCode = append(queryCode, "LIMIT", fts.limit.offset, fts.limit.num)
 	} else {
 		queryCode = append(queryCode, "LIMIT", 0, 10)
@@ -0,0 +1,42 @@
+package redisearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFtSearch_Serialize(t *testing.T) {
+	tests := []struct {
+		name string
+		fts  *FtSearch
+		want []interface{}
+	}{
+		{
+			name: "Summarize",
+			fts: NewFtSearch("idx:drd").AddQuery("dream").
+				AddSummarize([]string{"name", "description"}, 3, 20, "..."),
+			want: []interface{}{
+				"FT.SEARCH", "idx:drd", "dream",
+				"SUMMARIZE", "FIELDS", 2, "name", "description", "FRAGS", 3, "LEN", 20, "SEPARATOR", "...",
+				"LIMIT", 0, 10,
+			},
+		},
+		{
+			name: "Summarize Fields Only",
+			fts:  NewFtSearch("idx:drd").AddQuery("dream").AddSummarize([]string{"name"}, 0, 0, ""),
+			want: []interface{}{"FT.SEARCH", "idx:drd", "dream", "SUMMARIZE", "FIELDS", 1, "name", "LIMIT", 0, 10},
+		},
+		{
+			name: "Count Only",
+			fts:  NewFtSearch("idx:drd").AddQuery("dream").AddLimit(0, 0),
+			want: []interface{}{"FT.SEARCH", "idx:drd", "dream", "LIMIT", int64(0), int64(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fts.Serialize(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Serialize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 )
 
+// Search parameters of the dream app, mapped by hand into FtSearch. SearchBinder binds them from url.Values.
 type SearchBuilder struct {
 	Raw    string
 	Query  string
@@ -0,0 +1,363 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Virtual nodes per shard on the hash ring
+const DefaultShardReplicas = 160
+
+// ErrNoShards is returned for key commands before the first AddShard
+var ErrNoShards = errors.New("redisearch: sharded client has no shards")
+
+// hashRing maps keys to shard names by consistent hashing
+type hashRing struct {
+	replicas int
+	points   []uint32
+	owners   map[uint32]string
+}
+
+func newHashRing(replicas int) *hashRing {
+	if replicas <= 0 {
+		replicas = DefaultShardReplicas
+	}
+
+	return &hashRing{
+		replicas: replicas,
+		owners:   make(map[uint32]string),
+	}
+}
+
+func (r *hashRing) add(name string) {
+	for i := 0; i < r.replicas; i++ {
+		point := crc32.ChecksumIEEE([]byte(name + "#" + strconv.Itoa(i)))
+		if _, ok := r.owners[point]; ok {
+			continue
+		}
+		r.owners[point] = name
+		r.points = append(r.points, point)
+	}
+
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
+
+// get returns the shard of key, the first point clockwise from its hash
+func (r *hashRing) get(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+
+	return r.owners[r.points[i]]
+}
+
+// ShardedClient spreads the documents of one logical index over independent Redis nodes.
+// Documents are routed by consistent hashing of their key, index commands run on every shard
+// and searches are scattered to all shards and merged.
+//
+//	sc := redisearch.NewShardedClient("dreams", redisearch.DefaultShardReplicas).
+//		AddShard("node-1", redisearch.NewRedisearchClient("dreams", []string{"10.0.0.1:6379"}, ...)).
+//		AddShard("node-2", redisearch.NewRedisearchClient("dreams", []string{"10.0.0.2:6379"}, ...))
+type ShardedClient struct {
+	Name string
+	Ctx  context.Context
+
+	mu     sync.RWMutex
+	ring   *hashRing
+	names  []string
+	shards map[string]*RedisearchClient
+}
+
+func NewShardedClient(serviceName string, replicas int) *ShardedClient {
+	return &ShardedClient{
+		Name:   serviceName,
+		Ctx:    context.Background(),
+		ring:   newHashRing(replicas),
+		shards: make(map[string]*RedisearchClient),
+	}
+}
+
+// AddShard adds a node to the ring. The name, not the address, decides which keys the node owns,
+// so keep it stable across restarts. Existing indexes are not created on the new node and
+// documents are not moved: call Create and Rebalance afterwards.
+func (sc *ShardedClient) AddShard(name string, rsc *RedisearchClient) *ShardedClient {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if _, ok := sc.shards[name]; !ok {
+		sc.names = append(sc.names, name)
+		sort.Strings(sc.names)
+		sc.ring.add(name)
+	}
+	sc.shards[name] = rsc
+
+	return sc
+}
+
+// Shard returns the client that owns key, ErrNoShards when no shard was added
+func (sc *ShardedClient) Shard(key string) (*RedisearchClient, error) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	shard, ok := sc.shards[sc.ring.get(key)]
+	if !ok {
+		return nil, ErrNoShards
+	}
+
+	return shard, nil
+}
+
+// Shards returns every shard ordered by name
+func (sc *ShardedClient) Shards() []*RedisearchClient {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	shards := make([]*RedisearchClient, len(sc.names))
+	for i, name := range sc.names {
+		shards[i] = sc.shards[name]
+	}
+
+	return shards
+}
+
+func (sc *ShardedClient) CloseUniversalClient() error {
+	var first error
+	for _, shard := range sc.Shards() {
+		if err := shard.CloseUniversalClient(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// Create runs FT.CREATE on every shard
+func (sc *ShardedClient) Create(indexName string, args ...interface{}) (string, error) {
+	if err := doOnShards(sc.Ctx, sc.Shards(), args...); err != nil {
+		return "", err
+	}
+
+	return "OK", nil
+}
+
+// Alter runs FT.ALTER on every shard
+func (sc *ShardedClient) Alter(indexName string, values ...interface{}) (string, error) {
+	err := forEachShard(sc.Shards(), func(i int, shard *RedisearchClient) error {
+		_, err := shard.Alter(indexName, values...)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "OK", nil
+}
+
+// DropIndex runs FT.DROPINDEX on every shard
+func (sc *ShardedClient) DropIndex(indexName string, deleteHash bool) (string, error) {
+	err := forEachShard(sc.Shards(), func(i int, shard *RedisearchClient) error {
+		_, err := shard.DropIndex(indexName, deleteHash)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "OK", nil
+}
+
+func (sc *ShardedClient) HSet(key string, values ...interface{}) (int64, error) {
+	shard, err := sc.Shard(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return shard.HSet(key, values...)
+}
+
+func (sc *ShardedClient) HMSet(key string, values ...interface{}) (bool, error) {
+	shard, err := sc.Shard(key)
+	if err != nil {
+		return false, err
+	}
+
+	return shard.HMSet(key, values...)
+}
+
+func (sc *ShardedClient) HDel(key string, fields ...string) (int64, error) {
+	shard, err := sc.Shard(key)
+	if err != nil {
+		return 0, err
+	}
+
+	return shard.HDel(key, fields...)
+}
+
+func (sc *ShardedClient) HGetAll(key string) (map[string]string, error) {
+	shard, err := sc.Shard(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.HGetAll(key)
+}
+
+// Del groups the keys by shard
+func (sc *ShardedClient) Del(keys ...string) (int64, error) {
+	groups := make(map[*RedisearchClient][]string)
+	for _, key := range keys {
+		shard, err := sc.Shard(key)
+		if err != nil {
+			return 0, err
+		}
+		groups[shard] = append(groups[shard], key)
+	}
+
+	var deleted int64
+	for shard, keys := range groups {
+		n, err := shard.Del(keys...)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	return deleted, nil
+}
+
+// DoSearch scatters the search to every shard and merges the replies by score, or by
+// sort key when the search has SORTBY. LIMIT is applied to the merged documents.
+func (sc *ShardedClient) DoSearch(ctx context.Context, fts *FtSearch) (*SearchResult, error) {
+	return scatterSearch(ctx, sc.Shards(), fts)
+}
+
+// Aggregate scatters the aggregation to every shard and merges the rows.
+// Only COUNT, SUM, MIN and MAX reducers can be merged, see scatterAggregate.
+func (sc *ShardedClient) Aggregate(ctx context.Context, fta *FtAggregate) (*AggregateResult, error) {
+	return scatterAggregate(ctx, sc.Shards(), fta)
+}
+
+/*
+Rebalance moves the hashes matching pattern (SCAN MATCH syntax, e.g. "drd:*") to the shard that
+owns them on the ring. Run it after AddShard, it returns the number of moved hashes.
+
+A hash is copied with HGETALL and a MULTI/EXEC of DEL and HSET, then deleted from its old shard.
+Writes to a hash while it is moved can be lost, pause them or run Rebalance again.
+Only hashes are moved (SCAN TYPE hash, Redis 6 or later).
+*/
+func (sc *ShardedClient) Rebalance(ctx context.Context, pattern string) (int64, error) {
+	sc.mu.RLock()
+	names := append([]string(nil), sc.names...)
+	sc.mu.RUnlock()
+
+	var moved int64
+	for _, name := range names {
+		sc.mu.RLock()
+		source := sc.shards[name]
+		sc.mu.RUnlock()
+
+		keys, err := scanKeys(ctx, source, pattern)
+		if err != nil {
+			return moved, err
+		}
+
+		for _, key := range keys {
+			target, err := sc.Shard(key)
+			if err != nil {
+				return moved, err
+			}
+			if target == source {
+				continue
+			}
+
+			if err := moveHash(ctx, source, target, key); err != nil {
+				return moved, err
+			}
+			moved++
+		}
+	}
+
+	return moved, nil
+}
+
+// scanKeys collects the hash keys matching pattern with SCAN, TYPE needs Redis 6
+func scanKeys(ctx context.Context, rsc *RedisearchClient, pattern string) ([]string, error) {
+	var keys []string
+
+	cursor := "0"
+	for {
+		reply, err := rsc.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", 1000, "TYPE", "hash").Slice()
+		if err != nil {
+			return nil, err
+		}
+		if len(reply) != 2 {
+			return nil, errors.New("redisearch: unexpected scan reply")
+		}
+
+		page, _ := reply[1].([]interface{})
+		for _, key := range page {
+			keys = append(keys, replyString(key))
+		}
+
+		cursor = replyString(reply[0])
+		if cursor == "0" {
+			return keys, nil
+		}
+	}
+}
+
+func moveHash(ctx context.Context, source, target *RedisearchClient, key string) error {
+	fields, err := source.do(ctx, "HGETALL", key).Slice()
+	if err != nil {
+		return err
+	}
+
+	if len(fields) > 0 {
+		// replace the target hash at once, readers never see it empty or half written
+		args := appendArgs([]interface{}{"HSET", key}, fields)
+		res, err := target.pipeline(ctx, [][]interface{}{{"MULTI"}, {"DEL", key}, args, {"EXEC"}})
+		if err != nil {
+			return err
+		}
+		if err := execErr(res[len(res)-1]); err != nil {
+			return err
+		}
+		target.invalidateKeys(ctx, key)
+	}
+
+	if err := source.do(ctx, "DEL", key).Err(); err != nil {
+		return err
+	}
+
+	source.invalidateKeys(ctx, key)
+
+	return nil
+}
+
+// execErr returns the first error of the EXEC reply
+func execErr(cmd *redis.Cmd) error {
+	replies, err := cmd.Slice()
+	if err != nil {
+		return err
+	}
+
+	for _, reply := range replies {
+		if err, ok := reply.(error); ok {
+			return err
+		}
+	}
+
+	return nil
+}
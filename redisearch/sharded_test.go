@@ -0,0 +1,173 @@
+package redisearch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_hashRing(t *testing.T) {
+	ring := newHashRing(DefaultShardReplicas)
+	ring.add("node-1")
+	ring.add("node-2")
+
+	before := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		key := "drd:" + strconv.Itoa(i)
+		before[key] = ring.get(key)
+		counts[before[key]]++
+	}
+
+	for _, name := range []string{"node-1", "node-2"} {
+		if counts[name] < 300 {
+			t.Errorf("%s owns %d of 1000 keys", name, counts[name])
+		}
+	}
+
+	// a new node only takes keys, it never moves keys between the old nodes
+	ring.add("node-3")
+	var moved int
+	for key, owner := range before {
+		switch now := ring.get(key); now {
+		case owner:
+		case "node-3":
+			moved++
+		default:
+			t.Errorf("%s moved from %s to %s", key, owner, now)
+		}
+	}
+
+	if moved == 0 || moved > 500 {
+		t.Errorf("node-3 took %d of 1000 keys", moved)
+	}
+}
+
+func TestShardedClient_NoShards(t *testing.T) {
+	sc := NewShardedClient("test", DefaultShardReplicas)
+
+	if _, err := sc.Shard("drd:1"); err != ErrNoShards {
+		t.Errorf("Shard() error = %v, want ErrNoShards", err)
+	}
+	if _, err := sc.HSet("drd:1", "name", "Dream Test 1"); err != ErrNoShards {
+		t.Errorf("HSet() error = %v, want ErrNoShards", err)
+	}
+	if _, err := sc.Del("drd:1"); err != ErrNoShards {
+		t.Errorf("Del() error = %v, want ErrNoShards", err)
+	}
+}
+
+func TestShardedClient(t *testing.T) {
+	sc := NewShardedClient("test", DefaultShardReplicas).
+		AddShard("node-1", newTestClient(t)).
+		AddShard("node-2", newTestClient(t))
+
+	ftc := NewFtCreate("idx:drd").
+		AddDataType(HASH).
+		AddPrefix("drd:")
+	ftc.AddSchema(FieldTypeText, "name", "", true, ftc.AddSchemaTextOption(1, false, false, "")).
+		AddSchema(FieldTypeNumeric, "updated", "", true, ftc.AddSchemaNumericOption(false))
+
+	if _, err := sc.Create(ftc.indexname, ftc.Serialize()...); err != nil {
+		t.Fatal(err)
+	}
+
+	const docs = 20
+	for i := 1; i <= docs; i++ {
+		if _, err := sc.HSet("drd:"+strconv.Itoa(i), "name", "Dream "+strconv.Itoa(i), "updated", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	search := NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true).AddSortBy("updated", false).AddLimit(0, 3)
+
+	check := func(t *testing.T) {
+		t.Helper()
+
+		res, err := sc.DoSearch(context.Background(), search)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Total != docs {
+			t.Errorf("Total = %d, want %d", res.Total, docs)
+		}
+
+		want := []string{"drd:20", "drd:19", "drd:18"}
+		for i, doc := range res.Docs {
+			if i >= len(want) || doc.ID != want[i] {
+				t.Fatalf("Docs = %+v, want %v", res.Docs, want)
+			}
+		}
+
+		// every document lives on the shard that owns it
+		for i := 1; i <= docs; i++ {
+			key := "drd:" + strconv.Itoa(i)
+			for _, shard := range sc.Shards() {
+				fields, err := shard.HGetAll(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				owner, err := sc.Shard(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if (owner == shard) != (len(fields) > 0) {
+					t.Errorf("%s on %s: owner %v, stored %v", key, shard.Name, owner == shard, len(fields) > 0)
+				}
+			}
+		}
+	}
+
+	t.Run("Scatter Search", check)
+
+	third := newTestClient(t)
+	t.Run("Add Shard And Rebalance", func(t *testing.T) {
+		sc.AddShard("node-3", third)
+
+		if _, err := third.Create(ftc.indexname, ftc.Serialize()...); err != nil {
+			t.Fatal(err)
+		}
+
+		rec := NewRecordingExecutor(third.Executor)
+		third.Executor = rec
+
+		moved, err := sc.Rebalance(context.Background(), "drd:*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if moved == 0 {
+			t.Error("Rebalance() moved no documents to the new shard")
+		}
+
+		// only hashes are scanned and every moved hash is replaced in one transaction
+		var sent []string
+		for _, cmd := range rec.Commands() {
+			sent = append(sent, fmt.Sprint(cmd[0]))
+			if cmd[0] == "SCAN" && fmt.Sprint(cmd[len(cmd)-2:]) != "[TYPE hash]" {
+				t.Errorf("scan command = %v, want TYPE hash", cmd)
+			}
+		}
+		if got, want := strings.Join(sent, " "), "MULTI DEL HSET EXEC"; !strings.Contains(got, want) {
+			t.Errorf("target commands = %s, want %s", got, want)
+		}
+
+		check(t)
+
+		if moved, _ := sc.Rebalance(context.Background(), "drd:*"); moved != 0 {
+			t.Errorf("second Rebalance() moved %d documents, want 0", moved)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		deleted, err := sc.Del("drd:1", "drd:2", "drd:3", "drd:missing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted != 3 {
+			t.Errorf("Del() = %d, want 3", deleted)
+		}
+	})
+}
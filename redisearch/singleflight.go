@@ -0,0 +1,148 @@
+package redisearch
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Coalescing counters
+type CoalescingStats struct {
+	Calls        int64 // searches that went through the coalescer
+	Deduplicated int64 // searches answered by another in-flight search
+	InFlight     int64 // distinct searches waiting for Redis right now
+}
+
+type flightCall struct {
+	done  chan struct{}
+	val   interface{}
+	err   error
+	panic interface{} // raised again in every caller
+}
+
+// coalescer shares the reply of an in-flight FT.SEARCH with identical concurrent searches
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+
+	total        int64
+	deduplicated int64
+}
+
+// EnableCoalescing makes identical concurrent searches share one round trip. The serialized
+// command is the key, so only byte for byte identical searches are coalesced. The shared search
+// runs with the values of the first caller's context but without its deadline or cancellation,
+// every caller stops waiting when its own context is done. Callers get their own copy of the
+// top-level reply, the nested arrays are shared and must not be modified.
+// Enable it before the client is shared between goroutines.
+func (rsc *RedisearchClient) EnableCoalescing() *RedisearchClient {
+	rsc.flight = &coalescer{calls: make(map[string]*flightCall)}
+
+	return rsc
+}
+
+// CoalescingStats returns zero values when coalescing is not enabled
+func (rsc *RedisearchClient) CoalescingStats() CoalescingStats {
+	if rsc.flight == nil {
+		return CoalescingStats{}
+	}
+
+	rsc.flight.mu.Lock()
+	inFlight := int64(len(rsc.flight.calls))
+	rsc.flight.mu.Unlock()
+
+	return CoalescingStats{
+		Calls:        atomic.LoadInt64(&rsc.flight.total),
+		Deduplicated: atomic.LoadInt64(&rsc.flight.deduplicated),
+		InFlight:     inFlight,
+	}
+}
+
+func (c *coalescer) do(ctx context.Context, args []interface{}, run func(ctx context.Context) *redis.Cmd) *redis.Cmd {
+	if len(args) == 0 || !strings.EqualFold(replyString(args[0]), "FT.SEARCH") {
+		return run(ctx)
+	}
+
+	atomic.AddInt64(&c.total, 1)
+
+	key := cacheKey(args)
+
+	c.mu.Lock()
+	call, ok := c.calls[key]
+	if ok {
+		atomic.AddInt64(&c.deduplicated, 1)
+	} else {
+		call = &flightCall{done: make(chan struct{})}
+		c.calls[key] = call
+		go c.fly(detachedContext{ctx}, key, call, run)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		cmd := redis.NewCmd(ctx, args...)
+		cmd.SetErr(ctx.Err())
+		return cmd
+	}
+
+	if call.panic != nil {
+		panic(call.panic)
+	}
+
+	return flightCmd(ctx, args, call)
+}
+
+// fly runs the shared search and releases the callers, even when run panics
+func (c *coalescer) fly(ctx context.Context, key string, call *flightCall, run func(ctx context.Context) *redis.Cmd) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.panic = r
+		}
+
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	cmd := run(ctx)
+	call.val, call.err = cmd.Val(), cmd.Err()
+}
+
+// detachedContext keeps the values of its parent (hooks, query tags) without its deadline and cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// flightCmd copies the shared reply into a command of the waiting caller. Every caller gets its
+// own top-level reply slice, nested arrays (document fields) are still shared.
+func flightCmd(ctx context.Context, args []interface{}, call *flightCall) *redis.Cmd {
+	cmd := redis.NewCmd(ctx, args...)
+	if call.err != nil {
+		cmd.SetErr(call.err)
+	} else if reply, ok := call.val.([]interface{}); ok {
+		cmd.SetVal(append([]interface{}(nil), reply...))
+	} else {
+		cmd.SetVal(call.val)
+	}
+
+	return cmd
+}
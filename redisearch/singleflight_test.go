@@ -0,0 +1,180 @@
+package redisearch
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// gatedExecutor blocks every command until release is closed
+type gatedExecutor struct {
+	release chan struct{}
+	calls   int64
+}
+
+func (e *gatedExecutor) Do(ctx context.Context, args ...interface{}) *redis.Cmd {
+	atomic.AddInt64(&e.calls, 1)
+	<-e.release
+
+	cmd := redis.NewCmd(ctx, args...)
+	cmd.SetVal([]interface{}{int64(1), "drd:1"})
+
+	return cmd
+}
+
+func (e *gatedExecutor) Pipeline(ctx context.Context, cmds [][]interface{}) ([]*redis.Cmd, error) {
+	return nil, nil
+}
+
+func TestRedisearchClient_EnableCoalescing(t *testing.T) {
+	exec := &gatedExecutor{release: make(chan struct{})}
+	rsc := NewRedisearchClientWithExecutor("test", exec).EnableCoalescing()
+
+	const callers = 20
+
+	var wg sync.WaitGroup
+	totals := make([]int64, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			res, err := rsc.DoSearch(context.Background(), NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			totals[i] = res.Total
+		}(i)
+	}
+
+	// wait until every caller joined the in-flight search
+	deadline := time.Now().Add(5 * time.Second)
+	for rsc.CoalescingStats().Calls < callers {
+		if time.Now().After(deadline) {
+			t.Fatal("callers did not reach the coalescer")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if got := rsc.CoalescingStats().InFlight; got != 1 {
+		t.Errorf("InFlight = %d, want 1", got)
+	}
+
+	close(exec.release)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&exec.calls); got != 1 {
+		t.Errorf("executor calls = %d, want 1", got)
+	}
+
+	for i, total := range totals {
+		if total != 1 {
+			t.Errorf("caller %d total = %d, want 1", i, total)
+		}
+	}
+
+	stats := rsc.CoalescingStats()
+	if stats.Calls != callers || stats.Deduplicated != callers-1 || stats.InFlight != 0 {
+		t.Errorf("CoalescingStats() = %+v", stats)
+	}
+
+	// a finished search is not shared with later callers
+	rsc.DoSearch(context.Background(), NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true))
+	if got := atomic.LoadInt64(&exec.calls); got != 2 {
+		t.Errorf("executor calls = %d, want 2", got)
+	}
+}
+
+func TestRedisearchClient_EnableCoalescingCancel(t *testing.T) {
+	exec := &gatedExecutor{release: make(chan struct{})}
+	rsc := NewRedisearchClientWithExecutor("test", exec).EnableCoalescing()
+	fts := NewFtSearch("idx:drd").AddQuery("dream").AddNoContent(true)
+
+	search := func(ctx context.Context) <-chan error {
+		errc := make(chan error, 1)
+		go func() {
+			_, err := rsc.DoSearch(ctx, fts)
+			errc <- err
+		}()
+		return errc
+	}
+
+	// the first caller starts the search, then a caller that gives up and one that waits
+	first, cancelFirst := context.WithCancel(context.Background())
+	firstErr := search(first)
+	for atomic.LoadInt64(&exec.calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waiter, cancelWaiter := context.WithCancel(context.Background())
+	waiterErr := search(waiter)
+	patientErr := search(context.Background())
+	for rsc.CoalescingStats().Calls < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancelWaiter()
+	if err := <-waiterErr; err != context.Canceled {
+		t.Errorf("canceled waiter error = %v, want context.Canceled", err)
+	}
+
+	cancelFirst()
+	if err := <-firstErr; err != context.Canceled {
+		t.Errorf("canceled first caller error = %v, want context.Canceled", err)
+	}
+
+	// the search goes on for the callers still waiting
+	close(exec.release)
+	if err := <-patientErr; err != nil {
+		t.Errorf("waiting caller error = %v, want the reply", err)
+	}
+	if got := atomic.LoadInt64(&exec.calls); got != 1 {
+		t.Errorf("executor calls = %d, want 1", got)
+	}
+}
+
+func Test_coalescerPanic(t *testing.T) {
+	c := &coalescer{calls: make(map[string]*flightCall)}
+	args := []interface{}{"FT.SEARCH", "idx:drd", "dream"}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "executor failed" {
+				t.Errorf("recover() = %v, want the panic of run", r)
+			}
+		}()
+
+		c.do(context.Background(), args, func(ctx context.Context) *redis.Cmd {
+			panic("executor failed")
+		})
+	}()
+
+	// the flight was released, the next search runs again
+	cmd := c.do(context.Background(), args, func(ctx context.Context) *redis.Cmd {
+		cmd := redis.NewCmd(ctx, args...)
+		cmd.SetVal([]interface{}{int64(0)})
+		return cmd
+	})
+	if cmd.Err() != nil || len(c.calls) != 0 {
+		t.Errorf("do() after a panic = %v, %d in flight", cmd.Err(), len(c.calls))
+	}
+}
+
+func Test_flightCmd(t *testing.T) {
+	args := []interface{}{"FT.SEARCH", "idx:drd", "dream"}
+	call := &flightCall{val: []interface{}{int64(1), "drd:1"}}
+
+	first := flightCmd(context.Background(), args, call)
+	first.Val().([]interface{})[1] = "drd:2"
+
+	// a caller editing its reply does not change the reply of the others
+	second := flightCmd(context.Background(), args, call)
+	if got := second.Val().([]interface{})[1]; got != "drd:1" {
+		t.Errorf("second reply = %v, want drd:1", got)
+	}
+}
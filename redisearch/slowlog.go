@@ -0,0 +1,335 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Default number of entries kept by the slow query log
+const DefaultSlowLogSize = 128
+
+// Number of slow queries waiting for XADD, more are dropped
+const DefaultSlowLogQueueSize = 256
+
+// Single FT.SEARCH or FT.AGGREGATE slower than the threshold
+type SlowQuery struct {
+	Time     time.Time // when the command finished
+	Index    string
+	Command  string // FT.SEARCH or FT.AGGREGATE
+	Args     []interface{}
+	Duration time.Duration
+	Hits     int64 // total results, -1 when the command failed
+	Tags     map[string]string
+	Err      string
+}
+
+// Query returns the query string of the command
+func (sq SlowQuery) Query() string {
+	if len(sq.Args) < 3 {
+		return ""
+	}
+
+	return replyString(sq.Args[2])
+}
+
+// String returns the command as it would be typed in redis-cli
+func (sq SlowQuery) String() string {
+	parts := make([]string, 0, len(sq.Args))
+	for _, arg := range sq.Args {
+		val := fmt.Sprint(arg)
+		if val == "" || strings.ContainsAny(val, " \"'") {
+			val = strconv.Quote(val)
+		}
+		parts = append(parts, val)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+type queryTagsKey struct{}
+
+// WithQueryTags attaches caller tags (endpoint, user, feature ...) to every slow query recorded with ctx.
+// Tags of an outer context are kept unless overwritten.
+func WithQueryTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string)
+	for k, v := range queryTags(ctx) {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, queryTagsKey{}, merged)
+}
+
+func queryTags(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(queryTagsKey{}).(map[string]string)
+
+	return tags
+}
+
+// SlowLog is a Hook that records FT.SEARCH and FT.AGGREGATE commands slower than a threshold
+// in a ring buffer and, with AddStream, in a Redis stream.
+type SlowLog struct {
+	threshold time.Duration
+	executor  Executor
+
+	stream       string
+	streamMaxLen int64
+	queue        chan []interface{}
+	done         chan struct{}
+	stopped      chan struct{}
+	closeOnce    sync.Once
+	dropped      int64
+
+	mu      sync.Mutex
+	entries []SlowQuery // ring buffer
+	next    int
+	full    bool
+}
+
+// EnableSlowLog installs a slow query log on the client. size 0 uses DefaultSlowLogSize.
+func (rsc *RedisearchClient) EnableSlowLog(threshold time.Duration, size int) *SlowLog {
+	sl := NewSlowLog(threshold, size)
+	sl.executor = rsc.Executor
+	rsc.AddHook(sl)
+
+	return sl
+}
+
+// NewSlowLog returns a slow query log, add it to a client with AddHook
+func NewSlowLog(threshold time.Duration, size int) *SlowLog {
+	if size <= 0 {
+		size = DefaultSlowLogSize
+	}
+
+	return &SlowLog{
+		threshold: threshold,
+		entries:   make([]SlowQuery, size),
+	}
+}
+
+// AddStream also appends slow queries to a Redis stream capped near maxLen entries (XADD MAXLEN ~).
+// Writes use the executor of EnableSlowLog and are skipped for a log built with NewSlowLog.
+// A background goroutine sends them, so queries never wait for XADD. Entries are dropped
+// while DefaultSlowLogQueueSize of them are waiting, see Dropped. Call it once, Close stops the goroutine.
+func (sl *SlowLog) AddStream(key string, maxLen int64) *SlowLog {
+	sl.stream = key
+	sl.streamMaxLen = maxLen
+
+	if sl.executor != nil && sl.queue == nil {
+		sl.queue = make(chan []interface{}, DefaultSlowLogQueueSize)
+		sl.done = make(chan struct{})
+		sl.stopped = make(chan struct{})
+		go sl.publisher()
+	}
+
+	return sl
+}
+
+// Dropped returns the number of slow queries not written to the stream because the queue was full
+func (sl *SlowLog) Dropped() int64 {
+	return atomic.LoadInt64(&sl.dropped)
+}
+
+// Close writes the queued entries to the stream and stops the stream goroutine.
+// Entries recorded after Close are kept in the ring buffer only.
+func (sl *SlowLog) Close() {
+	if sl.queue == nil {
+		return
+	}
+
+	sl.closeOnce.Do(func() {
+		close(sl.done)
+	})
+	<-sl.stopped
+}
+
+// Threshold returns the minimum duration of a recorded query
+func (sl *SlowLog) Threshold() time.Duration {
+	return sl.threshold
+}
+
+func (sl *SlowLog) BeforeCommand(ctx context.Context, info *CommandInfo) context.Context {
+	return ctx
+}
+
+func (sl *SlowLog) AfterCommand(ctx context.Context, info *CommandInfo) {
+	if info.Command != "FT.SEARCH" && info.Command != "FT.AGGREGATE" {
+		return
+	}
+
+	if info.Duration < sl.threshold {
+		return
+	}
+
+	sq := SlowQuery{
+		Time:     time.Now(),
+		Index:    info.Index,
+		Command:  info.Command,
+		Args:     append([]interface{}(nil), info.Args...),
+		Duration: info.Duration,
+		Hits:     info.Results,
+		Tags:     queryTags(ctx),
+	}
+	if info.Err != nil {
+		sq.Err = info.Err.Error()
+	}
+
+	sl.add(sq)
+
+	if sl.queue != nil {
+		sl.enqueue(sl.streamArgs(sq))
+	}
+}
+
+func (sl *SlowLog) add(sq SlowQuery) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	sl.entries[sl.next] = sq
+	sl.next = (sl.next + 1) % len(sl.entries)
+	if sl.next == 0 {
+		sl.full = true
+	}
+}
+
+// enqueue hands the XADD to the stream goroutine without blocking the query
+func (sl *SlowLog) enqueue(args []interface{}) {
+	select {
+	case <-sl.done:
+		atomic.AddInt64(&sl.dropped, 1)
+		return
+	default:
+	}
+
+	select {
+	case sl.queue <- args:
+	default:
+		atomic.AddInt64(&sl.dropped, 1)
+	}
+}
+
+// publisher writes the queued entries until Close, then the ones still queued
+func (sl *SlowLog) publisher() {
+	defer close(sl.stopped)
+
+	for {
+		select {
+		case args := <-sl.queue:
+			sl.publish(args)
+		case <-sl.done:
+			for {
+				select {
+				case args := <-sl.queue:
+					sl.publish(args)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// publish writes one entry. The command context may already be canceled, so a short own timeout is used.
+func (sl *SlowLog) publish(args []interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	sl.executor.Do(ctx, args...)
+}
+
+func (sl *SlowLog) streamArgs(sq SlowQuery) []interface{} {
+	args := []interface{}{"XADD", sl.stream}
+	if sl.streamMaxLen > 0 {
+		args = append(args, "MAXLEN", "~", sl.streamMaxLen)
+	}
+	args = append(args, "*",
+		"index", sq.Index,
+		"command", sq.String(),
+		"duration_us", sq.Duration.Microseconds(),
+		"hits", sq.Hits,
+	)
+
+	if sq.Err != "" {
+		args = append(args, "error", sq.Err)
+	}
+
+	tags := make([]string, 0, len(sq.Tags))
+	for k := range sq.Tags {
+		tags = append(tags, k)
+	}
+	sort.Strings(tags)
+	for _, k := range tags {
+		args = append(args, "tag:"+k, sq.Tags[k])
+	}
+
+	return args
+}
+
+// Entries returns the recorded queries, oldest first
+func (sl *SlowLog) Entries() []SlowQuery {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	if !sl.full {
+		return append([]SlowQuery(nil), sl.entries[:sl.next]...)
+	}
+
+	res := make([]SlowQuery, 0, len(sl.entries))
+	res = append(res, sl.entries[sl.next:]...)
+
+	return append(res, sl.entries[:sl.next]...)
+}
+
+// Top returns the n slowest recorded queries, slowest first. n 0 returns all of them.
+func (sl *SlowLog) Top(n int) []SlowQuery {
+	entries := sl.Entries()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Duration > entries[j].Duration
+	})
+
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+
+	return entries
+}
+
+// Reset clears the ring buffer
+func (sl *SlowLog) Reset() {
+	sl.mu.Lock()
+	sl.entries = make([]SlowQuery, len(sl.entries))
+	sl.next = 0
+	sl.full = false
+	sl.mu.Unlock()
+}
+
+// ProfileSlowQuery runs the recorded command again under FT.PROFILE
+func (rsc *RedisearchClient) ProfileSlowQuery(ctx context.Context, sq SlowQuery, limited bool) (*Profile, error) {
+	if len(sq.Args) < 3 {
+		return nil, errors.New("redisearch: slow query has no query")
+	}
+
+	kind := strings.TrimPrefix(sq.Command, "FT.")
+	args := profileArgs(sq.Index, kind, sq.Query(), limited, sq.Args[3:])
+
+	reply, err := rsc.do(ctx, args...).Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reply) != 2 {
+		return nil, errors.New("redisearch: unexpected profile reply")
+	}
+
+	return parseProfile(reply[1])
+}
@@ -0,0 +1,125 @@
+package redisearch
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSlowLog_Top(t *testing.T) {
+	sl := NewSlowLog(10*time.Millisecond, 3)
+	ctx := context.Background()
+
+	for _, d := range []time.Duration{5, 20, 40, 15, 30} {
+		sl.AfterCommand(ctx, &CommandInfo{
+			Index:    "idx:drd",
+			Command:  "FT.SEARCH",
+			Args:     []interface{}{"FT.SEARCH", "idx:drd", "dream"},
+			Duration: d * time.Millisecond,
+			Results:  1,
+		})
+	}
+	sl.AfterCommand(ctx, &CommandInfo{Command: "FT.INFO", Duration: time.Second})
+
+	durations := func(entries []SlowQuery) []time.Duration {
+		var res []time.Duration
+		for _, e := range entries {
+			res = append(res, e.Duration/time.Millisecond)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name string
+		got  []SlowQuery
+		want []time.Duration
+	}{
+		{name: "Ring Buffer Keeps Newest", got: sl.Entries(), want: []time.Duration{40, 15, 30}},
+		{name: "Top Two", got: sl.Top(2), want: []time.Duration{40, 30}},
+		{name: "Top All", got: sl.Top(0), want: []time.Duration{40, 30, 15}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := durations(tt.got); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("durations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	sl.Reset()
+	if got := sl.Entries(); len(got) != 0 {
+		t.Errorf("Entries() after Reset = %v", got)
+	}
+}
+
+func TestRedisearchClient_EnableSlowLog(t *testing.T) {
+	scripted := NewScriptedExecutor().
+		AddReply([]interface{}{int64(3), "drd:1"}).
+		AddReply("1650000000000-0")
+	rec := NewRecordingExecutor(scripted)
+
+	rsc := NewRedisearchClientWithExecutor("test", rec)
+	sl := rsc.EnableSlowLog(0, 10).AddStream("slowlog", 1000)
+
+	ctx := WithQueryTags(context.Background(), map[string]string{"endpoint": "/search"})
+	ctx = WithQueryTags(ctx, map[string]string{"user": "42"})
+
+	if _, err := rsc.DoSearch(ctx, NewFtSearch("idx:drd").AddQuery("hello world").AddNoContent(true)); err != nil {
+		t.Fatal(err)
+	}
+
+	// flushes the stream queue
+	sl.Close()
+
+	entries := sl.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("Entries() = %v, want 1 entry", entries)
+	}
+
+	sq := entries[0]
+	if sq.Index != "idx:drd" || sq.Hits != 3 || sq.Query() != "hello world" {
+		t.Errorf("SlowQuery = %+v", sq)
+	}
+
+	if want := map[string]string{"endpoint": "/search", "user": "42"}; !reflect.DeepEqual(sq.Tags, want) {
+		t.Errorf("Tags = %v, want %v", sq.Tags, want)
+	}
+
+	if want := `FT.SEARCH idx:drd "hello world" NOCONTENT LIMIT 0 10`; sq.String() != want {
+		t.Errorf("String() = %q, want %q", sq.String(), want)
+	}
+
+	cmds := rec.Commands()
+	if len(cmds) != 2 {
+		t.Fatalf("Commands() = %v, want search and XADD", cmds)
+	}
+
+	xadd := cmds[1]
+	want := []interface{}{"XADD", "slowlog", "MAXLEN", "~", int64(1000), "*", "index", "idx:drd", "command", sq.String()}
+	if !reflect.DeepEqual(xadd[:len(want)], want) {
+		t.Errorf("XADD = %v, want prefix %v", xadd, want)
+	}
+
+	if tail := xadd[len(xadd)-4:]; !reflect.DeepEqual(tail, []interface{}{"tag:endpoint", "/search", "tag:user", "42"}) {
+		t.Errorf("XADD tags = %v", tail)
+	}
+}
+
+func TestSlowLog_Dropped(t *testing.T) {
+	sl := NewSlowLog(0, 10)
+	sl.executor = NewScriptedExecutor()
+	sl.stream = "slowlog"
+
+	// a full queue nobody drains
+	sl.queue = make(chan []interface{}, 1)
+	sl.done = make(chan struct{})
+
+	for i := 0; i < 3; i++ {
+		sl.AfterCommand(context.Background(), &CommandInfo{Command: "FT.SEARCH", Index: "idx:drd", Duration: time.Millisecond})
+	}
+
+	if len(sl.Entries()) != 3 || len(sl.queue) != 1 || sl.Dropped() != 2 {
+		t.Errorf("entries %d, queued %d, dropped %d, want 3, 1 and 2", len(sl.Entries()), len(sl.queue), sl.Dropped())
+	}
+}
@@ -0,0 +1,193 @@
+package redisearch
+
+import (
+	"strings"
+)
+
+// SearchValidationError lists every problem Validate found in a search
+type SearchValidationError struct {
+	Index    string
+	Problems []string
+}
+
+func (e *SearchValidationError) Error() string {
+	return "redisearch: invalid search on " + e.Index + ": " + strings.Join(e.Problems, "; ")
+}
+
+// AddIndexDefinition attaches the schema of ftc, DoSearch validates the search against it
+func (fts *FtSearch) AddIndexDefinition(ftc *FtCreate) *FtSearch {
+	fts.schema = ftc.attributes()
+
+	return fts
+}
+
+// AddIndexInfo attaches the schema of a live index, see AddIndexDefinition
+func (fts *FtSearch) AddIndexInfo(info *IndexInfo) *FtSearch {
+	fts.schema = info.Attributes
+
+	return fts
+}
+
+/*
+Validate checks the search against the attached schema and returns a *SearchValidationError for:
+
+	unknown attributes in the query, FILTER, GEOFILTER, INFIELDS and SORTBY
+	{tag} syntax on a non TAG attribute, [range] syntax on a non NUMERIC or GEO attribute
+	text syntax on a TAG, NUMERIC or GEO attribute
+	SORTBY on a non SORTABLE attribute
+	RETURN of a property that is not in the schema
+	HIGHLIGHT and SUMMARIZE on a non TEXT attribute
+
+Without a schema it returns nil.
+*/
+func (fts *FtSearch) Validate() error {
+	if fts.schema == nil {
+		return nil
+	}
+
+	var problems []string
+	lookup := func(clause, name string) *IndexAttribute {
+		attr := findAttribute(fts.schema, name)
+		if attr == nil {
+			problems = append(problems, clause+" references unknown attribute @"+name)
+		}
+		return attr
+	}
+
+	for _, ref := range queryAttributes(fts.query) {
+		attr := lookup("query", ref.name)
+		if attr == nil {
+			continue
+		}
+
+		switch {
+		case ref.syntax == '{' && attr.Type != FieldTypeTag:
+			problems = append(problems, "query uses tag syntax @"+ref.name+":{...} on "+attr.Type+" attribute")
+		case ref.syntax == '[' && attr.Type != FieldTypeNumeric && attr.Type != FieldTypeGeo:
+			problems = append(problems, "query uses range syntax @"+ref.name+":[...] on "+attr.Type+" attribute")
+		case ref.syntax != '{' && ref.syntax != '[' && attr.Type != FieldTypeText:
+			problems = append(problems, "query uses text syntax on "+attr.Type+" attribute @"+ref.name)
+		}
+	}
+
+	for _, f := range fts.filters {
+		if attr := lookup("FILTER", f.field); attr != nil && attr.Type != FieldTypeNumeric {
+			problems = append(problems, "FILTER on "+attr.Type+" attribute @"+f.field)
+		}
+	}
+
+	if fts.geofilter.field != "" {
+		if attr := lookup("GEOFILTER", fts.geofilter.field); attr != nil && attr.Type != FieldTypeGeo {
+			problems = append(problems, "GEOFILTER on "+attr.Type+" attribute @"+fts.geofilter.field)
+		}
+	}
+
+	for _, name := range fts.infields {
+		lookup("INFIELDS", name)
+	}
+
+	if fts.sortby.attribute != "" {
+		if attr := lookup("SORTBY", fts.sortby.attribute); attr != nil && !attr.Sortable {
+			problems = append(problems, "SORTBY on non SORTABLE attribute @"+fts.sortby.attribute)
+		}
+	}
+
+	for _, name := range fts.returnfields {
+		if findAttribute(fts.schema, name) == nil {
+			problems = append(problems, "RETURN of unknown property "+name)
+		}
+	}
+
+	for _, name := range fts.highlight.fields {
+		if attr := lookup("HIGHLIGHT", name); attr != nil && attr.Type != FieldTypeText {
+			problems = append(problems, "HIGHLIGHT on "+attr.Type+" attribute @"+name)
+		}
+	}
+
+	for _, name := range fts.summarize.fields {
+		if attr := lookup("SUMMARIZE", name); attr != nil && attr.Type != FieldTypeText {
+			problems = append(problems, "SUMMARIZE on "+attr.Type+" attribute @"+name)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &SearchValidationError{Index: fts.indexname, Problems: problems}
+	}
+
+	return nil
+}
+
+// Attribute reference of a query: @name:{...} has syntax '{', @name:[...] '[' and text 0
+type queryAttribute struct {
+	name   string
+	syntax byte
+}
+
+// queryAttributes finds the @attribute: references of a query, @a|b: yields a and b.
+// Escaped characters and quoted phrases are skipped.
+func queryAttributes(query string) []queryAttribute {
+	var refs []queryAttribute
+
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\\':
+			i++
+			continue
+		case c == '"':
+			quoted = !quoted
+			continue
+		case c != '@' || quoted:
+			continue
+		}
+
+		var names []string
+		var name strings.Builder
+		j := i + 1
+		for ; j < len(query); j++ {
+			c := query[j]
+			if c == '\\' && j+1 < len(query) {
+				j++
+				name.WriteByte(query[j])
+				continue
+			}
+			if c == '|' {
+				names = append(names, name.String())
+				name.Reset()
+				continue
+			}
+			if !isAttributeChar(c) {
+				break
+			}
+			name.WriteByte(c)
+		}
+		names = append(names, name.String())
+
+		if j >= len(query) || query[j] != ':' {
+			continue
+		}
+
+		var syntax byte
+		for k := j + 1; k < len(query); k++ {
+			if query[k] != ' ' {
+				if query[k] == '{' || query[k] == '[' {
+					syntax = query[k]
+				}
+				break
+			}
+		}
+
+		for _, n := range names {
+			if n != "" {
+				refs = append(refs, queryAttribute{name: n, syntax: syntax})
+			}
+		}
+		i = j
+	}
+
+	return refs
+}
+
+func isAttributeChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
@@ -0,0 +1,139 @@
+package redisearch
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func testIndexDefinition() *FtCreate {
+	ftc := NewFtCreate("idx:drd").
+		AddDataType(HASH).
+		AddPrefix("drd:")
+	ftc.AddSchema(FieldTypeText, "name", "", true, ftc.AddSchemaTextOption(1, false, false, "")).
+		AddSchema(FieldTypeText, "description", "desc", false, ftc.AddSchemaTextOption(1, false, false, "")).
+		AddSchema(FieldTypeTag, "cats", "", false, ftc.AddSchemaTagOption(false, ",")).
+		AddSchema(FieldTypeNumeric, "updated", "", true, ftc.AddSchemaNumericOption(false)).
+		AddSchema(FieldTypeNumeric, "views", "", false, ftc.AddSchemaNumericOption(false)).
+		AddSchema(FieldTypeGeo, "location", "", false, ftc.AddSchemaGeoOption(false))
+
+	return ftc
+}
+
+func TestFtSearch_Validate(t *testing.T) {
+	ftc := testIndexDefinition()
+
+	tests := []struct {
+		name         string
+		fts          *FtSearch
+		wantProblems []string
+	}{
+		{
+			name: "Valid Search",
+			fts: NewFtSearch("idx:drd").AddQuery(`@name|desc:dream @cats:{dream|test} @updated:[100 +inf] -@location:[28.9 41.0 5 km]`).
+				AddFilter("views", 0, 10, false, false).AddSortBy("updated", false).AddReturnFields("name", "description").
+				AddHighlight([]string{"name"}, "<b>", "</b>"),
+		},
+		{
+			name: "Escaped And Quoted",
+			fts:  NewFtSearch("idx:drd").AddQuery(`@name:"mail me @home: now" user\@example\.com`),
+		},
+		{
+			name:         "Unknown Attribute",
+			fts:          NewFtSearch("idx:drd").AddQuery("@upated:[100 200] @name:dream"),
+			wantProblems: []string{"query references unknown attribute @upated"},
+		},
+		{
+			name:         "Tag Syntax On Text",
+			fts:          NewFtSearch("idx:drd").AddQuery("@name:{dream}"),
+			wantProblems: []string{"query uses tag syntax @name:{...} on TEXT attribute"},
+		},
+		{
+			name:         "Text Syntax On Tag",
+			fts:          NewFtSearch("idx:drd").AddQuery("@cats:dream"),
+			wantProblems: []string{"query uses text syntax on TAG attribute @cats"},
+		},
+		{
+			name:         "Range Syntax On Text",
+			fts:          NewFtSearch("idx:drd").AddQuery("@desc:[1 2]"),
+			wantProblems: []string{"query uses range syntax @desc:[...] on TEXT attribute"},
+		},
+		{
+			name: "Filters",
+			fts: NewFtSearch("idx:drd").AddQuery("*").AddFilter("name", 0, 1, false, false).AddFilter("price", 0, 1, false, false).
+				AddGeoFilter("updated", 1, 2, 3, KILOMETERS),
+			wantProblems: []string{
+				"FILTER on TEXT attribute @name",
+				"FILTER references unknown attribute @price",
+				"GEOFILTER on NUMERIC attribute @updated",
+			},
+		},
+		{
+			name:         "Sort By Non Sortable",
+			fts:          NewFtSearch("idx:drd").AddQuery("*").AddSortBy("views", true),
+			wantProblems: []string{"SORTBY on non SORTABLE attribute @views"},
+		},
+		{
+			name:         "Return Unknown Property",
+			fts:          NewFtSearch("idx:drd").AddQuery("*").AddReturnFields("name", "title"),
+			wantProblems: []string{"RETURN of unknown property title"},
+		},
+		{
+			name: "Highlight And Summarize Non Text",
+			fts: NewFtSearch("idx:drd").AddQuery("*").AddHighlight([]string{"cats"}, "", "").
+				AddSummarize([]string{"desc", "updated"}, 3, 20, "..."),
+			wantProblems: []string{
+				"HIGHLIGHT on TAG attribute @cats",
+				"SUMMARIZE on NUMERIC attribute @updated",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.fts.AddIndexDefinition(ftc).Validate()
+
+			var problems []string
+			var verr *SearchValidationError
+			if errors.As(err, &verr) {
+				problems = verr.Problems
+			} else if err != nil {
+				t.Fatalf("Validate() error = %v, want a *SearchValidationError", err)
+			}
+
+			if !reflect.DeepEqual(problems, tt.wantProblems) {
+				t.Errorf("Validate() problems = %q, want %q", problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestRedisearchClient_Info(t *testing.T) {
+	rsc := newTestClient(t)
+	seedTestIndex(t, rsc)
+
+	info, err := rsc.Info(context.Background(), "idx:drd")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Name != "idx:drd" || info.DataType != HASH || !reflect.DeepEqual(info.Prefixes, []string{"drd:"}) || info.NumDocs != 3 {
+		t.Errorf("Info() = %+v", info)
+	}
+
+	want := []IndexAttribute{
+		{Identifier: "name", Attribute: "name", Type: FieldTypeText, Sortable: true, Weight: 1},
+		{Identifier: "cats", Attribute: "cats", Type: FieldTypeTag, Separator: ","},
+		{Identifier: "updated", Attribute: "updated", Type: FieldTypeNumeric, Sortable: true},
+	}
+	if !reflect.DeepEqual(info.Attributes, want) {
+		t.Errorf("Info() attributes = %+v, want %+v", info.Attributes, want)
+	}
+
+	// a search validated against the live schema fails before it reaches the server
+	_, err = rsc.DoSearch(context.Background(), NewFtSearch("idx:drd").AddQuery("@upated:[100 200]").AddIndexInfo(info))
+	var verr *SearchValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("DoSearch() error = %v, want a *SearchValidationError", err)
+	}
+}